@@ -1,6 +1,11 @@
 package entx
 
 import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 )
@@ -16,6 +21,81 @@ type BaseNode struct {
 type Field struct {
 	Name        string
 	StorageName string
+	Type        FieldType
+	// Go type of the values set on ent mutations
+	GoType reflect.Type
+	// allowed values of an enum field
+	Enums    []string
+	Nillable bool
+	Optional bool
+	// the field has a default value on creation
+	Default bool
+	// the field cannot be updated once created
+	Immutable bool
+	// the field is a primary key, is unique or leads an index of the table
+	Indexed bool
+}
+
+// FieldType is the kind of value stored by a field, as declared in the ent schema.
+// The zero value TypeOther disables any type checking on the field.
+type FieldType uint8
+
+const (
+	TypeOther FieldType = iota
+	TypeInt
+	TypeFloat
+	TypeString
+	TypeBool
+	TypeTime
+	TypeEnum
+	TypeJSON
+	TypeUUID
+	TypeBytes
+)
+
+var fieldTypeNames = [...]string{
+	TypeOther:  "other",
+	TypeInt:    "int",
+	TypeFloat:  "float",
+	TypeString: "string",
+	TypeBool:   "bool",
+	TypeTime:   "time",
+	TypeEnum:   "enum",
+	TypeJSON:   "json",
+	TypeUUID:   "uuid",
+	TypeBytes:  "bytes",
+}
+
+func (t FieldType) String() string {
+	if int(t) < len(fieldTypeNames) {
+		return fieldTypeNames[t]
+	}
+	return fieldTypeNames[TypeOther]
+}
+
+// IsNumeric reports whether the field holds integers or floats.
+func (t FieldType) IsNumeric() bool {
+	return t == TypeInt || t == TypeFloat
+}
+
+// IsOrdered reports whether values of the field can be compared with ordering operators.
+func (t FieldType) IsOrdered() bool {
+	switch t {
+	case TypeInt, TypeFloat, TypeString, TypeTime, TypeOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasEnum reports whether v is one of the allowed values of an enum field.
+func (f *Field) HasEnum(v string) bool {
+	return slices.Contains(f.Enums, v)
+}
+
+// IsNullable reports whether the column may hold NULL values.
+func (f *Field) IsNullable() bool {
+	return f.Nillable || f.Optional
 }
 
 func NewBaseNode(
@@ -58,10 +138,23 @@ func (n *BaseNode) FieldByName(s string) *Field {
 	return n.fields[s]
 }
 
+// Fields returns the fields of the node sorted by name.
+func (n *BaseNode) Fields() []*Field {
+	fields := slices.Collect(maps.Values(n.fields))
+	slices.SortFunc(fields, func(a, b *Field) int { return strings.Compare(a.Name, b.Name) })
+	return fields
+}
+
+// Bridges returns the relations of the node keyed by name.
+func (n *BaseNode) Bridges() map[string]Bridge {
+	return maps.Clone(n.bridges)
+}
+
 type BaseBridge struct {
 	parent  Node
 	child   Node
 	inverse Bridge
+	pivot   Node
 	RelationInfos
 }
 
@@ -72,10 +165,10 @@ func NewBaseBridge(
 	relInfos RelationInfos,
 ) BaseBridge {
 	return BaseBridge{
-		parent,
-		child,
-		inverse,
-		relInfos,
+		parent:        parent,
+		child:         child,
+		inverse:       inverse,
+		RelationInfos: relInfos,
 	}
 }
 
@@ -88,7 +181,7 @@ func (b *BaseBridge) JoinPivot(s *sql.Selector, outer ...*sql.SelectTable) *sql.
 	}
 
 	if b.RelType == sqlgraph.M2M {
-		pivot := sql.Table(b.PivotTable)
+		pivot := sql.Dialect(s.Dialect()).Table(b.PivotTable)
 
 		s.Join(pivot).
 			On(pivot.C(b.PivotLeftField), c(b.FinalRightField))
@@ -108,8 +201,8 @@ func (b *BaseBridge) Join(s *sql.Selector, outer ...*sql.SelectTable) (tables []
 
 	switch b.RelType {
 	case sqlgraph.M2M:
-		pivot := sql.Table(b.PivotTable)
-		t := sql.Table(b.child.Table())
+		pivot := sql.Dialect(s.Dialect()).Table(b.PivotTable)
+		t := sql.Dialect(s.Dialect()).Table(b.child.Table())
 
 		s.Join(pivot).
 			On(pivot.C(b.PivotLeftField), c(b.FinalLeftField)).
@@ -118,7 +211,7 @@ func (b *BaseBridge) Join(s *sql.Selector, outer ...*sql.SelectTable) (tables []
 
 		tables = append(tables, t, pivot)
 	default:
-		t := sql.Table(b.child.Table())
+		t := sql.Dialect(s.Dialect()).Table(b.child.Table())
 
 		s.Join(t).
 			On(t.C(b.FinalRightField), c(b.FinalLeftField))
@@ -136,6 +229,14 @@ func (b *BaseBridge) Inverse() Bridge {
 	return b.inverse
 }
 
+func (b *BaseBridge) SetPivot(node Node) {
+	b.pivot = node
+}
+
+func (b *BaseBridge) Pivot() Node {
+	return b.pivot
+}
+
 func (b *BaseBridge) RelInfos() *RelationInfos {
 	return &b.RelationInfos
 }
@@ -8,6 +8,7 @@ import (
 	"e2e/ent/tag"
 	"fmt"
 	"strings"
+	"time"
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
@@ -21,6 +22,10 @@ type ArticleTag struct {
 	TagID int `json:"tag_id,omitempty"`
 	// ArticleID holds the value of the "article_id" field.
 	ArticleID int `json:"article_id,omitempty"`
+	// Position holds the value of the "position" field.
+	Position int `json:"position,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt time.Time `json:"created_at,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the ArticleTagQuery when eager-loading is set.
 	Edges        ArticleTagEdges `json:"edges"`
@@ -67,8 +72,10 @@ func (*ArticleTag) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case articletag.FieldTagID, articletag.FieldArticleID:
+		case articletag.FieldTagID, articletag.FieldArticleID, articletag.FieldPosition:
 			values[i] = new(sql.NullInt64)
+		case articletag.FieldCreatedAt:
+			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
 		}
@@ -96,6 +103,18 @@ func (at *ArticleTag) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				at.ArticleID = int(value.Int64)
 			}
+		case articletag.FieldPosition:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field position", values[i])
+			} else if value.Valid {
+				at.Position = int(value.Int64)
+			}
+		case articletag.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				at.CreatedAt = value.Time
+			}
 		default:
 			at.selectValues.Set(columns[i], values[i])
 		}
@@ -146,6 +165,12 @@ func (at *ArticleTag) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("article_id=")
 	builder.WriteString(fmt.Sprintf("%v", at.ArticleID))
+	builder.WriteString(", ")
+	builder.WriteString("position=")
+	builder.WriteString(fmt.Sprintf("%v", at.Position))
+	builder.WriteString(", ")
+	builder.WriteString("created_at=")
+	builder.WriteString(at.CreatedAt.Format(time.ANSIC))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -4,6 +4,7 @@ package articletag
 
 import (
 	"e2e/ent/predicate"
+	"time"
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
@@ -19,6 +20,16 @@ func ArticleID(v int) predicate.ArticleTag {
 	return predicate.ArticleTag(sql.FieldEQ(FieldArticleID, v))
 }
 
+// Position applies equality check predicate on the "position" field. It's identical to PositionEQ.
+func Position(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldEQ(FieldPosition, v))
+}
+
+// CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
+func CreatedAt(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldEQ(FieldCreatedAt, v))
+}
+
 // TagIDEQ applies the EQ predicate on the "tag_id" field.
 func TagIDEQ(v int) predicate.ArticleTag {
 	return predicate.ArticleTag(sql.FieldEQ(FieldTagID, v))
@@ -59,6 +70,86 @@ func ArticleIDNotIn(vs ...int) predicate.ArticleTag {
 	return predicate.ArticleTag(sql.FieldNotIn(FieldArticleID, vs...))
 }
 
+// PositionEQ applies the EQ predicate on the "position" field.
+func PositionEQ(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldEQ(FieldPosition, v))
+}
+
+// PositionNEQ applies the NEQ predicate on the "position" field.
+func PositionNEQ(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldNEQ(FieldPosition, v))
+}
+
+// PositionIn applies the In predicate on the "position" field.
+func PositionIn(vs ...int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldIn(FieldPosition, vs...))
+}
+
+// PositionNotIn applies the NotIn predicate on the "position" field.
+func PositionNotIn(vs ...int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldNotIn(FieldPosition, vs...))
+}
+
+// PositionGT applies the GT predicate on the "position" field.
+func PositionGT(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldGT(FieldPosition, v))
+}
+
+// PositionGTE applies the GTE predicate on the "position" field.
+func PositionGTE(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldGTE(FieldPosition, v))
+}
+
+// PositionLT applies the LT predicate on the "position" field.
+func PositionLT(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldLT(FieldPosition, v))
+}
+
+// PositionLTE applies the LTE predicate on the "position" field.
+func PositionLTE(v int) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldLTE(FieldPosition, v))
+}
+
+// CreatedAtEQ applies the EQ predicate on the "created_at" field.
+func CreatedAtEQ(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtNEQ applies the NEQ predicate on the "created_at" field.
+func CreatedAtNEQ(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldNEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtIn applies the In predicate on the "created_at" field.
+func CreatedAtIn(vs ...time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtNotIn applies the NotIn predicate on the "created_at" field.
+func CreatedAtNotIn(vs ...time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldNotIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtGT applies the GT predicate on the "created_at" field.
+func CreatedAtGT(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldGT(FieldCreatedAt, v))
+}
+
+// CreatedAtGTE applies the GTE predicate on the "created_at" field.
+func CreatedAtGTE(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldGTE(FieldCreatedAt, v))
+}
+
+// CreatedAtLT applies the LT predicate on the "created_at" field.
+func CreatedAtLT(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldLT(FieldCreatedAt, v))
+}
+
+// CreatedAtLTE applies the LTE predicate on the "created_at" field.
+func CreatedAtLTE(v time.Time) predicate.ArticleTag {
+	return predicate.ArticleTag(sql.FieldLTE(FieldCreatedAt, v))
+}
+
 // HasArticle applies the HasEdge predicate on the "article" edge.
 func HasArticle() predicate.ArticleTag {
 	return predicate.ArticleTag(func(s *sql.Selector) {
@@ -9,6 +9,7 @@ import (
 	"e2e/ent/tag"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
@@ -33,6 +34,34 @@ func (atc *ArticleTagCreate) SetArticleID(i int) *ArticleTagCreate {
 	return atc
 }
 
+// SetPosition sets the "position" field.
+func (atc *ArticleTagCreate) SetPosition(i int) *ArticleTagCreate {
+	atc.mutation.SetPosition(i)
+	return atc
+}
+
+// SetNillablePosition sets the "position" field if the given value is not nil.
+func (atc *ArticleTagCreate) SetNillablePosition(i *int) *ArticleTagCreate {
+	if i != nil {
+		atc.SetPosition(*i)
+	}
+	return atc
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (atc *ArticleTagCreate) SetCreatedAt(t time.Time) *ArticleTagCreate {
+	atc.mutation.SetCreatedAt(t)
+	return atc
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (atc *ArticleTagCreate) SetNillableCreatedAt(t *time.Time) *ArticleTagCreate {
+	if t != nil {
+		atc.SetCreatedAt(*t)
+	}
+	return atc
+}
+
 // SetArticle sets the "article" edge to the Article entity.
 func (atc *ArticleTagCreate) SetArticle(a *Article) *ArticleTagCreate {
 	return atc.SetArticleID(a.ID)
@@ -50,6 +79,7 @@ func (atc *ArticleTagCreate) Mutation() *ArticleTagMutation {
 
 // Save creates the ArticleTag in the database.
 func (atc *ArticleTagCreate) Save(ctx context.Context) (*ArticleTag, error) {
+	atc.defaults()
 	return withHooks(ctx, atc.sqlSave, atc.mutation, atc.hooks)
 }
 
@@ -75,6 +105,18 @@ func (atc *ArticleTagCreate) ExecX(ctx context.Context) {
 	}
 }
 
+// defaults sets the default values of the builder before save.
+func (atc *ArticleTagCreate) defaults() {
+	if _, ok := atc.mutation.Position(); !ok {
+		v := articletag.DefaultPosition
+		atc.mutation.SetPosition(v)
+	}
+	if _, ok := atc.mutation.CreatedAt(); !ok {
+		v := articletag.DefaultCreatedAt()
+		atc.mutation.SetCreatedAt(v)
+	}
+}
+
 // check runs all checks and user-defined validators on the builder.
 func (atc *ArticleTagCreate) check() error {
 	if _, ok := atc.mutation.TagID(); !ok {
@@ -83,6 +125,12 @@ func (atc *ArticleTagCreate) check() error {
 	if _, ok := atc.mutation.ArticleID(); !ok {
 		return &ValidationError{Name: "article_id", err: errors.New(`ent: missing required field "ArticleTag.article_id"`)}
 	}
+	if _, ok := atc.mutation.Position(); !ok {
+		return &ValidationError{Name: "position", err: errors.New(`ent: missing required field "ArticleTag.position"`)}
+	}
+	if _, ok := atc.mutation.CreatedAt(); !ok {
+		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "ArticleTag.created_at"`)}
+	}
 	if len(atc.mutation.ArticleIDs()) == 0 {
 		return &ValidationError{Name: "article", err: errors.New(`ent: missing required edge "ArticleTag.article"`)}
 	}
@@ -111,6 +159,14 @@ func (atc *ArticleTagCreate) createSpec() (*ArticleTag, *sqlgraph.CreateSpec) {
 		_node = &ArticleTag{config: atc.config}
 		_spec = sqlgraph.NewCreateSpec(articletag.Table, nil)
 	)
+	if value, ok := atc.mutation.Position(); ok {
+		_spec.SetField(articletag.FieldPosition, field.TypeInt, value)
+		_node.Position = value
+	}
+	if value, ok := atc.mutation.CreatedAt(); ok {
+		_spec.SetField(articletag.FieldCreatedAt, field.TypeTime, value)
+		_node.CreatedAt = value
+	}
 	if nodes := atc.mutation.ArticleIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -166,6 +222,7 @@ func (atcb *ArticleTagCreateBulk) Save(ctx context.Context) ([]*ArticleTag, erro
 	for i := range atcb.builders {
 		func(i int, root context.Context) {
 			builder := atcb.builders[i]
+			builder.defaults()
 			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
 				mutation, ok := m.(*ArticleTagMutation)
 				if !ok {
@@ -58,6 +58,27 @@ func (atu *ArticleTagUpdate) SetNillableArticleID(i *int) *ArticleTagUpdate {
 	return atu
 }
 
+// SetPosition sets the "position" field.
+func (atu *ArticleTagUpdate) SetPosition(i int) *ArticleTagUpdate {
+	atu.mutation.ResetPosition()
+	atu.mutation.SetPosition(i)
+	return atu
+}
+
+// SetNillablePosition sets the "position" field if the given value is not nil.
+func (atu *ArticleTagUpdate) SetNillablePosition(i *int) *ArticleTagUpdate {
+	if i != nil {
+		atu.SetPosition(*i)
+	}
+	return atu
+}
+
+// AddPosition adds i to the "position" field.
+func (atu *ArticleTagUpdate) AddPosition(i int) *ArticleTagUpdate {
+	atu.mutation.AddPosition(i)
+	return atu
+}
+
 // SetArticle sets the "article" edge to the Article entity.
 func (atu *ArticleTagUpdate) SetArticle(a *Article) *ArticleTagUpdate {
 	return atu.SetArticleID(a.ID)
@@ -141,6 +162,12 @@ func (atu *ArticleTagUpdate) sqlSave(ctx context.Context) (n int, err error) {
 			}
 		}
 	}
+	if value, ok := atu.mutation.Position(); ok {
+		_spec.SetField(articletag.FieldPosition, field.TypeInt, value)
+	}
+	if value, ok := atu.mutation.AddedPosition(); ok {
+		_spec.AddField(articletag.FieldPosition, field.TypeInt, value)
+	}
 	if atu.mutation.ArticleCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -249,6 +276,27 @@ func (atuo *ArticleTagUpdateOne) SetNillableArticleID(i *int) *ArticleTagUpdateO
 	return atuo
 }
 
+// SetPosition sets the "position" field.
+func (atuo *ArticleTagUpdateOne) SetPosition(i int) *ArticleTagUpdateOne {
+	atuo.mutation.ResetPosition()
+	atuo.mutation.SetPosition(i)
+	return atuo
+}
+
+// SetNillablePosition sets the "position" field if the given value is not nil.
+func (atuo *ArticleTagUpdateOne) SetNillablePosition(i *int) *ArticleTagUpdateOne {
+	if i != nil {
+		atuo.SetPosition(*i)
+	}
+	return atuo
+}
+
+// AddPosition adds i to the "position" field.
+func (atuo *ArticleTagUpdateOne) AddPosition(i int) *ArticleTagUpdateOne {
+	atuo.mutation.AddPosition(i)
+	return atuo
+}
+
 // SetArticle sets the "article" edge to the Article entity.
 func (atuo *ArticleTagUpdateOne) SetArticle(a *Article) *ArticleTagUpdateOne {
 	return atuo.SetArticleID(a.ID)
@@ -364,6 +412,12 @@ func (atuo *ArticleTagUpdateOne) sqlSave(ctx context.Context) (_node *ArticleTag
 			}
 		}
 	}
+	if value, ok := atuo.mutation.Position(); ok {
+		_spec.SetField(articletag.FieldPosition, field.TypeInt, value)
+	}
+	if value, ok := atuo.mutation.AddedPosition(); ok {
+		_spec.AddField(articletag.FieldPosition, field.TypeInt, value)
+	}
 	if atuo.mutation.ArticleCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
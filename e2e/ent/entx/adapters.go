@@ -14,6 +14,7 @@ import (
   "entgo.io/ent/dialect/sql"
 
   "e2e/ent"
+  "e2e/ent/predicate"
 )
 
 type Client struct {
@@ -58,7 +59,7 @@ func (c *Client) GetEntityClient(n entx.Node) (entx.EntityClient, error) {
   case "User":
     return &UserClient{ UserClient: c.Client.User}, nil
   default:
-    return nil, fmt.Errorf("node named \"%s\" hasn't client", nodeName)
+    return nil, fmt.Errorf("node named '%s' hasn't client", nodeName)
   }
 }
 
@@ -77,6 +78,105 @@ func (c *ArticleClient) Query()  entx.Query {
   return &ArticleQuery{c.ArticleClient.Query()}
 }
 
+func (c *ArticleClient) Create() entx.Create {
+  return &ArticleCreate{c.ArticleClient.Create()}
+}
+
+func (c *ArticleClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.ArticleCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*ArticleCreate)
+    if !ok {
+      panic(fmt.Sprintf("ArticleClient.CreateBulk expect *ArticleCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.ArticleCreate
+  }
+  return &ArticleCreateBulk{c.ArticleClient.CreateBulk(creates...)}
+}
+
+func (c *ArticleClient) Update() entx.Update {
+  return &ArticleUpdate{c.ArticleClient.Update()}
+}
+
+func (c *ArticleClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.Article)
+  if !ok {
+    panic(fmt.Sprintf("ArticleClient.UpdateOne expect *ent.Article as Entity, got: %T", e))
+  }
+  return &ArticleUpdateOne{c.ArticleClient.UpdateOne(entity)}
+}
+
+func (c *ArticleClient) Delete() entx.Delete {
+  return &ArticleDelete{c.ArticleClient.Delete()}
+}
+
+type ArticleCreate struct {
+  *ent.ArticleCreate
+}
+
+func (b *ArticleCreate) Mutation() ent.Mutation {
+  return b.ArticleCreate.Mutation()
+}
+
+func (b *ArticleCreate) SetID(id any) error {
+  typed, err := entx.ConvertValues[int]([]any{id})
+  if err != nil {
+    return err
+  }
+  b.ArticleCreate.SetID(typed[0])
+  return nil
+}
+
+func (b *ArticleCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.ArticleCreate.Save(ctx)
+}
+
+type ArticleCreateBulk struct {
+  *ent.ArticleCreateBulk
+}
+
+func (b *ArticleCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.ArticleCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type ArticleUpdate struct {
+  *ent.ArticleUpdate
+}
+
+func (b *ArticleUpdate) Mutation() ent.Mutation {
+  return b.ArticleUpdate.Mutation()
+}
+
+func (b *ArticleUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.ArticleUpdate.Where(predicate.Article(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type ArticleUpdateOne struct {
+  *ent.ArticleUpdateOne
+}
+
+func (b *ArticleUpdateOne) Mutation() ent.Mutation {
+  return b.ArticleUpdateOne.Mutation()
+}
+
+func (b *ArticleUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.ArticleUpdateOne.Save(ctx)
+}
+
+type ArticleDelete struct {
+  *ent.ArticleDelete
+}
+
+func (b *ArticleDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.ArticleDelete.Where(predicate.Article(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type ArticleQuery struct {
   *ent.ArticleQuery
 }
@@ -107,6 +207,100 @@ func (c *ArticleTagClient) Query()  entx.Query {
   return &ArticleTagQuery{c.ArticleTagClient.Query()}
 }
 
+func (c *ArticleTagClient) Create() entx.Create {
+  return &ArticleTagCreate{c.ArticleTagClient.Create()}
+}
+
+func (c *ArticleTagClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.ArticleTagCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*ArticleTagCreate)
+    if !ok {
+      panic(fmt.Sprintf("ArticleTagClient.CreateBulk expect *ArticleTagCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.ArticleTagCreate
+  }
+  return &ArticleTagCreateBulk{c.ArticleTagClient.CreateBulk(creates...)}
+}
+
+func (c *ArticleTagClient) Update() entx.Update {
+  return &ArticleTagUpdate{c.ArticleTagClient.Update()}
+}
+
+func (c *ArticleTagClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.ArticleTag)
+  if !ok {
+    panic(fmt.Sprintf("ArticleTagClient.UpdateOne expect *ent.ArticleTag as Entity, got: %T", e))
+  }
+  return &ArticleTagUpdateOne{c.ArticleTagClient.UpdateOne(entity)}
+}
+
+func (c *ArticleTagClient) Delete() entx.Delete {
+  return &ArticleTagDelete{c.ArticleTagClient.Delete()}
+}
+
+type ArticleTagCreate struct {
+  *ent.ArticleTagCreate
+}
+
+func (b *ArticleTagCreate) Mutation() ent.Mutation {
+  return b.ArticleTagCreate.Mutation()
+}
+
+func (b *ArticleTagCreate) SetID(id any) error {
+  return fmt.Errorf(entx.ErrIDNotSettable, "ArticleTag")
+}
+
+func (b *ArticleTagCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.ArticleTagCreate.Save(ctx)
+}
+
+type ArticleTagCreateBulk struct {
+  *ent.ArticleTagCreateBulk
+}
+
+func (b *ArticleTagCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.ArticleTagCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type ArticleTagUpdate struct {
+  *ent.ArticleTagUpdate
+}
+
+func (b *ArticleTagUpdate) Mutation() ent.Mutation {
+  return b.ArticleTagUpdate.Mutation()
+}
+
+func (b *ArticleTagUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.ArticleTagUpdate.Where(predicate.ArticleTag(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type ArticleTagUpdateOne struct {
+  *ent.ArticleTagUpdateOne
+}
+
+func (b *ArticleTagUpdateOne) Mutation() ent.Mutation {
+  return b.ArticleTagUpdateOne.Mutation()
+}
+
+func (b *ArticleTagUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.ArticleTagUpdateOne.Save(ctx)
+}
+
+type ArticleTagDelete struct {
+  *ent.ArticleTagDelete
+}
+
+func (b *ArticleTagDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.ArticleTagDelete.Where(predicate.ArticleTag(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type ArticleTagQuery struct {
   *ent.ArticleTagQuery
 }
@@ -137,6 +331,105 @@ func (c *CommentClient) Query()  entx.Query {
   return &CommentQuery{c.CommentClient.Query()}
 }
 
+func (c *CommentClient) Create() entx.Create {
+  return &CommentCreate{c.CommentClient.Create()}
+}
+
+func (c *CommentClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.CommentCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*CommentCreate)
+    if !ok {
+      panic(fmt.Sprintf("CommentClient.CreateBulk expect *CommentCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.CommentCreate
+  }
+  return &CommentCreateBulk{c.CommentClient.CreateBulk(creates...)}
+}
+
+func (c *CommentClient) Update() entx.Update {
+  return &CommentUpdate{c.CommentClient.Update()}
+}
+
+func (c *CommentClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.Comment)
+  if !ok {
+    panic(fmt.Sprintf("CommentClient.UpdateOne expect *ent.Comment as Entity, got: %T", e))
+  }
+  return &CommentUpdateOne{c.CommentClient.UpdateOne(entity)}
+}
+
+func (c *CommentClient) Delete() entx.Delete {
+  return &CommentDelete{c.CommentClient.Delete()}
+}
+
+type CommentCreate struct {
+  *ent.CommentCreate
+}
+
+func (b *CommentCreate) Mutation() ent.Mutation {
+  return b.CommentCreate.Mutation()
+}
+
+func (b *CommentCreate) SetID(id any) error {
+  typed, err := entx.ConvertValues[int]([]any{id})
+  if err != nil {
+    return err
+  }
+  b.CommentCreate.SetID(typed[0])
+  return nil
+}
+
+func (b *CommentCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.CommentCreate.Save(ctx)
+}
+
+type CommentCreateBulk struct {
+  *ent.CommentCreateBulk
+}
+
+func (b *CommentCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.CommentCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type CommentUpdate struct {
+  *ent.CommentUpdate
+}
+
+func (b *CommentUpdate) Mutation() ent.Mutation {
+  return b.CommentUpdate.Mutation()
+}
+
+func (b *CommentUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.CommentUpdate.Where(predicate.Comment(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type CommentUpdateOne struct {
+  *ent.CommentUpdateOne
+}
+
+func (b *CommentUpdateOne) Mutation() ent.Mutation {
+  return b.CommentUpdateOne.Mutation()
+}
+
+func (b *CommentUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.CommentUpdateOne.Save(ctx)
+}
+
+type CommentDelete struct {
+  *ent.CommentDelete
+}
+
+func (b *CommentDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.CommentDelete.Where(predicate.Comment(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type CommentQuery struct {
   *ent.CommentQuery
 }
@@ -167,6 +460,105 @@ func (c *DepartmentClient) Query()  entx.Query {
   return &DepartmentQuery{c.DepartmentClient.Query()}
 }
 
+func (c *DepartmentClient) Create() entx.Create {
+  return &DepartmentCreate{c.DepartmentClient.Create()}
+}
+
+func (c *DepartmentClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.DepartmentCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*DepartmentCreate)
+    if !ok {
+      panic(fmt.Sprintf("DepartmentClient.CreateBulk expect *DepartmentCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.DepartmentCreate
+  }
+  return &DepartmentCreateBulk{c.DepartmentClient.CreateBulk(creates...)}
+}
+
+func (c *DepartmentClient) Update() entx.Update {
+  return &DepartmentUpdate{c.DepartmentClient.Update()}
+}
+
+func (c *DepartmentClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.Department)
+  if !ok {
+    panic(fmt.Sprintf("DepartmentClient.UpdateOne expect *ent.Department as Entity, got: %T", e))
+  }
+  return &DepartmentUpdateOne{c.DepartmentClient.UpdateOne(entity)}
+}
+
+func (c *DepartmentClient) Delete() entx.Delete {
+  return &DepartmentDelete{c.DepartmentClient.Delete()}
+}
+
+type DepartmentCreate struct {
+  *ent.DepartmentCreate
+}
+
+func (b *DepartmentCreate) Mutation() ent.Mutation {
+  return b.DepartmentCreate.Mutation()
+}
+
+func (b *DepartmentCreate) SetID(id any) error {
+  typed, err := entx.ConvertValues[int]([]any{id})
+  if err != nil {
+    return err
+  }
+  b.DepartmentCreate.SetID(typed[0])
+  return nil
+}
+
+func (b *DepartmentCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.DepartmentCreate.Save(ctx)
+}
+
+type DepartmentCreateBulk struct {
+  *ent.DepartmentCreateBulk
+}
+
+func (b *DepartmentCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.DepartmentCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type DepartmentUpdate struct {
+  *ent.DepartmentUpdate
+}
+
+func (b *DepartmentUpdate) Mutation() ent.Mutation {
+  return b.DepartmentUpdate.Mutation()
+}
+
+func (b *DepartmentUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.DepartmentUpdate.Where(predicate.Department(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type DepartmentUpdateOne struct {
+  *ent.DepartmentUpdateOne
+}
+
+func (b *DepartmentUpdateOne) Mutation() ent.Mutation {
+  return b.DepartmentUpdateOne.Mutation()
+}
+
+func (b *DepartmentUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.DepartmentUpdateOne.Save(ctx)
+}
+
+type DepartmentDelete struct {
+  *ent.DepartmentDelete
+}
+
+func (b *DepartmentDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.DepartmentDelete.Where(predicate.Department(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type DepartmentQuery struct {
   *ent.DepartmentQuery
 }
@@ -197,6 +589,105 @@ func (c *EmployeeClient) Query()  entx.Query {
   return &EmployeeQuery{c.EmployeeClient.Query()}
 }
 
+func (c *EmployeeClient) Create() entx.Create {
+  return &EmployeeCreate{c.EmployeeClient.Create()}
+}
+
+func (c *EmployeeClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.EmployeeCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*EmployeeCreate)
+    if !ok {
+      panic(fmt.Sprintf("EmployeeClient.CreateBulk expect *EmployeeCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.EmployeeCreate
+  }
+  return &EmployeeCreateBulk{c.EmployeeClient.CreateBulk(creates...)}
+}
+
+func (c *EmployeeClient) Update() entx.Update {
+  return &EmployeeUpdate{c.EmployeeClient.Update()}
+}
+
+func (c *EmployeeClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.Employee)
+  if !ok {
+    panic(fmt.Sprintf("EmployeeClient.UpdateOne expect *ent.Employee as Entity, got: %T", e))
+  }
+  return &EmployeeUpdateOne{c.EmployeeClient.UpdateOne(entity)}
+}
+
+func (c *EmployeeClient) Delete() entx.Delete {
+  return &EmployeeDelete{c.EmployeeClient.Delete()}
+}
+
+type EmployeeCreate struct {
+  *ent.EmployeeCreate
+}
+
+func (b *EmployeeCreate) Mutation() ent.Mutation {
+  return b.EmployeeCreate.Mutation()
+}
+
+func (b *EmployeeCreate) SetID(id any) error {
+  typed, err := entx.ConvertValues[int]([]any{id})
+  if err != nil {
+    return err
+  }
+  b.EmployeeCreate.SetID(typed[0])
+  return nil
+}
+
+func (b *EmployeeCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.EmployeeCreate.Save(ctx)
+}
+
+type EmployeeCreateBulk struct {
+  *ent.EmployeeCreateBulk
+}
+
+func (b *EmployeeCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.EmployeeCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type EmployeeUpdate struct {
+  *ent.EmployeeUpdate
+}
+
+func (b *EmployeeUpdate) Mutation() ent.Mutation {
+  return b.EmployeeUpdate.Mutation()
+}
+
+func (b *EmployeeUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.EmployeeUpdate.Where(predicate.Employee(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type EmployeeUpdateOne struct {
+  *ent.EmployeeUpdateOne
+}
+
+func (b *EmployeeUpdateOne) Mutation() ent.Mutation {
+  return b.EmployeeUpdateOne.Mutation()
+}
+
+func (b *EmployeeUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.EmployeeUpdateOne.Save(ctx)
+}
+
+type EmployeeDelete struct {
+  *ent.EmployeeDelete
+}
+
+func (b *EmployeeDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.EmployeeDelete.Where(predicate.Employee(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type EmployeeQuery struct {
   *ent.EmployeeQuery
 }
@@ -227,6 +718,105 @@ func (c *TagClient) Query()  entx.Query {
   return &TagQuery{c.TagClient.Query()}
 }
 
+func (c *TagClient) Create() entx.Create {
+  return &TagCreate{c.TagClient.Create()}
+}
+
+func (c *TagClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.TagCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*TagCreate)
+    if !ok {
+      panic(fmt.Sprintf("TagClient.CreateBulk expect *TagCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.TagCreate
+  }
+  return &TagCreateBulk{c.TagClient.CreateBulk(creates...)}
+}
+
+func (c *TagClient) Update() entx.Update {
+  return &TagUpdate{c.TagClient.Update()}
+}
+
+func (c *TagClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.Tag)
+  if !ok {
+    panic(fmt.Sprintf("TagClient.UpdateOne expect *ent.Tag as Entity, got: %T", e))
+  }
+  return &TagUpdateOne{c.TagClient.UpdateOne(entity)}
+}
+
+func (c *TagClient) Delete() entx.Delete {
+  return &TagDelete{c.TagClient.Delete()}
+}
+
+type TagCreate struct {
+  *ent.TagCreate
+}
+
+func (b *TagCreate) Mutation() ent.Mutation {
+  return b.TagCreate.Mutation()
+}
+
+func (b *TagCreate) SetID(id any) error {
+  typed, err := entx.ConvertValues[int]([]any{id})
+  if err != nil {
+    return err
+  }
+  b.TagCreate.SetID(typed[0])
+  return nil
+}
+
+func (b *TagCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.TagCreate.Save(ctx)
+}
+
+type TagCreateBulk struct {
+  *ent.TagCreateBulk
+}
+
+func (b *TagCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.TagCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type TagUpdate struct {
+  *ent.TagUpdate
+}
+
+func (b *TagUpdate) Mutation() ent.Mutation {
+  return b.TagUpdate.Mutation()
+}
+
+func (b *TagUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.TagUpdate.Where(predicate.Tag(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type TagUpdateOne struct {
+  *ent.TagUpdateOne
+}
+
+func (b *TagUpdateOne) Mutation() ent.Mutation {
+  return b.TagUpdateOne.Mutation()
+}
+
+func (b *TagUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.TagUpdateOne.Save(ctx)
+}
+
+type TagDelete struct {
+  *ent.TagDelete
+}
+
+func (b *TagDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.TagDelete.Where(predicate.Tag(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type TagQuery struct {
   *ent.TagQuery
 }
@@ -257,6 +847,105 @@ func (c *UserClient) Query()  entx.Query {
   return &UserQuery{c.UserClient.Query()}
 }
 
+func (c *UserClient) Create() entx.Create {
+  return &UserCreate{c.UserClient.Create()}
+}
+
+func (c *UserClient) CreateBulk(builders ...entx.Create) entx.CreateBulk {
+  creates := make([]*ent.UserCreate, len(builders))
+  for i, b := range builders {
+    adapter, ok := b.(*UserCreate)
+    if !ok {
+      panic(fmt.Sprintf("UserClient.CreateBulk expect *UserCreate as Create, got: %T", b))
+    }
+    creates[i] = adapter.UserCreate
+  }
+  return &UserCreateBulk{c.UserClient.CreateBulk(creates...)}
+}
+
+func (c *UserClient) Update() entx.Update {
+  return &UserUpdate{c.UserClient.Update()}
+}
+
+func (c *UserClient) UpdateOne(e entx.Entity) entx.UpdateOne {
+  entity, ok := e.(*ent.User)
+  if !ok {
+    panic(fmt.Sprintf("UserClient.UpdateOne expect *ent.User as Entity, got: %T", e))
+  }
+  return &UserUpdateOne{c.UserClient.UpdateOne(entity)}
+}
+
+func (c *UserClient) Delete() entx.Delete {
+  return &UserDelete{c.UserClient.Delete()}
+}
+
+type UserCreate struct {
+  *ent.UserCreate
+}
+
+func (b *UserCreate) Mutation() ent.Mutation {
+  return b.UserCreate.Mutation()
+}
+
+func (b *UserCreate) SetID(id any) error {
+  typed, err := entx.ConvertValues[int]([]any{id})
+  if err != nil {
+    return err
+  }
+  b.UserCreate.SetID(typed[0])
+  return nil
+}
+
+func (b *UserCreate) Save(ctx context.Context) (entx.Entity, error) {
+  return b.UserCreate.Save(ctx)
+}
+
+type UserCreateBulk struct {
+  *ent.UserCreateBulk
+}
+
+func (b *UserCreateBulk) Save(ctx context.Context) ([]entx.Entity, error) {
+  entities, err := b.UserCreateBulk.Save(ctx)
+  if err != nil {
+    return nil, err
+  }
+  return entx.AsEntities(entities), nil
+}
+
+type UserUpdate struct {
+  *ent.UserUpdate
+}
+
+func (b *UserUpdate) Mutation() ent.Mutation {
+  return b.UserUpdate.Mutation()
+}
+
+func (b *UserUpdate) Predicate(preds ...func(s *sql.Selector)) entx.Update {
+  b.UserUpdate.Where(predicate.User(entx.CombinePredicates(preds...)))
+  return b
+}
+
+type UserUpdateOne struct {
+  *ent.UserUpdateOne
+}
+
+func (b *UserUpdateOne) Mutation() ent.Mutation {
+  return b.UserUpdateOne.Mutation()
+}
+
+func (b *UserUpdateOne) Save(ctx context.Context) (entx.Entity, error) {
+  return b.UserUpdateOne.Save(ctx)
+}
+
+type UserDelete struct {
+  *ent.UserDelete
+}
+
+func (b *UserDelete) Predicate(preds ...func(s *sql.Selector)) entx.Delete {
+  b.UserDelete.Where(predicate.User(entx.CombinePredicates(preds...)))
+  return b
+}
+
 type UserQuery struct {
   *ent.UserQuery
 }
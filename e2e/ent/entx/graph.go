@@ -3,6 +3,7 @@
 package entx
 
 import (
+  "context"
   "fmt"
   "reflect"
   
@@ -18,6 +19,7 @@ import (
   "e2e/ent/employee"
   "e2e/ent/tag"
   "e2e/ent/user"
+  "time"
 )
 
 type ArticleNode struct {
@@ -26,12 +28,12 @@ type ArticleNode struct {
 
 func newArticleNode() *ArticleNode {
   cols := map[string]*entx.Field{
-    "user_id": {Name:"user_id", StorageName:"user_id"},
-    "title": {Name:"title", StorageName:"title"},
-    "content": {Name:"content", StorageName:"content"},
-    "published": {Name:"published", StorageName:"published"},
-    "created_at": {Name:"created_at", StorageName:"created_at"},
-    "id": {Name:"id", StorageName:"id"},
+    "user_id": {Name:"user_id", StorageName:"user_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int]()},
+    "title": {Name:"title", StorageName:"title", Type:entx.TypeString, GoType:reflect.TypeFor[string]()},
+    "content": {Name:"content", StorageName:"content", Type:entx.TypeString, GoType:reflect.TypeFor[string](), Optional:true},
+    "published": {Name:"published", StorageName:"published", Type:entx.TypeBool, GoType:reflect.TypeFor[bool](), Default:true},
+    "created_at": {Name:"created_at", StorageName:"created_at", Type:entx.TypeTime, GoType:reflect.TypeFor[time.Time](), Default:true},
+    "id": {Name:"id", StorageName:"id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
   }
   pks := []*entx.Field{
     cols["id"],
@@ -53,14 +55,37 @@ func (n *ArticleNode) Policy() ent.Policy {
   return nil
 }
 
+func (n *ArticleNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
+
 type ArticleTagNode struct {
 	entx.BaseNode
 }
 
 func newArticleTagNode() *ArticleTagNode {
   cols := map[string]*entx.Field{
-    "tag_id": {Name:"tag_id", StorageName:"tag_id"},
-    "article_id": {Name:"article_id", StorageName:"article_id"},
+    "tag_id": {Name:"tag_id", StorageName:"tag_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
+    "article_id": {Name:"article_id", StorageName:"article_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int]()},
+    "position": {Name:"position", StorageName:"position", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Default:true},
+    "created_at": {Name:"created_at", StorageName:"created_at", Type:entx.TypeTime, GoType:reflect.TypeFor[time.Time](), Default:true, Immutable:true},
   }
   pks := []*entx.Field{
     cols["tag_id"],
@@ -83,17 +108,38 @@ func (n *ArticleTagNode) Policy() ent.Policy {
   return nil
 }
 
+func (n *ArticleTagNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.ArticleTagMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
+
 type CommentNode struct {
 	entx.BaseNode
 }
 
 func newCommentNode() *CommentNode {
   cols := map[string]*entx.Field{
-    "body": {Name:"body", StorageName:"body"},
-    "created_at": {Name:"created_at", StorageName:"created_at"},
-    "user_id": {Name:"user_id", StorageName:"user_id"},
-    "article_id": {Name:"article_id", StorageName:"article_id"},
-    "id": {Name:"id", StorageName:"id"},
+    "body": {Name:"body", StorageName:"body", Type:entx.TypeString, GoType:reflect.TypeFor[string]()},
+    "created_at": {Name:"created_at", StorageName:"created_at", Type:entx.TypeTime, GoType:reflect.TypeFor[time.Time](), Default:true},
+    "user_id": {Name:"user_id", StorageName:"user_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int]()},
+    "article_id": {Name:"article_id", StorageName:"article_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int]()},
+    "id": {Name:"id", StorageName:"id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
   }
   pks := []*entx.Field{
     cols["id"],
@@ -115,14 +161,35 @@ func (n *CommentNode) Policy() ent.Policy {
   return nil
 }
 
+func (n *CommentNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.CommentMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
+
 type DepartmentNode struct {
 	entx.BaseNode
 }
 
 func newDepartmentNode() *DepartmentNode {
   cols := map[string]*entx.Field{
-    "name": {Name:"name", StorageName:"name"},
-    "id": {Name:"id", StorageName:"id"},
+    "name": {Name:"name", StorageName:"name", Type:entx.TypeString, GoType:reflect.TypeFor[string]()},
+    "id": {Name:"id", StorageName:"id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
   }
   pks := []*entx.Field{
     cols["id"],
@@ -144,17 +211,38 @@ func (n *DepartmentNode) Policy() ent.Policy {
   return nil
 }
 
+func (n *DepartmentNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.DepartmentMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
+
 type EmployeeNode struct {
 	entx.BaseNode
 }
 
 func newEmployeeNode() *EmployeeNode {
   cols := map[string]*entx.Field{
-    "hire_date": {Name:"hire_date", StorageName:"hire_date"},
-    "manager_id": {Name:"manager_id", StorageName:"manager_id"},
-    "user_id": {Name:"user_id", StorageName:"user_id"},
-    "department_id": {Name:"department_id", StorageName:"department_id"},
-    "id": {Name:"id", StorageName:"id"},
+    "hire_date": {Name:"hire_date", StorageName:"hire_date", Type:entx.TypeTime, GoType:reflect.TypeFor[time.Time](), Default:true},
+    "manager_id": {Name:"manager_id", StorageName:"manager_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Optional:true},
+    "user_id": {Name:"user_id", StorageName:"user_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int]()},
+    "department_id": {Name:"department_id", StorageName:"department_id", Type:entx.TypeInt, GoType:reflect.TypeFor[int]()},
+    "id": {Name:"id", StorageName:"id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
   }
   pks := []*entx.Field{
     cols["id"],
@@ -176,14 +264,35 @@ func (n *EmployeeNode) Policy() ent.Policy {
   return nil
 }
 
+func (n *EmployeeNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
+
 type TagNode struct {
 	entx.BaseNode
 }
 
 func newTagNode() *TagNode {
   cols := map[string]*entx.Field{
-    "name": {Name:"name", StorageName:"name"},
-    "id": {Name:"id", StorageName:"id"},
+    "name": {Name:"name", StorageName:"name", Type:entx.TypeString, GoType:reflect.TypeFor[string](), Indexed:true},
+    "id": {Name:"id", StorageName:"id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
   }
   pks := []*entx.Field{
     cols["id"],
@@ -205,19 +314,40 @@ func (n *TagNode) Policy() ent.Policy {
   return nil
 }
 
+func (n *TagNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.TagMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
+
 type UserNode struct {
 	entx.BaseNode
 }
 
 func newUserNode() *UserNode {
   cols := map[string]*entx.Field{
-    "name": {Name:"name", StorageName:"name"},
-    "email": {Name:"email", StorageName:"email"},
-    "age": {Name:"age", StorageName:"age"},
-    "is_active": {Name:"is_active", StorageName:"is_active"},
-    "created_at": {Name:"created_at", StorageName:"created_at"},
-    "updated_at": {Name:"updated_at", StorageName:"updated_at"},
-    "id": {Name:"id", StorageName:"id"},
+    "name": {Name:"name", StorageName:"name", Type:entx.TypeString, GoType:reflect.TypeFor[string]()},
+    "email": {Name:"email", StorageName:"email", Type:entx.TypeString, GoType:reflect.TypeFor[string](), Indexed:true},
+    "age": {Name:"age", StorageName:"age", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Optional:true},
+    "is_active": {Name:"is_active", StorageName:"is_active", Type:entx.TypeBool, GoType:reflect.TypeFor[bool](), Default:true},
+    "created_at": {Name:"created_at", StorageName:"created_at", Type:entx.TypeTime, GoType:reflect.TypeFor[time.Time](), Default:true},
+    "updated_at": {Name:"updated_at", StorageName:"updated_at", Type:entx.TypeTime, GoType:reflect.TypeFor[time.Time](), Default:true},
+    "id": {Name:"id", StorageName:"id", Type:entx.TypeInt, GoType:reflect.TypeFor[int](), Indexed:true},
   }
   pks := []*entx.Field{
     cols["id"],
@@ -238,6 +368,27 @@ func (n *UserNode) NewQuery(c entx.Client) entx.Query {
 func (n *UserNode) Policy() ent.Policy {
   return user.Policy
 }
+
+func (n *UserNode) OnCommit(m ent.Mutation, fn func(context.Context)) bool {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return false
+	}
+	tx, err := mut.Tx()
+	if err != nil {
+		return false
+	}
+	tx.OnCommit(func(next ent.Committer) ent.Committer {
+		return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+			fn(ctx)
+			return nil
+		})
+	})
+	return true
+}
 // ArticleCommentsBridge (O2M) left=Article, right=Comment
 type ArticleCommentsBridge struct {
   entx.BaseBridge
@@ -282,6 +433,32 @@ func (b *ArticleCommentsBridge) Include(pq entx.Query, cq func(entx.Query), hand
 	})
 	return adapter
 }
+
+func (*ArticleCommentsBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return fmt.Errorf("ArticleCommentsBridge.Attach expect *ent.ArticleMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddCommentIDs(typed...)
+	return nil
+}
+
+func (*ArticleCommentsBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return fmt.Errorf("ArticleCommentsBridge.Detach expect *ent.ArticleMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveCommentIDs(typed...)
+	return nil
+}
 // CommentArticleBridge (M2O) left=Comment, right=Article
 type CommentArticleBridge struct {
   entx.BaseBridge
@@ -326,6 +503,31 @@ func (b *CommentArticleBridge) Include(pq entx.Query, cq func(entx.Query), handl
 	})
 	return adapter
 }
+
+func (*CommentArticleBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.CommentMutation)
+	if !ok {
+		return fmt.Errorf("CommentArticleBridge.Attach expect *ent.CommentMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "article", len(typed))
+	}
+	mut.SetArticleID(typed[0])
+	return nil
+}
+
+func (*CommentArticleBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.CommentMutation)
+	if !ok {
+		return fmt.Errorf("CommentArticleBridge.Detach expect *ent.CommentMutation as Mutation, got: %T", m)
+	}
+	mut.ClearArticle()
+	return nil
+}
 // ArticleArticleTagBridge (O2M) left=Article, right=ArticleTag
 type ArticleArticleTagBridge struct {
   entx.BaseBridge
@@ -370,6 +572,14 @@ func (b *ArticleArticleTagBridge) Include(pq entx.Query, cq func(entx.Query), ha
 	})
 	return adapter
 }
+
+func (*ArticleArticleTagBridge) Attach(m ent.Mutation, ids ...any) error {
+	return fmt.Errorf(entx.ErrRelationNotMutable, "article_tag")
+}
+
+func (*ArticleArticleTagBridge) Detach(m ent.Mutation, ids ...any) error {
+	return fmt.Errorf(entx.ErrRelationNotMutable, "article_tag")
+}
 // ArticleTagArticleBridge (M2O) left=ArticleTag, right=Article
 type ArticleTagArticleBridge struct {
   entx.BaseBridge
@@ -414,6 +624,31 @@ func (b *ArticleTagArticleBridge) Include(pq entx.Query, cq func(entx.Query), ha
 	})
 	return adapter
 }
+
+func (*ArticleTagArticleBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleTagMutation)
+	if !ok {
+		return fmt.Errorf("ArticleTagArticleBridge.Attach expect *ent.ArticleTagMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "article", len(typed))
+	}
+	mut.SetArticleID(typed[0])
+	return nil
+}
+
+func (*ArticleTagArticleBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleTagMutation)
+	if !ok {
+		return fmt.Errorf("ArticleTagArticleBridge.Detach expect *ent.ArticleTagMutation as Mutation, got: %T", m)
+	}
+	mut.ClearArticle()
+	return nil
+}
 // DepartmentEmployeesBridge (O2M) left=Department, right=Employee
 type DepartmentEmployeesBridge struct {
   entx.BaseBridge
@@ -458,6 +693,32 @@ func (b *DepartmentEmployeesBridge) Include(pq entx.Query, cq func(entx.Query),
 	})
 	return adapter
 }
+
+func (*DepartmentEmployeesBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.DepartmentMutation)
+	if !ok {
+		return fmt.Errorf("DepartmentEmployeesBridge.Attach expect *ent.DepartmentMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddEmployeeIDs(typed...)
+	return nil
+}
+
+func (*DepartmentEmployeesBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.DepartmentMutation)
+	if !ok {
+		return fmt.Errorf("DepartmentEmployeesBridge.Detach expect *ent.DepartmentMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveEmployeeIDs(typed...)
+	return nil
+}
 // EmployeeDepartmentBridge (M2O) left=Employee, right=Department
 type EmployeeDepartmentBridge struct {
   entx.BaseBridge
@@ -502,6 +763,31 @@ func (b *EmployeeDepartmentBridge) Include(pq entx.Query, cq func(entx.Query), h
 	})
 	return adapter
 }
+
+func (*EmployeeDepartmentBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeDepartmentBridge.Attach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "department", len(typed))
+	}
+	mut.SetDepartmentID(typed[0])
+	return nil
+}
+
+func (*EmployeeDepartmentBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeDepartmentBridge.Detach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	mut.ClearDepartment()
+	return nil
+}
 // EmployeeManagerBridge (M2O) left=Employee, right=Employee
 type EmployeeManagerBridge struct {
   entx.BaseBridge
@@ -517,8 +803,8 @@ func newEmployeeManagerBridge(
       nil,
       entx.RelationInfos{
         RelType:         sqlgraph.M2O,
-        FinalLeftField:  "id",
-        FinalRightField: "manager_id",
+        FinalLeftField:  "manager_id",
+        FinalRightField: "id",
         PivotTable:      "",
         PivotLeftField:  "",
         PivotRightField: "",
@@ -546,6 +832,31 @@ func (b *EmployeeManagerBridge) Include(pq entx.Query, cq func(entx.Query), hand
 	})
 	return adapter
 }
+
+func (*EmployeeManagerBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeManagerBridge.Attach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "manager", len(typed))
+	}
+	mut.SetManagerID(typed[0])
+	return nil
+}
+
+func (*EmployeeManagerBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeManagerBridge.Detach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	mut.ClearManager()
+	return nil
+}
 // EmployeeReportsBridge (O2M) left=Employee, right=Employee
 type EmployeeReportsBridge struct {
   entx.BaseBridge
@@ -590,6 +901,32 @@ func (b *EmployeeReportsBridge) Include(pq entx.Query, cq func(entx.Query), hand
 	})
 	return adapter
 }
+
+func (*EmployeeReportsBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeReportsBridge.Attach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddReportIDs(typed...)
+	return nil
+}
+
+func (*EmployeeReportsBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeReportsBridge.Detach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveReportIDs(typed...)
+	return nil
+}
 // TagArticlesBridge (M2M) left=Tag, right=Article
 type TagArticlesBridge struct {
   entx.BaseBridge
@@ -634,6 +971,68 @@ func (b *TagArticlesBridge) Include(pq entx.Query, cq func(entx.Query), handlers
 	})
 	return adapter
 }
+
+func (b *TagArticlesBridge) IncludePivot(pq entx.Query, cq, pivotq func(entx.Query), handler entx.PivotHandler, handlers ...entx.EntityHandler) entx.Query {
+	adapter := b.Include(pq, cq, handlers...).(*TagQuery)
+	adapter.TagQuery.WithArticleTag(func(q *ent.ArticleTagQuery) {
+		pivotq(&ArticleTagQuery{q})
+	})
+	// the children shared by several parents are loaded once, each parent gets its own copy
+	adapter.TagQuery.AppendInterceptors(entx.ToInterceptor[*ent.Tag](func(entities []entx.Entity) error {
+		for _, e := range entities {
+			parent := e.(*ent.Tag)
+			pivots := make(map[int]*ent.ArticleTag, len(parent.Edges.ArticleTag))
+			for _, p := range parent.Edges.ArticleTag {
+				pivots[p.ArticleID] = p
+			}
+			for i, child := range parent.Edges.Articles {
+				p, ok := pivots[child.ID]
+				if !ok {
+					continue
+				}
+				copied := *child
+				if child.Meta != nil {
+					meta := *child.Meta
+					copied.Meta = &meta
+				}
+				if err := handler(&copied, p); err != nil {
+					return err
+				}
+				parent.Edges.Articles[i] = &copied
+			}
+			// the pivot rows are only read to set the metadata of the children
+			parent.Edges.ArticleTag = nil
+		}
+		return nil
+	}))
+	return adapter
+}
+
+func (*TagArticlesBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.TagMutation)
+	if !ok {
+		return fmt.Errorf("TagArticlesBridge.Attach expect *ent.TagMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddArticleIDs(typed...)
+	return nil
+}
+
+func (*TagArticlesBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.TagMutation)
+	if !ok {
+		return fmt.Errorf("TagArticlesBridge.Detach expect *ent.TagMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveArticleIDs(typed...)
+	return nil
+}
 // ArticleTagsBridge (M2M) left=Article, right=Tag
 type ArticleTagsBridge struct {
   entx.BaseBridge
@@ -678,6 +1077,68 @@ func (b *ArticleTagsBridge) Include(pq entx.Query, cq func(entx.Query), handlers
 	})
 	return adapter
 }
+
+func (b *ArticleTagsBridge) IncludePivot(pq entx.Query, cq, pivotq func(entx.Query), handler entx.PivotHandler, handlers ...entx.EntityHandler) entx.Query {
+	adapter := b.Include(pq, cq, handlers...).(*ArticleQuery)
+	adapter.ArticleQuery.WithArticleTag(func(q *ent.ArticleTagQuery) {
+		pivotq(&ArticleTagQuery{q})
+	})
+	// the children shared by several parents are loaded once, each parent gets its own copy
+	adapter.ArticleQuery.AppendInterceptors(entx.ToInterceptor[*ent.Article](func(entities []entx.Entity) error {
+		for _, e := range entities {
+			parent := e.(*ent.Article)
+			pivots := make(map[int]*ent.ArticleTag, len(parent.Edges.ArticleTag))
+			for _, p := range parent.Edges.ArticleTag {
+				pivots[p.TagID] = p
+			}
+			for i, child := range parent.Edges.Tags {
+				p, ok := pivots[child.ID]
+				if !ok {
+					continue
+				}
+				copied := *child
+				if child.Meta != nil {
+					meta := *child.Meta
+					copied.Meta = &meta
+				}
+				if err := handler(&copied, p); err != nil {
+					return err
+				}
+				parent.Edges.Tags[i] = &copied
+			}
+			// the pivot rows are only read to set the metadata of the children
+			parent.Edges.ArticleTag = nil
+		}
+		return nil
+	}))
+	return adapter
+}
+
+func (*ArticleTagsBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return fmt.Errorf("ArticleTagsBridge.Attach expect *ent.ArticleMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddTagIDs(typed...)
+	return nil
+}
+
+func (*ArticleTagsBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return fmt.Errorf("ArticleTagsBridge.Detach expect *ent.ArticleMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveTagIDs(typed...)
+	return nil
+}
 // TagArticleTagBridge (O2M) left=Tag, right=ArticleTag
 type TagArticleTagBridge struct {
   entx.BaseBridge
@@ -722,6 +1183,14 @@ func (b *TagArticleTagBridge) Include(pq entx.Query, cq func(entx.Query), handle
 	})
 	return adapter
 }
+
+func (*TagArticleTagBridge) Attach(m ent.Mutation, ids ...any) error {
+	return fmt.Errorf(entx.ErrRelationNotMutable, "article_tag")
+}
+
+func (*TagArticleTagBridge) Detach(m ent.Mutation, ids ...any) error {
+	return fmt.Errorf(entx.ErrRelationNotMutable, "article_tag")
+}
 // ArticleTagTagBridge (M2O) left=ArticleTag, right=Tag
 type ArticleTagTagBridge struct {
   entx.BaseBridge
@@ -766,6 +1235,31 @@ func (b *ArticleTagTagBridge) Include(pq entx.Query, cq func(entx.Query), handle
 	})
 	return adapter
 }
+
+func (*ArticleTagTagBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleTagMutation)
+	if !ok {
+		return fmt.Errorf("ArticleTagTagBridge.Attach expect *ent.ArticleTagMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "tag", len(typed))
+	}
+	mut.SetTagID(typed[0])
+	return nil
+}
+
+func (*ArticleTagTagBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleTagMutation)
+	if !ok {
+		return fmt.Errorf("ArticleTagTagBridge.Detach expect *ent.ArticleTagMutation as Mutation, got: %T", m)
+	}
+	mut.ClearTag()
+	return nil
+}
 // UserArticlesBridge (O2M) left=User, right=Article
 type UserArticlesBridge struct {
   entx.BaseBridge
@@ -810,6 +1304,32 @@ func (b *UserArticlesBridge) Include(pq entx.Query, cq func(entx.Query), handler
 	})
 	return adapter
 }
+
+func (*UserArticlesBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return fmt.Errorf("UserArticlesBridge.Attach expect *ent.UserMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddArticleIDs(typed...)
+	return nil
+}
+
+func (*UserArticlesBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return fmt.Errorf("UserArticlesBridge.Detach expect *ent.UserMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveArticleIDs(typed...)
+	return nil
+}
 // ArticleAuthorBridge (M2O) left=Article, right=User
 type ArticleAuthorBridge struct {
   entx.BaseBridge
@@ -854,6 +1374,31 @@ func (b *ArticleAuthorBridge) Include(pq entx.Query, cq func(entx.Query), handle
 	})
 	return adapter
 }
+
+func (*ArticleAuthorBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return fmt.Errorf("ArticleAuthorBridge.Attach expect *ent.ArticleMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "author", len(typed))
+	}
+	mut.SetAuthorID(typed[0])
+	return nil
+}
+
+func (*ArticleAuthorBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.ArticleMutation)
+	if !ok {
+		return fmt.Errorf("ArticleAuthorBridge.Detach expect *ent.ArticleMutation as Mutation, got: %T", m)
+	}
+	mut.ClearAuthor()
+	return nil
+}
 // UserCommentsBridge (O2M) left=User, right=Comment
 type UserCommentsBridge struct {
   entx.BaseBridge
@@ -898,6 +1443,32 @@ func (b *UserCommentsBridge) Include(pq entx.Query, cq func(entx.Query), handler
 	})
 	return adapter
 }
+
+func (*UserCommentsBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return fmt.Errorf("UserCommentsBridge.Attach expect *ent.UserMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.AddCommentIDs(typed...)
+	return nil
+}
+
+func (*UserCommentsBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return fmt.Errorf("UserCommentsBridge.Detach expect *ent.UserMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	mut.RemoveCommentIDs(typed...)
+	return nil
+}
 // CommentUserBridge (M2O) left=Comment, right=User
 type CommentUserBridge struct {
   entx.BaseBridge
@@ -942,6 +1513,31 @@ func (b *CommentUserBridge) Include(pq entx.Query, cq func(entx.Query), handlers
 	})
 	return adapter
 }
+
+func (*CommentUserBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.CommentMutation)
+	if !ok {
+		return fmt.Errorf("CommentUserBridge.Attach expect *ent.CommentMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "user", len(typed))
+	}
+	mut.SetUserID(typed[0])
+	return nil
+}
+
+func (*CommentUserBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.CommentMutation)
+	if !ok {
+		return fmt.Errorf("CommentUserBridge.Detach expect *ent.CommentMutation as Mutation, got: %T", m)
+	}
+	mut.ClearUser()
+	return nil
+}
 // UserEmployeeBridge (O2O) left=User, right=Employee
 type UserEmployeeBridge struct {
   entx.BaseBridge
@@ -986,6 +1582,31 @@ func (b *UserEmployeeBridge) Include(pq entx.Query, cq func(entx.Query), handler
 	})
 	return adapter
 }
+
+func (*UserEmployeeBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return fmt.Errorf("UserEmployeeBridge.Attach expect *ent.UserMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "employee", len(typed))
+	}
+	mut.SetEmployeeID(typed[0])
+	return nil
+}
+
+func (*UserEmployeeBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.UserMutation)
+	if !ok {
+		return fmt.Errorf("UserEmployeeBridge.Detach expect *ent.UserMutation as Mutation, got: %T", m)
+	}
+	mut.ClearEmployee()
+	return nil
+}
 // EmployeeUserBridge (O2O) left=Employee, right=User
 type EmployeeUserBridge struct {
   entx.BaseBridge
@@ -1031,6 +1652,31 @@ func (b *EmployeeUserBridge) Include(pq entx.Query, cq func(entx.Query), handler
 	return adapter
 }
 
+func (*EmployeeUserBridge) Attach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeUserBridge.Attach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	typed, err := entx.ConvertValues[int](ids)
+	if err != nil {
+		return err
+	}
+	if len(typed) != 1 {
+		return fmt.Errorf(entx.ErrAttachUnique, "user", len(typed))
+	}
+	mut.SetUserID(typed[0])
+	return nil
+}
+
+func (*EmployeeUserBridge) Detach(m ent.Mutation, ids ...any) error {
+	mut, ok := m.(*ent.EmployeeMutation)
+	if !ok {
+		return fmt.Errorf("EmployeeUserBridge.Detach expect *ent.EmployeeMutation as Mutation, got: %T", m)
+	}
+	mut.ClearUser()
+	return nil
+}
+
 var Graph = BuildGraph()
 
 func BuildGraph() map[string]entx.Node {
@@ -1065,9 +1711,10 @@ func BuildGraph() map[string]entx.Node {
   
   var employeeManagerBridge = newEmployeeManagerBridge(employeeNode, employeeNode)
   employeeNode.SetBridge("manager", employeeManagerBridge)
-  
   var employeeReportsBridge = newEmployeeReportsBridge(employeeNode, employeeNode)
   employeeNode.SetBridge("reports", employeeReportsBridge)
+  employeeManagerBridge.SetInverse(employeeReportsBridge)
+  employeeReportsBridge.SetInverse(employeeManagerBridge)
   
   var tagArticlesBridge = newTagArticlesBridge(tagNode, articleNode)
   tagNode.SetBridge("articles", tagArticlesBridge)
@@ -1075,6 +1722,8 @@ func BuildGraph() map[string]entx.Node {
   articleNode.SetBridge("tags", articleTagsBridge)
   tagArticlesBridge.SetInverse(articleTagsBridge)
   articleTagsBridge.SetInverse(tagArticlesBridge)
+  tagArticlesBridge.SetPivot(articleTagNode)
+  articleTagsBridge.SetPivot(articleTagNode)
   
   var tagArticleTagBridge = newTagArticleTagBridge(tagNode, articleTagNode)
   tagNode.SetBridge("article_tag", tagArticleTagBridge)
@@ -33,6 +33,8 @@ var (
 	}
 	// ArticleTagsColumns holds the columns for the "article_tags" table.
 	ArticleTagsColumns = []*schema.Column{
+		{Name: "position", Type: field.TypeInt, Default: 0},
+		{Name: "created_at", Type: field.TypeTime},
 		{Name: "article_id", Type: field.TypeInt},
 		{Name: "tag_id", Type: field.TypeInt},
 	}
@@ -40,17 +42,17 @@ var (
 	ArticleTagsTable = &schema.Table{
 		Name:       "article_tags",
 		Columns:    ArticleTagsColumns,
-		PrimaryKey: []*schema.Column{ArticleTagsColumns[1], ArticleTagsColumns[0]},
+		PrimaryKey: []*schema.Column{ArticleTagsColumns[3], ArticleTagsColumns[2]},
 		ForeignKeys: []*schema.ForeignKey{
 			{
 				Symbol:     "article_tags_articles_article",
-				Columns:    []*schema.Column{ArticleTagsColumns[0]},
+				Columns:    []*schema.Column{ArticleTagsColumns[2]},
 				RefColumns: []*schema.Column{ArticlesColumns[0]},
 				OnDelete:   schema.NoAction,
 			},
 			{
 				Symbol:     "article_tags_tags_tag",
-				Columns:    []*schema.Column{ArticleTagsColumns[1]},
+				Columns:    []*schema.Column{ArticleTagsColumns[3]},
 				RefColumns: []*schema.Column{TagsColumns[0]},
 				OnDelete:   schema.NoAction,
 			},
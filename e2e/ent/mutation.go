@@ -852,6 +852,9 @@ type ArticleTagMutation struct {
 	config
 	op             Op
 	typ            string
+	position       *int
+	addposition    *int
+	created_at     *time.Time
 	clearedFields  map[string]struct{}
 	article        *int
 	clearedarticle bool
@@ -938,6 +941,64 @@ func (m *ArticleTagMutation) ResetArticleID() {
 	m.article = nil
 }
 
+// SetPosition sets the "position" field.
+func (m *ArticleTagMutation) SetPosition(i int) {
+	m.position = &i
+	m.addposition = nil
+}
+
+// Position returns the value of the "position" field in the mutation.
+func (m *ArticleTagMutation) Position() (r int, exists bool) {
+	v := m.position
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddPosition adds i to the "position" field.
+func (m *ArticleTagMutation) AddPosition(i int) {
+	if m.addposition != nil {
+		*m.addposition += i
+	} else {
+		m.addposition = &i
+	}
+}
+
+// AddedPosition returns the value that was added to the "position" field in this mutation.
+func (m *ArticleTagMutation) AddedPosition() (r int, exists bool) {
+	v := m.addposition
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetPosition resets all changes to the "position" field.
+func (m *ArticleTagMutation) ResetPosition() {
+	m.position = nil
+	m.addposition = nil
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *ArticleTagMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *ArticleTagMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *ArticleTagMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
 // ClearArticle clears the "article" edge to the Article entity.
 func (m *ArticleTagMutation) ClearArticle() {
 	m.clearedarticle = true
@@ -1026,13 +1087,19 @@ func (m *ArticleTagMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ArticleTagMutation) Fields() []string {
-	fields := make([]string, 0, 2)
+	fields := make([]string, 0, 4)
 	if m.tag != nil {
 		fields = append(fields, articletag.FieldTagID)
 	}
 	if m.article != nil {
 		fields = append(fields, articletag.FieldArticleID)
 	}
+	if m.position != nil {
+		fields = append(fields, articletag.FieldPosition)
+	}
+	if m.created_at != nil {
+		fields = append(fields, articletag.FieldCreatedAt)
+	}
 	return fields
 }
 
@@ -1045,6 +1112,10 @@ func (m *ArticleTagMutation) Field(name string) (ent.Value, bool) {
 		return m.TagID()
 	case articletag.FieldArticleID:
 		return m.ArticleID()
+	case articletag.FieldPosition:
+		return m.Position()
+	case articletag.FieldCreatedAt:
+		return m.CreatedAt()
 	}
 	return nil, false
 }
@@ -1075,6 +1146,20 @@ func (m *ArticleTagMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetArticleID(v)
 		return nil
+	case articletag.FieldPosition:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPosition(v)
+		return nil
+	case articletag.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
 	}
 	return fmt.Errorf("unknown ArticleTag field %s", name)
 }
@@ -1083,6 +1168,9 @@ func (m *ArticleTagMutation) SetField(name string, value ent.Value) error {
 // this mutation.
 func (m *ArticleTagMutation) AddedFields() []string {
 	var fields []string
+	if m.addposition != nil {
+		fields = append(fields, articletag.FieldPosition)
+	}
 	return fields
 }
 
@@ -1091,6 +1179,8 @@ func (m *ArticleTagMutation) AddedFields() []string {
 // was not set, or was not defined in the schema.
 func (m *ArticleTagMutation) AddedField(name string) (ent.Value, bool) {
 	switch name {
+	case articletag.FieldPosition:
+		return m.AddedPosition()
 	}
 	return nil, false
 }
@@ -1100,6 +1190,13 @@ func (m *ArticleTagMutation) AddedField(name string) (ent.Value, bool) {
 // type.
 func (m *ArticleTagMutation) AddField(name string, value ent.Value) error {
 	switch name {
+	case articletag.FieldPosition:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddPosition(v)
+		return nil
 	}
 	return fmt.Errorf("unknown ArticleTag numeric field %s", name)
 }
@@ -1133,6 +1230,12 @@ func (m *ArticleTagMutation) ResetField(name string) error {
 	case articletag.FieldArticleID:
 		m.ResetArticleID()
 		return nil
+	case articletag.FieldPosition:
+		m.ResetPosition()
+		return nil
+	case articletag.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
 	}
 	return fmt.Errorf("unknown ArticleTag field %s", name)
 }
@@ -5,6 +5,7 @@ package runtime
 import (
 	"context"
 	"e2e/ent/article"
+	"e2e/ent/articletag"
 	"e2e/ent/comment"
 	"e2e/ent/employee"
 	"e2e/ent/user"
@@ -29,6 +30,16 @@ func init() {
 	articleDescCreatedAt := articleFields[5].Descriptor()
 	// article.DefaultCreatedAt holds the default value on creation for the created_at field.
 	article.DefaultCreatedAt = articleDescCreatedAt.Default.(func() time.Time)
+	articletagFields := schema.ArticleTag{}.Fields()
+	_ = articletagFields
+	// articletagDescPosition is the schema descriptor for position field.
+	articletagDescPosition := articletagFields[2].Descriptor()
+	// articletag.DefaultPosition holds the default value on creation for the position field.
+	articletag.DefaultPosition = articletagDescPosition.Default.(int)
+	// articletagDescCreatedAt is the schema descriptor for created_at field.
+	articletagDescCreatedAt := articletagFields[3].Descriptor()
+	// articletag.DefaultCreatedAt holds the default value on creation for the created_at field.
+	articletag.DefaultCreatedAt = articletagDescCreatedAt.Default.(func() time.Time)
 	commentFields := schema.Comment{}.Fields()
 	_ = commentFields
 	// commentDescCreatedAt is the schema descriptor for created_at field.
@@ -20,7 +20,7 @@ func main() {
 		Package: "e2e/ent",
 	}
 	exts := entc.Extensions(
-		searchext.New(),
+		searchext.New(searchext.WithGraphQL()),
 	)
 	if err := entc.Generate("./schema", &cfg, exts); err != nil {
 		log.Fatalf("running ent codegen: %v", err)
@@ -0,0 +1,4 @@
+#!/bin/sh
+# Loads the time zone tables used by CONVERT_TZ to resolve IANA names (e.g. Europe/Paris),
+# the entrypoint sourcing this script provides docker_process_sql.
+mysql_tzinfo_to_sql /usr/share/zoneinfo | docker_process_sql --database=mysql
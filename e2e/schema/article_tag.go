@@ -1,6 +1,8 @@
 package schema
 
 import (
+	"time"
+
 	"entgo.io/ent"
 	"entgo.io/ent/schema"
 	"entgo.io/ent/schema/edge"
@@ -15,6 +17,11 @@ func (ArticleTag) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int("tag_id"),
 		field.Int("article_id"),
+		field.Int("position").
+			Default(0),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
 	}
 }
 
@@ -3,24 +3,41 @@ package tests
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"e2e/ent"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 
 	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"modernc.org/sqlite"
 )
 
+func init() {
+	// SQLite does not implement the REGEXP operator, it calls a user function.
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		pattern, _ := args[0].(string)
+		value, _ := args[1].(string)
+		return regexp.MatchString(pattern, value)
+	})
+}
+
+// Dialect returns the dialect of the database under test, set by the DB_DIALECT
+// environment variable. Defaults to an in-memory SQLite database.
+func Dialect() string {
+	if d := os.Getenv("DB_DIALECT"); d != "" {
+		return d
+	}
+	return dialect.SQLite
+}
+
 func OpenAndMigrateDB() (*ent.Client, error) {
-	url := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=True",
-		os.Getenv("MYSQL_USER"),
-		os.Getenv("MYSQL_PASSWORD"),
-		os.Getenv("MYSQL_HOST"),
-		os.Getenv("MYSQL_PORT"),
-		os.Getenv("MYSQL_DATABASE"),
-	)
-
-	client, err := ent.Open(dialect.MySQL, url)
+	client, err := openDB(Dialect())
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +48,36 @@ func OpenAndMigrateDB() (*ent.Client, error) {
 	return client, nil
 }
 
+func openDB(name string) (*ent.Client, error) {
+	switch name {
+	case dialect.MySQL:
+		return ent.Open(dialect.MySQL, fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=True",
+			os.Getenv("MYSQL_USER"),
+			os.Getenv("MYSQL_PASSWORD"),
+			os.Getenv("MYSQL_HOST"),
+			os.Getenv("MYSQL_PORT"),
+			os.Getenv("MYSQL_DATABASE"),
+		))
+	case dialect.Postgres:
+		return ent.Open(dialect.Postgres, fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			os.Getenv("POSTGRES_HOST"),
+			os.Getenv("POSTGRES_PORT"),
+			os.Getenv("POSTGRES_USER"),
+			os.Getenv("POSTGRES_PASSWORD"),
+			os.Getenv("POSTGRES_DB"),
+		))
+	case dialect.SQLite:
+		// memdb databases are shared by the connections of the process
+		db, err := sql.Open("sqlite", "file:/entx-test?vfs=memdb&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite")
+		if err != nil {
+			return nil, err
+		}
+		return ent.NewClient(ent.Driver(entsql.OpenDB(dialect.SQLite, db))), nil
+	default:
+		return nil, fmt.Errorf("unsupported dialect %q", name)
+	}
+}
+
 func WithTx(
 	ctx context.Context,
 	client *ent.Client,
@@ -72,48 +119,92 @@ func FlushDBt(t *testing.T, client *ent.Client) {
 
 func FlushDB(ctx context.Context, client *ent.Client) error {
 	return WithTx(ctx, client, func(ctx context.Context, client *ent.Client) error {
-		// Retrieve table names to be truncated (excluding migrations)
-		rows, err := client.QueryContext(ctx, `
-			SELECT table_name 
-			FROM information_schema.tables 
-			WHERE table_schema = DATABASE()
-		`)
+		tables, err := tableNames(ctx, client)
 		if err != nil {
-			return fmt.Errorf("failed to retrieve table names: %v", err)
+			return err
 		}
-		defer rows.Close()
 
-		var tables []string
-		for rows.Next() {
-			var table string
-			if err := rows.Scan(&table); err != nil {
-				return fmt.Errorf("failed to scan table name: %v", err)
+		switch Dialect() {
+		case dialect.Postgres:
+			if len(tables) == 0 {
+				return nil
+			}
+			quoted := make([]string, len(tables))
+			for i, table := range tables {
+				quoted[i] = fmt.Sprintf("%q", table)
+			}
+			if _, err := client.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", strings.Join(quoted, ", "))); err != nil {
+				return fmt.Errorf("failed to truncate tables: %v", err)
+			}
+		case dialect.SQLite:
+			// foreign keys cannot be disabled inside a transaction, they are checked on commit
+			if _, err := client.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+				return fmt.Errorf("failed to defer foreign key checks: %v", err)
+			}
+			for _, table := range tables {
+				if _, err := client.ExecContext(ctx, fmt.Sprintf("DELETE FROM `%s`", table)); err != nil {
+					return fmt.Errorf("failed to delete rows of table %s: %v", table, err)
+				}
+			}
+		default:
+			// Disable foreign key checks
+			if _, err := client.ExecContext(ctx, `SET FOREIGN_KEY_CHECKS = 0`); err != nil {
+				return fmt.Errorf("failed to disable foreign key checks: %v", err)
 			}
-			tables = append(tables, table)
-		}
-
-		// Disable foreign key checks
-		if _, err := client.ExecContext(ctx, `SET FOREIGN_KEY_CHECKS = 0`); err != nil {
-			return fmt.Errorf("failed to disable foreign key checks: %v", err)
-		}
 
-		// Truncate all tables
-		for _, table := range tables {
-			if _, err := client.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE `%s`", table)); err != nil {
-				return fmt.Errorf("failed to truncate table %s: %v", table, err)
+			// Truncate all tables
+			for _, table := range tables {
+				if _, err := client.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE `%s`", table)); err != nil {
+					return fmt.Errorf("failed to truncate table %s: %v", table, err)
+				}
 			}
-		}
 
-		// Enable foreign key checks
-		if _, err := client.ExecContext(ctx, `SET FOREIGN_KEY_CHECKS = 1`); err != nil {
-			return fmt.Errorf("failed to re-enable foreign key checks: %v", err)
+			// Enable foreign key checks
+			if _, err := client.ExecContext(ctx, `SET FOREIGN_KEY_CHECKS = 1`); err != nil {
+				return fmt.Errorf("failed to re-enable foreign key checks: %v", err)
+			}
 		}
-
 		return nil
 	})
 }
 
+// tableNames retrieves the tables of the schema, migrations excluded.
+func tableNames(ctx context.Context, client *ent.Client) ([]string, error) {
+	var query string
+	switch Dialect() {
+	case dialect.Postgres:
+		query = `SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'`
+	case dialect.SQLite:
+		query = `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`
+	default:
+		query = `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()`
+	}
+	rows, err := client.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve table names: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
 func ResetAutoIncrement(ctx context.Context, client *ent.Client) error {
+	switch Dialect() {
+	case dialect.SQLite:
+		// rowids always follow the greatest inserted id
+		return nil
+	case dialect.Postgres:
+		return resetIdentities(ctx, client)
+	}
+
 	// Retrieve tables with an auto_increment id column
 	rows, err := client.QueryContext(ctx, `
 			SELECT DISTINCT TABLE_NAME
@@ -175,3 +266,35 @@ func ResetAutoIncrement(ctx context.Context, client *ent.Client) error {
 
 	return nil
 }
+
+// resetIdentities restarts the identity of the id columns after the greatest id.
+func resetIdentities(ctx context.Context, client *ent.Client) error {
+	rows, err := client.QueryContext(ctx, `
+			SELECT table_name
+			FROM information_schema.columns
+			WHERE table_schema = current_schema()
+			AND column_name = 'id'
+			AND (is_identity = 'YES' OR column_default LIKE 'nextval%')
+		`)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve identity tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return fmt.Errorf("failed to scan table name: %v", err)
+		}
+		tables = append(tables, table)
+	}
+
+	for _, table := range tables {
+		query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%[1]q', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]q`, table)
+		if _, err := client.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to reset identity for table %s: %v", table, err)
+		}
+	}
+	return nil
+}
@@ -2,10 +2,12 @@ package e2e_search_test
 
 import (
 	"context"
+	"e2e/ent"
 	"errors"
 	"fmt"
 	"testing"
 
+	"entgo.io/ent/dialect"
 	entxstd "github.com/brice-74/entx"
 	"github.com/brice-74/entx/search"
 	"github.com/brice-74/entx/search/common"
@@ -39,7 +41,7 @@ func TestAggregateValidationErrors(t *testing.T) {
 				From: "User",
 				QueryOptions: search.QueryOptions{
 					Aggregates: dsl.Aggregates{
-						&dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{Field: "age", Type: "median"}},
+						&dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{Field: "age", Type: "mode"}},
 					},
 				},
 			},
@@ -65,15 +67,36 @@ func TestAggregateValidationErrors(t *testing.T) {
 					},
 				},
 			},
-			cfg:          common.NewConfig(common.WithAggregateConfig(common.AggregateConfig{MaxAggregateRelationDepth: 1})),
+			cfg:          newConfig(common.WithAggregateConfig(common.AggregateConfig{MaxAggregateRelationDepth: 1})),
 			expectedRule: "MaxAggregateRelationsDepth",
 		},
+		{
+			query:        aggregateQuery(dsl.BaseAggregate{Field: "age", Type: dsl.AggPercentile, AggParams: dsl.AggParams{Percentile: 1.5}}),
+			expectedRule: "AggregatePercentile",
+		},
+		{
+			query:        aggregateQuery(dsl.BaseAggregate{Field: "age", Type: dsl.AggSum, AggParams: dsl.AggParams{Separator: ";"}}),
+			expectedRule: "AggregateParamNotAllowed",
+		},
+		{
+			query:        aggregateQuery(dsl.BaseAggregate{Field: "name", Type: dsl.AggStringAgg, AggParams: dsl.AggParams{Order: "UP"}}),
+			expectedRule: "AggregateOrder",
+		},
+		{
+			query:        aggregateQuery(dsl.BaseAggregate{Field: "age", Type: dsl.AggBoolAnd}),
+			expectedRule: "AggregateFieldType",
+		},
+		{
+			query:        aggregateQuery(dsl.BaseAggregate{Field: "age", Type: dsl.AggMedian}),
+			cfg:          common.NewConfig(common.WithDialect(dialect.MySQL)),
+			expectedRule: "AggregateDialect",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.expectedRule, func(t *testing.T) {
 			if tt.cfg == nil {
-				tt.cfg = &common.DefaultConf
+				tt.cfg = &defaultConf
 			}
 			err := runExecutableErr(t, &tt.query, tt.cfg)
 			var verr *search.ValidationError
@@ -83,6 +106,13 @@ func TestAggregateValidationErrors(t *testing.T) {
 	}
 }
 
+func aggregateQuery(agg dsl.BaseAggregate) search.TargetedQuery {
+	return search.TargetedQuery{
+		From:         "User",
+		QueryOptions: search.QueryOptions{Aggregates: dsl.Aggregates{{BaseAggregate: agg}}},
+	}
+}
+
 func TestAggregateBuildErr(t *testing.T) {
 	cases := []struct {
 		name   string
@@ -117,7 +147,7 @@ func TestAggregateBuildErr(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			err := runExecutableErr(t, &c.query, &common.DefaultConf)
+			err := runExecutableErr(t, &c.query, &defaultConf)
 			c.assert(err)
 		})
 	}
@@ -194,6 +224,43 @@ func TestAggregateExecution(t *testing.T) {
 			expectedField: "count_distinct_employees_department_id",
 			expectedValue: int64(1),
 		},
+		{
+			name: "CountDistinctType",
+			aggs: dsl.Aggregates{
+				&dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{Field: "employees.user.is_active", Type: dsl.AggCountDistinct}},
+			},
+			expectedField: "count_distinct_employees_user_is_active",
+			expectedValue: int64(2),
+		},
+		{
+			name: "BoolAndActive",
+			aggs: dsl.Aggregates{
+				&dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{Field: "employees.user.is_active", Type: dsl.AggBoolAnd, Alias: "all_active"}},
+			},
+			expectedField: "all_active",
+			expectedValue: false,
+		},
+		{
+			name: "BoolOrActive",
+			aggs: dsl.Aggregates{
+				&dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{Field: "employees.user.is_active", Type: dsl.AggBoolOr, Alias: "any_active"}},
+			},
+			expectedField: "any_active",
+			expectedValue: true,
+		},
+		{
+			name: "StringAggNames",
+			aggs: dsl.Aggregates{
+				&dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{
+					Field:     "employees.user.name",
+					Type:      dsl.AggStringAgg,
+					Alias:     "names",
+					AggParams: dsl.AggParams{Separator: " | ", Order: dsl.DirDESC},
+				}},
+			},
+			expectedField: "names",
+			expectedValue: "User Three | User Four | User Five",
+		},
 	}
 
 	for _, tt := range tests {
@@ -212,7 +279,7 @@ func TestAggregateExecution(t *testing.T) {
 				},
 			}
 
-			res := runTargetedQuery[entxstd.Entity](t, q, &common.DefaultConf)
+			res := runTargetedQuery[entxstd.Entity](t, q, &defaultConf)
 			val := res[0].Metadatas().Aggregates[tt.expectedField]
 			require.Equal(t, tt.expectedValue, val)
 		})
@@ -250,7 +317,35 @@ func TestAggregateExecutionWithFilters(t *testing.T) {
 		},
 	}
 
-	res := runTargetedQuery[entxstd.Entity](t, q, &common.DefaultConf)
+	res := runTargetedQuery[entxstd.Entity](t, q, &defaultConf)
 	val := res[0].Metadatas().Aggregates[alias]
 	require.Equal(t, expectedCount, val)
 }
+
+func TestAggregateSortExecution(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     *dsl.Sort
+		expected []int
+	}{
+		{"CountDistinct", &dsl.Sort{Field: "employees.user.is_active", Aggregate: dsl.AggCountDistinct, Direction: dsl.DirDESC}, []int{3, 1, 2}},
+		{"Variance", &dsl.Sort{Field: "employees.user.age", Aggregate: dsl.AggVariance, Direction: dsl.DirDESC}, []int{3, 1, 2}},
+		{"GroupConcat", &dsl.Sort{Field: "employees.user.name", Aggregate: dsl.AggGroupConcat, Direction: dsl.DirDESC, AggParams: dsl.AggParams{Order: dsl.DirASC}}, []int{2, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &search.TargetedQuery{
+				From: "Department",
+				QueryOptions: search.QueryOptions{
+					Sorts: dsl.Sorts{tt.sort, {Field: "id"}},
+				},
+			}
+			res := runTargetedQuery[*ent.Department](t, q, &defaultConf)
+			ids := make([]int, len(res))
+			for i, d := range res {
+				ids[i] = d.ID
+			}
+			require.Equal(t, tt.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"e2e/ent"
+	"e2e/ent/entx"
+	"e2e/ent/tag"
+
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/mutate"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func runBulkMutation(t *testing.T, m *mutate.BulkMutation) *mutate.BulkResponse {
+	t.Helper()
+	res, err := m.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+	require.NoError(t, err)
+	return res
+}
+
+func tagNames(t *testing.T, ids ...int) []string {
+	t.Helper()
+	names, err := client.Client.Tag.Query().Where(tag.IDIn(ids...)).Order(ent.Asc(tag.FieldID)).Select(tag.FieldName).Strings(context.Background())
+	require.NoError(t, err)
+	return names
+}
+
+func TestBulkMutateLifecycle(t *testing.T) {
+	cleanupTags(t, 110, 111, 112)
+
+	res := runBulkMutation(t, &mutate.BulkMutation{
+		Node: "Tag",
+		Op:   mutate.OpCreate,
+		Items: []*mutate.BulkItem{
+			{Fields: map[string]any{"id": float64(110), "name": "Bulk one"}, Edges: map[string]*mutate.Edge{"articles": {Attach: []any{float64(1)}}}},
+			{Fields: map[string]any{"id": float64(111), "name": "Bulk two"}},
+			{Fields: map[string]any{"id": float64(112), "name": "Other"}},
+		},
+	})
+	require.Equal(t, 3, res.Affected)
+	require.Empty(t, res.Errors)
+	tags := entxstd.AsTypedEntities[*ent.Tag](res.Data)
+	require.Len(t, tags, 3)
+	require.Equal(t, []int{1}, tagArticleIDs(t, 110))
+
+	res = runBulkMutation(t, &mutate.BulkMutation{
+		Node:    "Tag",
+		Op:      mutate.OpUpdate,
+		Filters: dsl.Filters{{Field: "name", Operator: "starts_with", Value: "Bulk"}},
+		Edges:   map[string]*mutate.Edge{"articles": {Attach: []any{float64(2)}}},
+	})
+	require.Equal(t, 2, res.Affected)
+	require.Equal(t, []int{1, 2}, tagArticleIDs(t, 110))
+	require.Equal(t, []int{2}, tagArticleIDs(t, 111))
+
+	res = runBulkMutation(t, &mutate.BulkMutation{
+		Node:    "Tag",
+		Op:      mutate.OpUpdate,
+		Filters: dsl.Filters{{Field: "id", Operator: "in", Value: []any{float64(111), float64(112)}}, {Field: "name", Operator: "=", Value: "Bulk two"}},
+		Fields:  map[string]any{"name": "Renamed"},
+	})
+	require.Equal(t, 1, res.Affected)
+	require.Equal(t, []string{"Bulk one", "Renamed", "Other"}, tagNames(t, 110, 111, 112))
+
+	res = runBulkMutation(t, &mutate.BulkMutation{
+		Node:    "Tag",
+		Op:      mutate.OpDelete,
+		Filters: dsl.Filters{{Field: "name", Operator: "=", Value: "Other"}},
+	})
+	require.Equal(t, 1, res.Affected)
+	require.Equal(t, []string{"Bulk one", "Renamed"}, tagNames(t, 110, 111, 112))
+}
+
+func TestBulkMutateBestEffort(t *testing.T) {
+	cleanupTags(t, 113, 114)
+
+	items := []*mutate.BulkItem{
+		{Fields: map[string]any{"id": float64(113), "name": "Kept"}},
+		{Fields: map[string]any{"id": float64(114), "name": 42}},
+		{Fields: map[string]any{"id": float64(113), "name": "Duplicate"}},
+	}
+
+	// the whole request fails when an item is invalid
+	m := &mutate.BulkMutation{Node: "Tag", Op: mutate.OpCreate, Items: items}
+	_, err := m.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+	var ierr *mutate.ItemError
+	require.ErrorAs(t, err, &ierr)
+	require.Equal(t, 1, ierr.Index)
+	var verr *mutate.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "MutationValueType", verr.Rule)
+
+	items[1].Fields["name"] = "Valid"
+	_, err = m.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+	var eerr *mutate.ExecError
+	require.ErrorAs(t, err, &eerr)
+	require.Empty(t, tagNames(t, 113, 114))
+
+	// the valid items are created, and the others reported by position
+	items[1].Fields["name"] = 42
+	m.BestEffort = true
+	res := runBulkMutation(t, m)
+	require.Equal(t, 1, res.Affected)
+	require.Len(t, res.Errors, 2)
+	require.Equal(t, 1, res.Errors[0].Index)
+	require.Equal(t, 2, res.Errors[1].Index)
+	require.Equal(t, []string{"Kept"}, tagNames(t, 113, 114))
+}
+
+func TestBulkMutateValidation(t *testing.T) {
+	filters := dsl.Filters{{Field: "name", Operator: "=", Value: "x"}}
+	items := []*mutate.BulkItem{{Fields: map[string]any{"name": "x"}}}
+	tests := []struct {
+		name     string
+		mutation mutate.BulkMutation
+		rule     string
+	}{
+		{"UnknownOp", mutate.BulkMutation{Node: "Tag", Op: mutate.OpUpsert, Items: items}, "BulkOp"},
+		{"CreateWithoutItems", mutate.BulkMutation{Node: "Tag", Op: mutate.OpCreate}, "BulkCreateInput"},
+		{"CreateWithFilters", mutate.BulkMutation{Node: "Tag", Op: mutate.OpCreate, Items: items, Filters: filters}, "BulkCreateInput"},
+		{"UpdateWithoutFilters", mutate.BulkMutation{Node: "Tag", Op: mutate.OpUpdate, Fields: map[string]any{"name": "x"}}, "BulkFiltersRequired"},
+		{"UpdateWithoutValues", mutate.BulkMutation{Node: "Tag", Op: mutate.OpUpdate, Filters: filters}, "BulkUpdateValues"},
+		{"DeleteWithItems", mutate.BulkMutation{Node: "Tag", Op: mutate.OpDelete, Filters: filters, Items: items}, "BulkItemsNotAllowed"},
+		{"DeleteValues", mutate.BulkMutation{Node: "Tag", Op: mutate.OpDelete, Filters: filters, Fields: map[string]any{"name": "x"}}, "MutationDeleteValues"},
+		{"UnknownNode", mutate.BulkMutation{Node: "Unknown", Op: mutate.OpDelete, Filters: filters}, "UnknownNode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.mutation.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+			var verr *mutate.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.rule, verr.Rule)
+		})
+	}
+
+	t.Run("MaxBulkItems", func(t *testing.T) {
+		cfg := mutate.NewConfig(mutate.WithMaxBulkItems(1))
+		m := mutate.BulkMutation{Node: "Tag", Op: mutate.OpCreate, Items: append(items, items...)}
+		_, err := m.Execute(context.Background(), client, entx.Graph, cfg)
+		var verr *mutate.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "MaxBulkItems", verr.Rule)
+	})
+
+	t.Run("ErrorPerItem", func(t *testing.T) {
+		m := &mutate.BulkMutation{
+			Node:       "Tag",
+			Op:         mutate.OpCreate,
+			BestEffort: true,
+			Items:      []*mutate.BulkItem{{Fields: map[string]any{"label": "x"}}, nil},
+		}
+		build, err := m.Build(entx.Graph)
+		require.NoError(t, err)
+		require.Len(t, build.Errors, 2)
+		var berr *mutate.QueryBuildError
+		require.ErrorAs(t, build.Errors[0], &berr)
+		var verr *mutate.ValidationError
+		require.ErrorAs(t, build.Errors[1], &verr)
+		require.Equal(t, "MutationFieldRequired", verr.Rule)
+	})
+}
@@ -0,0 +1,170 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"e2e/ent"
+	"e2e/ent/entx"
+
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func newCacheConfig(store common.Cache, nodeTTLs map[string]time.Duration) *search.Config {
+	return newConfig(common.WithCache(common.CacheConfig{
+		Store:      store,
+		DefaultTTL: time.Minute,
+		NodeTTLs:   nodeTTLs,
+	}))
+}
+
+func TestCacheSearch(t *testing.T) {
+	cleanupTags(t, 130)
+	ctx := context.Background()
+	_, err := client.Client.Tag.Create().SetID(130).SetName("Cached").Save(ctx)
+	require.NoError(t, err)
+
+	store := common.NewLRUCache(10)
+	cfg := newCacheConfig(store, nil)
+	query := func() *search.TargetedQuery {
+		return &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Filters:  dsl.Filters{{Field: "id", Operator: "=", Value: 130}},
+				Includes: dsl.Includes{{Relation: "articles"}},
+			},
+		}
+	}
+
+	tags := runTargetedQuery[*ent.Tag](t, query(), cfg)
+	require.Equal(t, "Cached", tags[0].Name)
+	require.Equal(t, 1, store.Len())
+
+	// written without invalidation, the cached response is returned
+	require.NoError(t, client.Client.Tag.UpdateOneID(130).SetName("Renamed").Exec(ctx))
+	tags = runTargetedQuery[*ent.Tag](t, query(), cfg)
+	require.Equal(t, "Cached", tags[0].Name)
+
+	// the entry depends on the included node
+	store.Invalidate(ctx, "Article")
+	require.Equal(t, 0, store.Len())
+	tags = runTargetedQuery[*ent.Tag](t, query(), cfg)
+	require.Equal(t, "Renamed", tags[0].Name)
+
+	t.Run("InvalidationHook", func(t *testing.T) {
+		articles := &search.TargetedQuery{From: "Article", QueryOptions: search.QueryOptions{Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 1}}}}
+		runExecutable(t, articles, cfg)
+		require.Equal(t, 2, store.Len())
+
+		// attaching an article to a tag invalidates the entries of both nodes
+		update := client.Client.Tag.UpdateOneID(130).AddArticleIDs(1)
+		hook := common.CacheInvalidationHook(store, entx.Graph)
+		_, err := hook(ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			return nil, update.Exec(ctx)
+		})).Mutate(ctx, update.Mutation())
+		require.NoError(t, err)
+		require.Equal(t, 0, store.Len())
+	})
+
+	t.Run("InvalidationHookTx", func(t *testing.T) {
+		for _, commit := range []bool{true, false} {
+			runExecutable(t, query(), cfg)
+			require.Equal(t, 1, store.Len())
+
+			tx, err := client.Client.Tx(ctx)
+			require.NoError(t, err)
+			update := tx.Tag.UpdateOneID(130).SetName("InTx")
+			hook := common.CacheInvalidationHook(store, entx.Graph)
+			_, err = hook(ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				return nil, update.Exec(ctx)
+			})).Mutate(ctx, update.Mutation())
+			require.NoError(t, err)
+			// the entries are invalidated once the transaction committed
+			require.Equal(t, 1, store.Len())
+
+			if commit {
+				require.NoError(t, tx.Commit())
+				require.Equal(t, 0, store.Len())
+			} else {
+				require.NoError(t, tx.Rollback())
+				require.Equal(t, 1, store.Len())
+				store.Invalidate(ctx, "Tag")
+			}
+		}
+	})
+
+	t.Run("NodeTTL", func(t *testing.T) {
+		store := common.NewLRUCache(10)
+		runExecutable(t, query(), newCacheConfig(store, map[string]time.Duration{"Article": -1}))
+		require.Equal(t, 0, store.Len())
+	})
+
+	t.Run("PivotDependency", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			options search.QueryOptions
+		}{
+			{"Filter", search.QueryOptions{Filters: dsl.Filters{{Field: "tags.pivot.position", Operator: "=", Value: 2}}}},
+			{"Include", search.QueryOptions{Includes: dsl.Includes{{Relation: "tags", Pivot: []string{"position"}}}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := common.NewLRUCache(10)
+				runExecutable(t, &search.TargetedQuery{From: "Article", QueryOptions: tt.options}, newCacheConfig(store, nil))
+				require.Equal(t, 1, store.Len())
+
+				// the entry depends on the pivot rows it read
+				store.Invalidate(ctx, "ArticleTag")
+				require.Equal(t, 0, store.Len())
+			})
+		}
+	})
+
+	t.Run("PolicyScope", func(t *testing.T) {
+		users := &search.TargetedQuery{From: "User"}
+		store := common.NewLRUCache(10)
+		runExecutable(t, users, newCacheConfig(store, nil))
+		require.Equal(t, 0, store.Len())
+
+		cfg := newConfig(common.WithCache(common.CacheConfig{
+			Store:      store,
+			DefaultTTL: time.Minute,
+			Scope:      func(context.Context) string { return "tenant" },
+		}))
+		runExecutable(t, users, cfg)
+		require.Equal(t, 1, store.Len())
+	})
+}
+
+func TestCacheScalars(t *testing.T) {
+	cleanupTags(t, 131)
+	ctx := context.Background()
+
+	store := common.NewLRUCache(10)
+	cfg := newCacheConfig(store, nil)
+	group := &search.QueryGroup{
+		Searches:   search.NamedQueries{{Key: "tags", TargetedQuery: search.TargetedQuery{From: "Tag", QueryOptions: search.QueryOptions{WithPagination: true, EnableTransaction: new(bool)}}}},
+		Aggregates: search.OverallAggregates{{BaseAggregate: dsl.BaseAggregate{Field: "Tag", Type: dsl.AggCount, Alias: "total"}}},
+	}
+
+	res := runExecutable(t, group, cfg)
+	total := res.Meta.Aggregates["total"]
+	require.Equal(t, total, int64(res.Searches["tags"].Meta.Paginate.Total))
+	// the pagination count and the aggregate run the same query, cached once
+	require.Equal(t, 2, store.Len())
+
+	_, err := client.Client.Tag.Create().SetID(131).SetName("Uncached").Save(ctx)
+	require.NoError(t, err)
+	res = runExecutable(t, group, cfg)
+	require.Equal(t, total, res.Meta.Aggregates["total"])
+	require.Equal(t, total, int64(res.Searches["tags"].Meta.Paginate.Total))
+
+	store.Invalidate(ctx, "Tag")
+	res = runExecutable(t, group, cfg)
+	require.Equal(t, total.(int64)+1, res.Meta.Aggregates["total"])
+	require.Equal(t, total.(int64)+1, int64(res.Searches["tags"].Meta.Paginate.Total))
+}
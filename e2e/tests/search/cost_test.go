@@ -0,0 +1,153 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"e2e/ent"
+
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func newCostConfig(budget int, downgrade bool) *search.Config {
+	return newConfig(common.WithCost(common.CostConfig{
+		Weights:   common.DefaultCostWeights,
+		Budget:    func(context.Context) int { return budget },
+		Downgrade: downgrade,
+	}))
+}
+
+// usersCostQuery costs 33 with the default weights: the search (10), a filter on
+// an unindexed field (10), an O2M include (5) and its 8 rows at depth 1 (8).
+func usersCostQuery() *search.TargetedQuery {
+	return &search.TargetedQuery{
+		From: "User",
+		QueryOptions: search.QueryOptions{
+			Filters:  dsl.Filters{{Field: "age", Operator: dsl.OpGreaterThan, Value: 0}},
+			Includes: dsl.Includes{{Relation: "articles", Limit: dsl.Limit{Limit: 8}}},
+		},
+	}
+}
+
+// tagsCostQuery costs 24 with the default weights: the search (10), a filter on
+// the primary key (0), an M2M include (5+5) and its 4 rows at depth 1 (4).
+func tagsCostQuery() *search.TargetedQuery {
+	return &search.TargetedQuery{
+		From: "Tag",
+		QueryOptions: search.QueryOptions{
+			Filters:  dsl.Filters{{Field: "id", Operator: "=", Value: 1}},
+			Includes: dsl.Includes{{Relation: "articles", Limit: dsl.Limit{Limit: 4}}},
+		},
+	}
+}
+
+func TestCostReported(t *testing.T) {
+	cfg := newCostConfig(0, false)
+
+	res := runExecutable(t, usersCostQuery(), cfg)
+	require.Equal(t, &common.Cost{Total: 33}, res.Meta.Cost)
+
+	group := &search.QueryGroup{
+		Searches: search.NamedQueries{
+			{Key: "users", TargetedQuery: *usersCostQuery()},
+			{Key: "tags", TargetedQuery: *tagsCostQuery()},
+		},
+	}
+	groupRes := runExecutable(t, group, cfg)
+	require.Equal(t, &common.Cost{Total: 57}, groupRes.Meta.Cost)
+	require.Nil(t, groupRes.Searches["users"].Meta.Cost)
+
+	t.Run("Disabled", func(t *testing.T) {
+		res := runExecutable(t, usersCostQuery(), &defaultConf)
+		require.Nil(t, res.Meta.Cost)
+	})
+
+	t.Run("AggregateSort", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From:         "User",
+			QueryOptions: search.QueryOptions{Sorts: dsl.Sorts{{Field: "articles.id", Aggregate: dsl.AggCount}}},
+		}
+		// the search (10), the relation (5) and the sort on its count (20)
+		res := runExecutable(t, q, cfg)
+		require.Equal(t, 35, res.Meta.Cost.Total)
+	})
+
+	t.Run("FilteredAggregateSort", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{Sorts: dsl.Sorts{{
+				Field: "articles.id", Aggregate: dsl.AggCount,
+				Filters: dsl.Filters{{Field: "published", Operator: "=", Value: true}},
+			}}},
+		}
+		// the search (10), the relation (5), the sort on its count (20)
+		// and the filter on an unindexed field of the related rows (10)
+		res := runExecutable(t, q, cfg)
+		require.Equal(t, 45, res.Meta.Cost.Total)
+	})
+
+	t.Run("Recursive", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Employee",
+			QueryOptions: search.QueryOptions{Filters: dsl.Filters{{
+				Relation: "manager", Recursive: &dsl.Recursion{MaxDepth: 3},
+				Field: "id", Operator: "=", Value: 1,
+			}}},
+		}
+		// the search (10) and the relation followed at each level (3*5)
+		res := runExecutable(t, q, cfg)
+		require.Equal(t, 25, res.Meta.Cost.Total)
+	})
+}
+
+func TestCostBudget(t *testing.T) {
+	t.Run("Exceeded", func(t *testing.T) {
+		err := runExecutableErr(t, usersCostQuery(), newCostConfig(30, false))
+		var verr *search.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "CostBudgetExceeded", verr.Rule)
+	})
+
+	t.Run("Within", func(t *testing.T) {
+		res := runExecutable(t, usersCostQuery(), newCostConfig(33, false))
+		require.Equal(t, &common.Cost{Total: 33, Budget: 33}, res.Meta.Cost)
+	})
+
+	t.Run("Downgrade", func(t *testing.T) {
+		// the include limit is halved once, from 8 to 4 rows
+		res := runExecutable(t, usersCostQuery(), newCostConfig(30, true))
+		require.Equal(t, &common.Cost{Total: 29, Budget: 30, Downgraded: true}, res.Meta.Cost)
+		for _, u := range entxstd.AsTypedEntities[*ent.User](res.Data.([]entxstd.Entity)) {
+			require.LessOrEqual(t, len(u.Edges.Articles), 4)
+		}
+	})
+
+	t.Run("DowngradeExhausted", func(t *testing.T) {
+		// lowered down to one row, the include still costs 26
+		err := runExecutableErr(t, usersCostQuery(), newCostConfig(25, true))
+		var verr *search.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "CostBudgetExceeded", verr.Rule)
+	})
+
+	t.Run("Transactions", func(t *testing.T) {
+		bundle := &search.QueryBundle{
+			Transactions: search.TxQueryGroups{{
+				QueryGroup: search.QueryGroup{
+					Searches: search.NamedQueries{
+						{Key: "users", TargetedQuery: *usersCostQuery()},
+						{Key: "tags", TargetedQuery: *tagsCostQuery()},
+					},
+				},
+			}},
+		}
+		err := runExecutableErr(t, bundle, newCostConfig(50, false))
+		var verr *search.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "CostBudgetExceeded", verr.Rule)
+	})
+}
@@ -0,0 +1,171 @@
+package e2e_search_test
+
+import (
+	"e2e/ent"
+	"testing"
+
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorValidation(t *testing.T) {
+	cases := []struct {
+		expectedRule string
+		options      search.QueryOptions
+	}{
+		{"CursorDirectionConflict", search.QueryOptions{Pageable: dsl.Pageable{Cursor: dsl.Cursor{After: "a", Before: "b"}}}},
+		{"CursorPaginationConflict", search.QueryOptions{WithPagination: true, Pageable: dsl.Pageable{Cursor: dsl.Cursor{WithCursor: true}}}},
+		{"CursorSortUnsupported", search.QueryOptions{Sorts: dsl.Sorts{{Field: "articles.id", Aggregate: dsl.AggCount}}, Pageable: dsl.Pageable{Cursor: dsl.Cursor{WithCursor: true}}}},
+		{"InvalidCursor", search.QueryOptions{Pageable: dsl.Pageable{Cursor: dsl.Cursor{After: "%%%"}}}},
+		{"CursorBackwardNotAllowed", search.QueryOptions{Includes: dsl.Includes{{Relation: "articles", Cursor: dsl.Cursor{Before: "a"}}}}},
+	}
+	for _, c := range cases {
+		t.Run(c.expectedRule, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: c.options}
+			err := runExecutableErr(t, &q, &defaultConf)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, c.expectedRule, verr.Rule)
+		})
+	}
+}
+
+func TestCursorMismatch(t *testing.T) {
+	cursor, err := common.EncodeCursor([]any{int64(1)})
+	require.NoError(t, err)
+
+	q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{
+		Sorts:    dsl.Sorts{{Field: "age"}},
+		Pageable: dsl.Pageable{Cursor: dsl.Cursor{After: cursor}},
+	}}
+	err = runExecutableErr(t, &q, &defaultConf)
+	var qerr *search.QueryBuildError
+	require.ErrorAs(t, err, &qerr)
+}
+
+func userIDs(res *search.SearchResponse) []int {
+	users := entxstd.AsTypedEntities[*ent.User](res.Data.([]entxstd.Entity))
+	ids := make([]int, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func TestCursorNavigation(t *testing.T) {
+	cases := []struct {
+		name  string
+		sorts dsl.Sorts
+		pages [][]int
+	}{
+		{"RowValue", dsl.Sorts{{Field: "age", Direction: dsl.DirDESC}}, [][]int{{5, 4}, {3, 2}, {1}}},
+		{"PrimaryKeyOnly", nil, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"MixedDirections", dsl.Sorts{{Field: "is_active"}, {Field: "age", Direction: dsl.DirDESC}}, [][]int{{4, 3}, {5, 2}, {1}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query := func(cursor dsl.Cursor) *search.SearchResponse {
+				q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{
+					Sorts:    c.sorts,
+					Pageable: dsl.Pageable{Limit: dsl.Limit{Limit: 2}, Cursor: cursor},
+				}}
+				return runExecutable(t, &q, &defaultConf)
+			}
+
+			var (
+				responses []*search.SearchResponse
+				cursor    = dsl.Cursor{WithCursor: true}
+			)
+			for i, want := range c.pages {
+				res := query(cursor)
+				require.Equal(t, want, userIDs(res))
+				require.Equal(t, len(want), res.Meta.Count)
+				require.Nil(t, res.Meta.Paginate)
+
+				isLast := i == len(c.pages)-1
+				require.Equal(t, !isLast, res.Meta.Cursor.HasNext)
+				require.Equal(t, i > 0, res.Meta.Cursor.PrevCursor != "")
+				responses = append(responses, res)
+				cursor = dsl.Cursor{After: res.Meta.Cursor.NextCursor}
+			}
+
+			// walk back from the last page
+			for i := len(c.pages) - 1; i > 0; i-- {
+				res := query(dsl.Cursor{Before: responses[i].Meta.Cursor.PrevCursor})
+				require.Equal(t, c.pages[i-1], userIDs(res))
+				require.True(t, res.Meta.Cursor.HasNext)
+				require.Equal(t, i-1 > 0, res.Meta.Cursor.PrevCursor != "")
+			}
+		})
+	}
+}
+
+func TestCursorNullable(t *testing.T) {
+	// only the employee 1 has no manager, NULL being ordered as the smallest value
+	cases := []struct {
+		name      string
+		direction dsl.Direction
+		pages     [][]int
+	}{
+		{"Ascending", dsl.DirASC, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"Descending", dsl.DirDESC, [][]int{{5, 4}, {3, 2}, {1}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query := func(cursor dsl.Cursor) *search.SearchResponse {
+				q := search.TargetedQuery{From: "Employee", QueryOptions: search.QueryOptions{
+					Sorts:    dsl.Sorts{{Field: "manager_id", Direction: c.direction}},
+					Pageable: dsl.Pageable{Limit: dsl.Limit{Limit: 2}, Cursor: cursor},
+				}}
+				return runExecutable(t, &q, &defaultConf)
+			}
+			ids := func(res *search.SearchResponse) []int {
+				return employeeIDs(entxstd.AsTypedEntities[*ent.Employee](res.Data.([]entxstd.Entity)))
+			}
+
+			var (
+				responses []*search.SearchResponse
+				cursor    = dsl.Cursor{WithCursor: true}
+			)
+			for i, want := range c.pages {
+				res := query(cursor)
+				require.Equal(t, want, ids(res))
+				require.Equal(t, i < len(c.pages)-1, res.Meta.Cursor.HasNext)
+				responses = append(responses, res)
+				cursor = dsl.Cursor{After: res.Meta.Cursor.NextCursor}
+			}
+
+			for i := len(c.pages) - 1; i > 0; i-- {
+				res := query(dsl.Cursor{Before: responses[i].Meta.Cursor.PrevCursor})
+				require.Equal(t, c.pages[i-1], ids(res))
+			}
+		})
+	}
+}
+
+func TestCursorInclude(t *testing.T) {
+	query := func(cursor dsl.Cursor) *ent.User {
+		q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{
+			Filters: dsl.Filters{{Field: "id", Operator: dsl.OpEqual, Value: 1}},
+			Includes: dsl.Includes{{
+				Relation: "articles",
+				Sort:     dsl.Sorts{{Field: "id", Direction: dsl.DirDESC}},
+				Limit:    dsl.Limit{Limit: 1},
+				Cursor:   cursor,
+			}},
+		}}
+		return runTargetedQuery[*ent.User](t, &q, &defaultConf)[0]
+	}
+
+	first := query(dsl.Cursor{WithCursor: true}).Edges.Articles
+	require.Len(t, first, 1)
+	require.Equal(t, 2, first[0].ID)
+	require.NotEmpty(t, first[0].Metadatas().Cursor)
+
+	next := query(dsl.Cursor{After: first[0].Metadatas().Cursor}).Edges.Articles
+	require.Len(t, next, 1)
+	require.Equal(t, 1, next[0].ID)
+}
@@ -0,0 +1,96 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"e2e/ent/entx"
+
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainTargetedQuery(t *testing.T) {
+	q := &search.TargetedQuery{
+		From: "User",
+		QueryOptions: search.QueryOptions{
+			Filters:        dsl.Filters{{Field: "id", Operator: dsl.OpEqual, Value: 1}},
+			Includes:       dsl.Includes{{Relation: "articles", Includes: dsl.Includes{{Relation: "tags"}}}},
+			WithPagination: true,
+		},
+	}
+
+	res, err := q.Explain(context.Background(), client, entx.Graph, &defaultConf, false)
+	require.NoError(t, err)
+	require.Len(t, res.Queries, 4)
+
+	kinds := make([]common.ExplainKind, len(res.Queries))
+	for i, eq := range res.Queries {
+		kinds[i] = eq.Kind
+		require.NotEmpty(t, eq.SQL)
+		require.Nil(t, eq.Plan)
+	}
+	require.Equal(t, []common.ExplainKind{
+		common.ExplainKindSearch,
+		common.ExplainKindInclude,
+		common.ExplainKindInclude,
+		common.ExplainKindPaginate,
+	}, kinds)
+	require.Equal(t, "User.articles", res.Queries[1].Include)
+	require.Equal(t, "Article.tags", res.Queries[2].Include)
+	require.Contains(t, res.Queries[0].SQL, entx.Graph["User"].Table())
+	require.Contains(t, res.Queries[0].Args, 1)
+
+	// the rendered statement is the executed one
+	root := res.Queries[0]
+	rows, err := client.QueryContext(context.Background(), root.SQL, root.Args...)
+	require.NoError(t, err)
+	defer rows.Close()
+	var count int
+	for rows.Next() {
+		count++
+	}
+	require.Equal(t, 1, count)
+}
+
+func TestExplainPlan(t *testing.T) {
+	q := &search.TargetedQuery{From: "Tag", QueryOptions: search.QueryOptions{Includes: dsl.Includes{{Relation: "articles"}}}}
+	res, err := q.Explain(context.Background(), client, entx.Graph, &defaultConf, true)
+	require.NoError(t, err)
+	require.Len(t, res.Queries, 2)
+	for _, eq := range res.Queries {
+		require.NotEmpty(t, eq.Plan, eq.SQL)
+	}
+}
+
+func TestExplainGroup(t *testing.T) {
+	group := &search.QueryGroup{
+		Searches: search.NamedQueries{
+			{Key: "users", TargetedQuery: search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{WithPagination: true, EnableTransaction: new(bool)}}},
+			{Key: "tags", TargetedQuery: search.TargetedQuery{From: "Tag"}},
+		},
+		Aggregates: search.OverallAggregates{{BaseAggregate: dsl.BaseAggregate{Field: "Tag", Type: dsl.AggCount, Alias: "total"}}},
+	}
+
+	res, err := group.Explain(context.Background(), client, entx.Graph, &defaultConf, false)
+	require.NoError(t, err)
+	require.Len(t, res.Queries, 3)
+	require.Equal(t, "users", res.Queries[0].Key)
+	require.Equal(t, "tags", res.Queries[1].Key)
+
+	// the pagination count is batched with the aggregate
+	scalars := res.Queries[2]
+	require.Equal(t, common.ExplainKindScalars, scalars.Kind)
+
+	require.Equal(t, []string{"users", "total"}, scalars.Scalars)
+
+	rows, err := client.QueryContext(context.Background(), scalars.SQL, scalars.Args...)
+	require.NoError(t, err)
+	defer rows.Close()
+	var users, total int64
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&users, &total))
+	require.Equal(t, total, runExecutable(t, group, &defaultConf).Meta.Aggregates["total"])
+}
@@ -4,6 +4,7 @@ import (
 	"e2e/ent"
 	"fmt"
 	"testing"
+	"time"
 
 	entxstd "github.com/brice-74/entx"
 
@@ -58,7 +59,7 @@ func TestFilterBuildErr(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			err := runExecutableErr(t, &c.query, &common.DefaultConf)
+			err := runExecutableErr(t, &c.query, &defaultConf)
 			c.assert(err)
 		})
 	}
@@ -70,16 +71,16 @@ func TestFilterValidation(t *testing.T) {
 		filters      dsl.Filters
 		cfg          *search.Config
 	}{
-		{"MaxFilterTreeCount", dsl.Filters{{}, {}}, common.NewConfig(common.WithFilterConfig(common.FilterConfig{MaxFilterTreeCount: 1}))},
-		{"MaxFilterRelationsPerTree", dsl.Filters{{Relation: "articles"}, {Relation: "comments"}}, common.NewConfig(common.WithFilterConfig(common.FilterConfig{MaxRelationTotalCount: 1}))},
-		{"MaxRelationChainDepth", dsl.Filters{{Relation: "articles.tags"}}, common.NewConfig(common.WithFilterConfig(common.FilterConfig{MaxRelationChainDepth: 1}))},
-		{"InvalidFilterRelationFormat", dsl.Filters{{Relation: "articles..tags"}}, &common.DefaultConf},
-		{"InvalidFilterFieldFormat", dsl.Filters{{Field: "articles..name"}}, &common.DefaultConf},
-		{"OperatorPrimitiveValue", dsl.Filters{{Field: "name", Operator: dsl.OpEqual, Value: []string{""}}}, &common.DefaultConf},
-		{"OperatorSliceValue", dsl.Filters{{Field: "name", Operator: dsl.OpIn, Value: []struct{}{}}}, &common.DefaultConf},
-		{"OperatorNumberValue", dsl.Filters{{Field: "name", Operator: dsl.OpGreaterThan, Value: ""}}, &common.DefaultConf},
-		{"OperatorStringValue", dsl.Filters{{Field: "name", Operator: dsl.OpLike, Value: 0}}, &common.DefaultConf},
-		{"InvalidOperator", dsl.Filters{{Field: "name", Operator: "#"}}, &common.DefaultConf},
+		{"MaxFilterTreeCount", dsl.Filters{{}, {}}, newConfig(common.WithFilterConfig(common.FilterConfig{MaxFilterTreeCount: 1}))},
+		{"MaxFilterRelationsPerTree", dsl.Filters{{Relation: "articles"}, {Relation: "comments"}}, newConfig(common.WithFilterConfig(common.FilterConfig{MaxRelationTotalCount: 1}))},
+		{"MaxRelationChainDepth", dsl.Filters{{Relation: "articles.tags"}}, newConfig(common.WithFilterConfig(common.FilterConfig{MaxRelationChainDepth: 1}))},
+		{"InvalidFilterRelationFormat", dsl.Filters{{Relation: "articles..tags"}}, &defaultConf},
+		{"InvalidFilterFieldFormat", dsl.Filters{{Field: "articles..name"}}, &defaultConf},
+		{"OperatorPrimitiveValue", dsl.Filters{{Field: "name", Operator: dsl.OpEqual, Value: []string{""}}}, &defaultConf},
+		{"OperatorSliceValue", dsl.Filters{{Field: "name", Operator: dsl.OpIn, Value: []struct{}{}}}, &defaultConf},
+		{"OperatorNumberValue", dsl.Filters{{Field: "name", Operator: dsl.OpGreaterThan, Value: ""}}, &defaultConf},
+		{"OperatorStringValue", dsl.Filters{{Field: "name", Operator: dsl.OpLike, Value: 0}}, &defaultConf},
+		{"InvalidOperator", dsl.Filters{{Field: "name", Operator: "#"}}, &defaultConf},
 	}
 	for _, c := range cases {
 		t.Run(c.expectedRule, func(t *testing.T) {
@@ -114,7 +115,7 @@ func TestFilterOperators(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{{Field: tt.field, Operator: tt.op, Value: tt.value}}}}
-			users := runTargetedQuery[*ent.User](t, &q, &common.DefaultConf)
+			users := runTargetedQuery[*ent.User](t, &q, &defaultConf)
 			if tt.wantVals != nil {
 				ages := make([]int, len(users))
 				for i, u := range users {
@@ -135,6 +136,110 @@ func TestFilterOperators(t *testing.T) {
 	}
 }
 
+func TestFilterExtendedOperators(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter *dsl.Filter
+		ids    []int
+	}{
+		{"Between", &dsl.Filter{Field: "age", Operator: dsl.OpBetween, Value: []any{30, 50}}, []int{2, 3, 4}},
+		{"NotBetween", &dsl.Filter{Field: "age", Operator: dsl.OpNotBetween, Value: []any{30, 50}}, []int{1, 5}},
+		{"BetweenTimes", &dsl.Filter{Field: "created_at", Operator: dsl.OpBetween, Value: []any{"2000-01-01", "now+1d"}}, []int{1, 2, 3, 4, 5}},
+		{"IsNull", &dsl.Filter{Field: "age", Operator: dsl.OpIsNull}, []int{}},
+		{"IsNotNull", &dsl.Filter{Field: "age", Operator: dsl.OpIsNotNull}, []int{1, 2, 3, 4, 5}},
+		{"StartsWith", &dsl.Filter{Field: "name", Operator: dsl.OpStartsWith, Value: "User T"}, []int{2, 3}},
+		{"EndsWith", &dsl.Filter{Field: "email", Operator: dsl.OpEndsWith, Value: "4@example.com"}, []int{4}},
+		{"EndsWithEscaped", &dsl.Filter{Field: "email", Operator: dsl.OpEndsWith, Value: "%.com"}, []int{}},
+		{"ILike", &dsl.Filter{Field: "name", Operator: dsl.OpILike, Value: "user f"}, []int{4, 5}},
+		{"EqualFold", &dsl.Filter{Field: "email", Operator: dsl.OpEqualFold, Value: "USER1@EXAMPLE.COM"}, []int{1}},
+		{"Regex", &dsl.Filter{Field: "email", Operator: dsl.OpRegex, Value: "^user[1-2]@"}, []int{1, 2}},
+		{"NestedIsNull", &dsl.Filter{Field: "employee.manager_id", Operator: dsl.OpIsNull}, []int{1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{c.filter}}}
+			users := runTargetedQuery[*ent.User](t, &q, &defaultConf)
+			ids := make([]int, len(users))
+			for i, u := range users {
+				ids[i] = u.ID
+			}
+			require.ElementsMatch(t, c.ids, ids)
+		})
+	}
+}
+
+func TestFilterExtendedOperatorsValidation(t *testing.T) {
+	cases := []struct {
+		expectedRule string
+		filter       *dsl.Filter
+	}{
+		{"OperatorRangeValue", &dsl.Filter{Field: "age", Operator: dsl.OpBetween, Value: []any{1}}},
+		{"OperatorRangeValue", &dsl.Filter{Field: "age", Operator: dsl.OpNotBetween, Value: []any{1, "a"}}},
+		{"OperatorNullValue", &dsl.Filter{Field: "age", Operator: dsl.OpIsNull, Value: 1}},
+		{"OperatorStringValue", &dsl.Filter{Field: "name", Operator: dsl.OpStartsWith, Value: 1}},
+		{"OperatorStringValue", &dsl.Filter{Field: "name", Operator: dsl.OpRegex, Value: []any{"a"}}},
+		{"FilterOperatorType", &dsl.Filter{Field: "email", Operator: dsl.OpIsNull}},
+		{"FilterOperatorType", &dsl.Filter{Field: "age", Operator: dsl.OpEndsWith, Value: "0"}},
+		{"FilterOperatorType", &dsl.Filter{Field: "is_active", Operator: dsl.OpBetween, Value: []any{0, 1}}},
+	}
+	for _, c := range cases {
+		t.Run(c.expectedRule, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{c.filter}}}
+			err := runExecutableErr(t, &q, &defaultConf)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, c.expectedRule, verr.Rule)
+		})
+	}
+}
+
+func TestFilterTimeValues(t *testing.T) {
+	future := newConfig(common.WithClock(func() time.Time { return time.Now().AddDate(0, 1, 0) }))
+	cases := []struct {
+		name  string
+		op    dsl.Operator
+		value any
+		cfg   *search.Config
+		count int
+	}{
+		{"DateOnly", dsl.OpGreaterThan, "2000-01-01", &defaultConf, 5},
+		{"RFC3339", dsl.OpLessThan, "2000-01-01T00:00:00Z", &defaultConf, 0},
+		{"TimeValue", dsl.OpGreaterEqual, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), &defaultConf, 5},
+		{"Relative", dsl.OpGreaterEqual, "now-7d", &defaultConf, 5},
+		{"RelativeInjectedClock", dsl.OpGreaterEqual, "now-7d", future, 0},
+		{"RelativeCompound", dsl.OpLessEqual, "now-1M+1d", future, 5},
+		{"InDates", dsl.OpNotIn, []any{"2000-01-01", "now-1y"}, &defaultConf, 5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{{Field: "created_at", Operator: c.op, Value: c.value}}}}
+			users := runTargetedQuery[*ent.User](t, &q, c.cfg)
+			require.Len(t, users, c.count)
+		})
+	}
+}
+
+func TestFilterTimeValidation(t *testing.T) {
+	cases := []struct {
+		expectedRule string
+		filter       *dsl.Filter
+	}{
+		{"OperatorNumberValue", &dsl.Filter{Field: "created_at", Operator: dsl.OpGreaterThan, Value: "yesterday"}},
+		{"OperatorNumberValue", &dsl.Filter{Field: "created_at", Operator: dsl.OpLessThan, Value: "now-7x"}},
+		{"FilterTimeValue", &dsl.Filter{Field: "created_at", Operator: dsl.OpEqual, Value: "2025-13-01"}},
+		{"FilterValueType", &dsl.Filter{Field: "age", Operator: dsl.OpGreaterThan, Value: "now"}},
+	}
+	for _, c := range cases {
+		t.Run(c.expectedRule, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{c.filter}}}
+			err := runExecutableErr(t, &q, &defaultConf)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, c.expectedRule, verr.Rule)
+		})
+	}
+}
+
 func TestFilterCondition(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -218,7 +323,7 @@ func TestFilterCondition(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			users := runTargetedQuery[entxstd.Entity](t, &c.query, &common.DefaultConf)
+			users := runTargetedQuery[entxstd.Entity](t, &c.query, &defaultConf)
 			require.Len(t, users, c.wantCount)
 			c.assertUsers(t, users)
 		})
@@ -264,7 +369,7 @@ func TestFilterRelationTypes(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			res := runTargetedQuery[entxstd.Entity](t, &c.query, &common.DefaultConf)
+			res := runTargetedQuery[entxstd.Entity](t, &c.query, &defaultConf)
 			var got []int
 			for _, e := range res {
 				switch v := e.(type) {
@@ -282,3 +387,63 @@ func TestFilterRelationTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterQuantifiers(t *testing.T) {
+	titleLike := func(v string) dsl.Filters {
+		return dsl.Filters{{Field: "title", Operator: dsl.OpLike, Value: v}}
+	}
+	cases := []struct {
+		name   string
+		filter *dsl.Filter
+		ids    []int
+	}{
+		{"Exists", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantExists}, []int{1, 3}},
+		{"NotExists", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantNotExists}, []int{2, 4, 5}},
+		{"NoneWithoutCondition", &dsl.Filter{Relation: "comments", Quantifier: dsl.QuantNone}, []int{4, 5}},
+		{"Any", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantAny, And: titleLike("SQL")}, []int{1}},
+		{"None", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantNone, And: titleLike("SQL")}, []int{2, 3, 4, 5}},
+		{"All", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantAll, And: titleLike("Go")}, []int{2, 4, 5}},
+		{"AllOnLastSegment", &dsl.Filter{Relation: "articles.tags", Quantifier: dsl.QuantAll, Field: "name", Operator: dsl.OpEqual, Value: "Go"}, []int{1}},
+		{"CountGreaterEqual", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpGreaterEqual, Value: 2}}, []int{1}},
+		{"CountZero", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpEqual, Value: 0}}, []int{2, 4, 5}},
+		{"CountBetween", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpBetween, Value: []any{1, 2}}}, []int{1, 3}},
+		{"CountWithCondition", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpEqual, Value: 1}, And: titleLike("o")}, []int{3}},
+		{"CountManyToMany", &dsl.Filter{Relation: "articles.tags", Count: &dsl.RelationCount{Operator: dsl.OpGreaterThan, Value: 1}}, []int{3}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{c.filter}}}
+			users := runTargetedQuery[*ent.User](t, &q, &defaultConf)
+			ids := make([]int, len(users))
+			for i, u := range users {
+				ids[i] = u.ID
+			}
+			require.ElementsMatch(t, c.ids, ids)
+		})
+	}
+}
+
+func TestFilterQuantifiersValidation(t *testing.T) {
+	cases := []struct {
+		expectedRule string
+		filter       *dsl.Filter
+	}{
+		{"QuantifierWithoutRelation", &dsl.Filter{Quantifier: dsl.QuantNone, Field: "age", Operator: dsl.OpEqual, Value: 1}},
+		{"QuantifierCountConflict", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantAny, Count: &dsl.RelationCount{Operator: dsl.OpEqual, Value: 1}}},
+		{"RelationCountOperator", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpLike, Value: 1}}},
+		{"RelationCountValue", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpEqual, Value: 1.5}}},
+		{"RelationCountValue", &dsl.Filter{Relation: "articles", Count: &dsl.RelationCount{Operator: dsl.OpBetween, Value: []any{1}}}},
+		{"QuantifierConditionRequired", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantAll}},
+		{"QuantifierConditionNotAllowed", &dsl.Filter{Relation: "articles", Quantifier: dsl.QuantExists, Field: "id", Operator: dsl.OpEqual, Value: 1}},
+		{"InvalidQuantifier", &dsl.Filter{Relation: "articles", Quantifier: "some"}},
+	}
+	for _, c := range cases {
+		t.Run(c.expectedRule, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Filters: dsl.Filters{c.filter}}}
+			err := runExecutableErr(t, &q, &defaultConf)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, c.expectedRule, verr.Rule)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package e2e_search_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"e2e/ent/entx"
+
+	"github.com/brice-74/entx/search/graphql"
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphQLResolver(t *testing.T) *graphql.Resolver {
+	t.Helper()
+	r, err := entx.NewGraphQLResolver(client, &defaultConf)
+	require.NoError(t, err)
+	return r
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	r := newGraphQLResolver(t)
+	tests := []struct {
+		name   string
+		query  string
+		vars   map[string]any
+		expect string
+	}{
+		{
+			name: "FilterSortAndFields",
+			query: `{
+				users(filter: {age: {gte: 40}, is_active: {eq: true}}, sort: [{field: age, direction: DESC}]) {
+					data { id name is_active }
+				}
+			}`,
+			expect: `{"users":{"data":[{"id":5,"name":"User Five","is_active":true}]}}`,
+		},
+		{
+			name: "NestedEdgesAndAliases",
+			query: `{
+				articles(filter: {id: {in: [1, 3]}}, sort: [{field: id}]) {
+					data {
+						title
+						writer: author { name }
+						tags(sort: [{field: name, direction: DESC}]) { name }
+						comments { id }
+					}
+				}
+			}`,
+			expect: `{"articles":{"data":[
+				{"title":"Go Concurrency Patterns","writer":{"name":"User One"},"tags":[{"name":"Go"}],"comments":[{"id":1}]},
+				{"title":"Docker for Developers","writer":{"name":"User Three"},"tags":[{"name":"Go"},{"name":"DevOps"}],"comments":[]}
+			]}}`,
+		},
+		{
+			name: "RelationFilters",
+			query: `{
+				withComments: articles(filter: {has_comments: true}, sort: [{field: id}]) { data { id } }
+				goOnly: articles(filter: {tags_all: {name: {eq: "Go"}}}, sort: [{field: id}]) { data { id } }
+				byAuthor: articles(filter: {author: {name: {starts_with: "User Th"}}}) { data { id } }
+			}`,
+			expect: `{
+				"withComments":{"data":[{"id":1},{"id":2}]},
+				"goOnly":{"data":[{"id":1}]},
+				"byAuthor":{"data":[{"id":3}]}
+			}`,
+		},
+		{
+			name: "LogicalFilters",
+			query: `{
+				users(filter: {or: [{age: {lt: 25}}, {not: {age: {lte: 50}}}]}, sort: [{field: id}]) { data { id } }
+			}`,
+			expect: `{"users":{"data":[{"id":1},{"id":5}]}}`,
+		},
+		{
+			name: "Aggregates",
+			query: `{
+				articles(sort: [{field: id}], limit: 2) {
+					data {
+						id
+						comments_count: aggregate(type: count, field: "comments")
+					}
+				}
+			}`,
+			expect: `{"articles":{"data":[{"id":1,"comments_count":1},{"id":2,"comments_count":2}]}}`,
+		},
+		{
+			name: "Variables",
+			query: `query Users($min: Int!, $limit: Int) {
+				users(filter: {age: {gt: $min}}, sort: [{field: id}], limit: $limit) { data { id } }
+			}`,
+			vars:   map[string]any{"min": float64(30), "limit": float64(2)},
+			expect: `{"users":{"data":[{"id":3},{"id":4}]}}`,
+		},
+		{
+			name: "Paginate",
+			query: `{
+				users(sort: [{field: id}], limit: 2, page: 2) {
+					__typename
+					data { id }
+					paginate { total current_page last_page }
+				}
+			}`,
+			expect: `{"users":{"__typename":"UserList","data":[{"id":3},{"id":4}],"paginate":{"total":5,"current_page":2,"last_page":3}}}`,
+		},
+		{
+			name: "FragmentsAndDirectives",
+			query: `query ($withEmail: Boolean!) {
+				users(filter: {id: {eq: 2}}) { data { ...UserName email @include(if: $withEmail) age @skip(if: true) } }
+			}
+			fragment UserName on User { __typename name }`,
+			vars:   map[string]any{"withEmail": true},
+			expect: `{"users":{"data":[{"__typename":"User","name":"User Two","email":"user2@example.com"}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := r.Execute(context.Background(), &graphql.Request{Query: tt.query, Variables: tt.vars})
+			require.Empty(t, res.Errors)
+			data, err := json.Marshal(res.Data)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expect, string(data))
+		})
+	}
+}
+
+func TestGraphQLFieldOrder(t *testing.T) {
+	r := newGraphQLResolver(t)
+	res := r.Execute(context.Background(), &graphql.Request{Query: `{ users(filter: {id: {eq: 1}}) { data { name id } } }`})
+	require.Empty(t, res.Errors)
+	data, err := json.Marshal(res.Data)
+	require.NoError(t, err)
+	require.Equal(t, `{"users":{"data":[{"name":"User One","id":1}]}}`, string(data))
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	r := newGraphQLResolver(t)
+	tests := []struct {
+		name  string
+		query string
+		code  string
+		rule  string
+	}{
+		{"InvalidDocument", `{ users { data { unknown } } }`, "", ""},
+		{"Mutation", `mutation { users { data { id } } }`, "", ""},
+		{"Introspection", `{ __schema { types { name } } }`, "", ""},
+		{"RelationSelectedTwice", `{ articles { data { tags { id } other: tags { name } } } }`, graphql.CodeValidation, "GraphQLRelationSelected"},
+		{"SearchValidation", `{ users(filter: {age: {between: [1]}}) { data { id } } }`, graphql.CodeValidation, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := r.Execute(context.Background(), &graphql.Request{Query: tt.query})
+			require.NotEmpty(t, res.Errors)
+			if tt.code != "" {
+				require.Equal(t, tt.code, res.Errors[0].Extensions["code"])
+			}
+			if tt.rule != "" {
+				require.Equal(t, tt.rule, res.Errors[0].Extensions["rule"])
+			}
+		})
+	}
+}
+
+func TestGraphQLPartialErrors(t *testing.T) {
+	r := newGraphQLResolver(t)
+	res := r.Execute(context.Background(), &graphql.Request{Query: `{
+		tags(filter: {id: {eq: 1}}) { data { name } }
+		articles { data { tags { id } again: tags { id } } }
+	}`})
+	require.Len(t, res.Errors, 1)
+	require.Equal(t, "articles", res.Errors[0].Path.String())
+	data, err := json.Marshal(res.Data)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"tags":{"data":[{"name":"Go"}]},"articles":null}`, string(data))
+}
+
+func TestGraphQLServeHTTP(t *testing.T) {
+	r := newGraphQLResolver(t)
+	query := `query ($id: Int!) { tags(filter: {id: {eq: $id}}) { data { name } } }`
+
+	serve := func(req *http.Request) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var res map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res
+	}
+
+	body, err := json.Marshal(graphql.Request{Query: query, Variables: map[string]any{"id": 2}})
+	require.NoError(t, err)
+	code, res := serve(httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, code, res)
+	require.Equal(t, map[string]any{"tags": map[string]any{"data": []any{map[string]any{"name": "SQL"}}}}, res["data"])
+
+	params := url.Values{"query": {query}, "variables": {`{"id":3}`}}
+	code, res = serve(httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil))
+	require.Equal(t, http.StatusOK, code, res)
+	require.Equal(t, map[string]any{"tags": map[string]any{"data": []any{map[string]any{"name": "DevOps"}}}}, res["data"])
+
+	code, res = serve(httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{`)))
+	require.Equal(t, http.StatusBadRequest, code)
+	require.NotEmpty(t, res["errors"])
+
+	code, _ = serve(httptest.NewRequest(http.MethodDelete, "/graphql", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+}
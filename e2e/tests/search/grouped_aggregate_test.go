@@ -0,0 +1,182 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupedAggregatePanicPreprocessed(t *testing.T) {
+	require.Panics(t, func() { (&dsl.GroupedAggregate{}).Build(context.Background(), nil, "") })
+}
+
+func TestGroupedAggregateValidationErrors(t *testing.T) {
+	count := []*dsl.BaseAggregate{{Type: dsl.AggCount}}
+	tests := []struct {
+		expectedRule string
+		aggregate    *dsl.GroupedAggregate
+		cfg          *search.Config
+	}{
+		{"GroupedAggregateFromEmpty", &dsl.GroupedAggregate{GroupBy: []string{"age"}, Aggregates: count}, nil},
+		{"GroupByEmpty", &dsl.GroupedAggregate{From: "User", Aggregates: count}, nil},
+		{"GroupByFieldSyntax", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"department..name"}, Aggregates: count}, nil},
+		{"GroupByDuplicate", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age", "age"}, Aggregates: count}, nil},
+		{"GroupedAggregatesEmpty", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}}, nil},
+		{"GroupedAggregateFilters", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Filters: dsl.Filters{{}}}}}, nil},
+		{"GroupedAggregateAliasDuplicate", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Alias: "n"}, {Type: dsl.AggSum, Field: "age", Alias: "n"}}}, nil},
+		{"HavingUnknownAggregate", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: count, Having: []*dsl.Having{{Aggregate: "total", Operator: dsl.OpEqual, Value: 1}}}, nil},
+		{"HavingOperator", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: count, Having: []*dsl.Having{{Aggregate: "count_", Operator: dsl.OpLike, Value: 1}}}, nil},
+		{"HavingValue", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: count, Having: []*dsl.Having{{Aggregate: "count_", Operator: dsl.OpEqual, Value: "1"}}}, nil},
+		{"BucketSortUnknownField", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: count, Sorts: []*dsl.BucketSort{{Field: "name"}}}, nil},
+		{"SortDirection", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: count, Sorts: []*dsl.BucketSort{{Field: "age", Direction: "UP"}}}, nil},
+		{"MaxBuckets", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: count, Limit: 10}, newConfig(common.WithMaxBuckets(5))},
+		{"AggregateFieldType", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggSum, Field: "name"}}}, nil},
+		{"AggregateDialect", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"is_active"}, Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggPercentile, Field: "age", AggParams: dsl.AggParams{Percentile: 0.9}}}}, newConfig(common.WithDialect(dialect.SQLite))},
+		{"HavingAggregateType", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"age"}, Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggMax, Field: "name", Alias: "last"}}, Having: []*dsl.Having{{Aggregate: "last", Operator: dsl.OpGreaterThan, Value: 1}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expectedRule, func(t *testing.T) {
+			if tt.cfg == nil {
+				tt.cfg = &defaultConf
+			}
+			err := runExecutableErr(t, search.GroupedAggregates{tt.aggregate}, tt.cfg)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.expectedRule, verr.Rule)
+		})
+	}
+}
+
+func TestGroupedAggregateBuildErr(t *testing.T) {
+	count := []*dsl.BaseAggregate{{Type: dsl.AggCount}}
+	cases := []struct {
+		name      string
+		aggregate *dsl.GroupedAggregate
+	}{
+		{"ErrNodeNotExist", &dsl.GroupedAggregate{From: "Unknown", GroupBy: []string{"id"}, Aggregates: count}},
+		{"ErrNodeNotHaveField", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"unknown"}, Aggregates: count}},
+		{"ErrGroupByRelation", &dsl.GroupedAggregate{From: "User", GroupBy: []string{"articles.title"}, Aggregates: count}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := runExecutableErr(t, search.GroupedAggregates{c.aggregate}, &defaultConf)
+			var qerr *search.QueryBuildError
+			require.ErrorAs(t, err, &qerr)
+		})
+	}
+}
+
+func TestGroupedAggregateExecution(t *testing.T) {
+	tests := []struct {
+		name      string
+		aggregate *dsl.GroupedAggregate
+		expected  []*search.Bucket
+	}{
+		{
+			name: "CountPerForeignKey",
+			aggregate: &dsl.GroupedAggregate{
+				From:       "Comment",
+				GroupBy:    []string{"article_id"},
+				Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Alias: "comments"}},
+				Sorts:      []*dsl.BucketSort{{Field: "article_id"}},
+			},
+			expected: []*search.Bucket{
+				{Keys: map[string]any{"article_id": int64(1)}, Values: map[string]any{"comments": int64(1)}},
+				{Keys: map[string]any{"article_id": int64(2)}, Values: map[string]any{"comments": int64(2)}},
+			},
+		},
+		{
+			name: "SeveralAggregates",
+			aggregate: &dsl.GroupedAggregate{
+				From:    "User",
+				GroupBy: []string{"is_active"},
+				Aggregates: []*dsl.BaseAggregate{
+					{Type: dsl.AggCount, Alias: "users"},
+					{Type: dsl.AggSum, Field: "age"},
+					{Type: dsl.AggMin, Field: "age"},
+				},
+				Sorts: []*dsl.BucketSort{{Field: "users", Direction: dsl.DirDESC}},
+			},
+			expected: []*search.Bucket{
+				{Keys: map[string]any{"is_active": true}, Values: map[string]any{"users": int64(3), "sum_age": float64(110), "min_age": int64(20)}},
+				{Keys: map[string]any{"is_active": false}, Values: map[string]any{"users": int64(2), "sum_age": float64(90), "min_age": int64(40)}},
+			},
+		},
+		{
+			name: "ThroughRelation",
+			aggregate: &dsl.GroupedAggregate{
+				From:       "Employee",
+				GroupBy:    []string{"department.name"},
+				Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Alias: "employees"}},
+				Sorts:      []*dsl.BucketSort{{Field: "department.name"}},
+			},
+			expected: []*search.Bucket{
+				{Keys: map[string]any{"department.name": "DG"}, Values: map[string]any{"employees": int64(1)}},
+				{Keys: map[string]any{"department.name": "DRH"}, Values: map[string]any{"employees": int64(1)}},
+				{Keys: map[string]any{"department.name": "DSI"}, Values: map[string]any{"employees": int64(3)}},
+			},
+		},
+		{
+			name: "HavingSortAndLimit",
+			aggregate: &dsl.GroupedAggregate{
+				From:       "Employee",
+				GroupBy:    []string{"department.id", "department.name"},
+				Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Alias: "employees"}},
+				Having:     []*dsl.Having{{Aggregate: "employees", Operator: dsl.OpLessThan, Value: 3}},
+				Sorts:      []*dsl.BucketSort{{Field: "department.id", Direction: dsl.DirDESC}},
+				Limit:      1,
+			},
+			expected: []*search.Bucket{
+				{Keys: map[string]any{"department.id": int64(2), "department.name": "DRH"}, Values: map[string]any{"employees": int64(1)}},
+			},
+		},
+		{
+			name: "NestedChainWithFilters",
+			aggregate: &dsl.GroupedAggregate{
+				From:       "Comment",
+				GroupBy:    []string{"article.author.name"},
+				Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Alias: "comments"}},
+				Filters:    dsl.Filters{{Field: "user_id", Operator: dsl.OpNotEqual, Value: 3}},
+			},
+			expected: []*search.Bucket{
+				{Keys: map[string]any{"article.author.name": "User One"}, Values: map[string]any{"comments": int64(2)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := runExecutable(t, search.GroupedAggregates{tt.aggregate}, &defaultConf)
+			require.Equal(t, tt.expected, res[tt.aggregate.Alias])
+		})
+	}
+}
+
+func TestGroupedAggregateInQueryGroup(t *testing.T) {
+	group := &search.QueryGroup{
+		Searches:   search.NamedQueries{{Key: "users", TargetedQuery: search.TargetedQuery{From: "User"}}},
+		Aggregates: search.OverallAggregates{{BaseAggregate: dsl.BaseAggregate{Field: "User", Type: dsl.AggCount, Alias: "total"}}},
+		Grouped: search.GroupedAggregates{{
+			From:       "Comment",
+			GroupBy:    []string{"article_id"},
+			Aggregates: []*dsl.BaseAggregate{{Type: dsl.AggCount, Alias: "comments"}},
+		}},
+	}
+	res := runExecutable(t, group, &defaultConf)
+	require.Len(t, res.Searches, 1)
+	require.Equal(t, int64(5), res.Meta.Aggregates["total"])
+	require.Len(t, res.Meta.Aggregates["comment_by_article_id"], 2)
+
+	tx := &search.TxQueryGroup{QueryGroup: search.QueryGroup{
+		Aggregates: group.Aggregates,
+		Grouped:    group.Grouped,
+	}}
+	txRes := runExecutable(t, tx, &defaultConf)
+	require.Equal(t, int64(5), txRes.Meta.Aggregates["total"])
+	require.Len(t, txRes.Meta.Aggregates["comment_by_article_id"], 2)
+}
@@ -0,0 +1,184 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"e2e/ent/entx"
+
+	"entgo.io/ent/dialect"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateHistogramPanicPreprocessed(t *testing.T) {
+	require.Panics(t, func() { (&dsl.DateHistogram{}).Build(context.Background(), nil, "") })
+}
+
+func TestDateHistogramValidationErrors(t *testing.T) {
+	count := dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article", Type: dsl.AggCount}}
+	tests := []struct {
+		expectedRule string
+		histogram    *dsl.DateHistogram
+	}{
+		{"OverallAggregateFieldFormat", &dsl.DateHistogram{OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article.author.id", Type: dsl.AggCount}}, On: "created_at", Interval: dsl.IntervalDay}},
+		{"HistogramFieldEmpty", &dsl.DateHistogram{OverallAggregate: count, Interval: dsl.IntervalDay}},
+		{"HistogramInterval", &dsl.DateHistogram{OverallAggregate: count, On: "created_at", Interval: "quarter"}},
+		{"HistogramTimezone", &dsl.DateHistogram{OverallAggregate: count, On: "created_at", Interval: dsl.IntervalDay, Timezone: "Mars/Olympus"}},
+		{"HistogramTimezone", &dsl.DateHistogram{OverallAggregate: count, On: "created_at", Interval: dsl.IntervalDay, Timezone: "+25:00"}},
+		{"HistogramFieldType", &dsl.DateHistogram{OverallAggregate: count, On: "title", Interval: dsl.IntervalDay}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expectedRule, func(t *testing.T) {
+			err := runExecutableErr(t, search.DateHistograms{tt.histogram}, &defaultConf)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.expectedRule, verr.Rule)
+		})
+	}
+}
+
+func TestDateHistogramBuildErr(t *testing.T) {
+	count := dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article", Type: dsl.AggCount}}
+	cases := []struct {
+		name      string
+		histogram *dsl.DateHistogram
+		cfg       *search.Config
+	}{
+		{"ErrNodeNotHaveField", &dsl.DateHistogram{OverallAggregate: count, On: "published_at", Interval: dsl.IntervalDay}, &defaultConf},
+		{"ErrHistogramTimezoneDialect", &dsl.DateHistogram{OverallAggregate: count, On: "created_at", Interval: dsl.IntervalDay, Timezone: "Europe/Paris"}, common.NewConfig(common.WithDialect(dialect.SQLite))},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := search.DateHistograms{c.histogram}
+			require.NoError(t, q.ValidateAndPreprocessFinal(c.cfg))
+			_, err := q.BuildBuckets(context.Background(), entx.Graph, c.cfg.Dialect)
+			var qerr *search.QueryBuildError
+			require.ErrorAs(t, err, &qerr)
+		})
+	}
+}
+
+func TestDateHistogramExecution(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	type bucket struct {
+		start time.Time
+		value any
+	}
+	tests := []struct {
+		name      string
+		histogram *dsl.DateHistogram
+		cfg       *search.Config
+		expected  []bucket
+	}{
+		{
+			name: "WeeklyFilled",
+			histogram: &dsl.DateHistogram{
+				OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article", Type: dsl.AggCount, Alias: "articles"}},
+				On:               "created_at",
+				Interval:         dsl.IntervalWeek,
+				FillEmpty:        true,
+			},
+			expected: []bucket{
+				{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), int64(2)},
+				{time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), int64(0)},
+				{time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), int64(1)},
+			},
+		},
+		{
+			name: "DailyInNamedTimezone",
+			histogram: &dsl.DateHistogram{
+				OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article", Type: dsl.AggCount, Alias: "articles"}},
+				On:               "created_at",
+				Interval:         dsl.IntervalDay,
+				Timezone:         "Europe/Paris",
+			},
+			expected: []bucket{
+				{time.Date(2024, 1, 1, 0, 0, 0, 0, paris), int64(1)},
+				{time.Date(2024, 1, 4, 0, 0, 0, 0, paris), int64(1)},
+				{time.Date(2024, 1, 17, 0, 0, 0, 0, paris), int64(1)},
+			},
+		},
+		{
+			name: "DailyInFixedOffsetWithFilters",
+			histogram: &dsl.DateHistogram{
+				OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{
+					Field:   "Article",
+					Type:    dsl.AggCount,
+					Alias:   "articles",
+					Filters: dsl.Filters{{Field: "user_id", Operator: dsl.OpEqual, Value: 1}},
+				}},
+				On:       "created_at",
+				Interval: dsl.IntervalDay,
+				Timezone: "-05:00",
+			},
+			expected: []bucket{
+				{time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("", -5*3600)), int64(1)},
+				{time.Date(2024, 1, 3, 0, 0, 0, 0, time.FixedZone("", -5*3600)), int64(1)},
+			},
+		},
+		{
+			name: "MonthlySum",
+			histogram: &dsl.DateHistogram{
+				OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article.id", Type: dsl.AggSum, Alias: "ids"}},
+				On:               "created_at",
+				Interval:         dsl.IntervalMonth,
+			},
+			expected: []bucket{
+				{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), float64(6)},
+			},
+		},
+		{
+			name: "HourlyFilledUpToMaxBuckets",
+			histogram: &dsl.DateHistogram{
+				OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article.id", Type: dsl.AggMax, Alias: "ids"}},
+				On:               "created_at",
+				Interval:         dsl.IntervalHour,
+				FillEmpty:        true,
+			},
+			cfg: newConfig(common.WithMaxBuckets(3)),
+			expected: []bucket{
+				{time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), int64(1)},
+				{time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), nil},
+				{time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.histogram.Timezone == "Europe/Paris" && defaultConf.Dialect == dialect.SQLite {
+				t.Skip("named timezones are not supported by SQLite")
+			}
+			if tt.cfg == nil {
+				tt.cfg = &defaultConf
+			}
+			res := runExecutable(t, search.DateHistograms{tt.histogram}, tt.cfg)
+			buckets, ok := res[tt.histogram.Alias].([]*search.Bucket)
+			require.True(t, ok)
+			require.Len(t, buckets, len(tt.expected))
+			for i, b := range buckets {
+				start, ok := b.Keys["created_at"].(time.Time)
+				require.True(t, ok)
+				require.True(t, tt.expected[i].start.Equal(start), "bucket %d starts at %s, expected %s", i, start, tt.expected[i].start)
+				require.Equal(t, tt.expected[i].value, b.Values[tt.histogram.Alias])
+			}
+		})
+	}
+}
+
+func TestDateHistogramInQueryGroup(t *testing.T) {
+	group := &search.QueryGroup{
+		Histograms: search.DateHistograms{{
+			OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article", Type: dsl.AggCount}},
+			On:               "created_at",
+			Interval:         dsl.IntervalYear,
+		}},
+	}
+	res := runExecutable(t, group, &defaultConf)
+	require.Len(t, res.Meta.Aggregates["count_Article_per_year"], 1)
+}
@@ -0,0 +1,210 @@
+package e2e_search_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"e2e/ent/entx"
+
+	"entgo.io/ent/privacy"
+	"github.com/brice-74/entx/search/httpapi"
+	"github.com/stretchr/testify/require"
+)
+
+type viewerKey struct{}
+
+func serveAPI(t *testing.T, h http.Handler, method, path, body string) (int, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	return rec.Code, res
+}
+
+func TestHTTPAPIEndpoints(t *testing.T) {
+	h := httpapi.New(client, entx.Graph, &defaultConf)
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		expect func(t *testing.T, res map[string]any)
+	}{
+		{
+			name: "Search",
+			path: httpapi.PathSearch,
+			body: `{"from":"User","filters":[{"field":"age","operator":">=","value":40}]}`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Len(t, res["data"], 3)
+			},
+		},
+		{
+			name: "Searches",
+			path: httpapi.PathSearches,
+			body: `[{"key":"users","from":"User"},{"key":"articles","from":"Article"}]`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Contains(t, res, "users")
+				require.Contains(t, res, "articles")
+			},
+		},
+		{
+			name: "Group",
+			path: httpapi.PathGroup,
+			body: `{"searches":[{"key":"users","from":"User"}],"aggregates":[{"field":"User","type":"count","alias":"total"}]}`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Equal(t, float64(5), res["meta"].(map[string]any)["aggregates"].(map[string]any)["total"])
+			},
+		},
+		{
+			name: "Bundle",
+			path: httpapi.PathBundle,
+			body: `{"transactions":[{"aggregates":[{"field":"Article","type":"count","alias":"articles"},{"field":"User","type":"count","alias":"users"}]}]}`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Equal(t, float64(3), res["meta"].(map[string]any)["aggregates"].(map[string]any)["articles"])
+			},
+		},
+		{
+			name: "Transaction",
+			path: httpapi.PathTransaction,
+			body: `{"aggregates":[{"field":"Article","type":"count","alias":"articles"}]}`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Equal(t, float64(3), res["meta"].(map[string]any)["aggregates"].(map[string]any)["articles"])
+			},
+		},
+		{
+			name: "Aggregates",
+			path: httpapi.PathAggregates,
+			body: `[{"field":"User.age","type":"max","alias":"oldest"}]`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Equal(t, float64(60), res["oldest"])
+			},
+		},
+		{
+			name: "Grouped",
+			path: httpapi.PathGrouped,
+			body: `[{"from":"Comment","group_by":["article_id"],"aggregates":[{"type":"count"}],"alias":"comments"}]`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Len(t, res["comments"], 2)
+			},
+		},
+		{
+			name: "Histograms",
+			path: httpapi.PathHistograms,
+			body: `[{"field":"Article","type":"count","on":"created_at","interval":"year","alias":"articles"}]`,
+			expect: func(t *testing.T, res map[string]any) {
+				require.Len(t, res["articles"], 1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, res := serveAPI(t, h, http.MethodPost, tt.path, tt.body)
+			require.Equal(t, http.StatusOK, code, res)
+			tt.expect(t, res)
+		})
+	}
+}
+
+func TestHTTPAPIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		opts    []httpapi.Option
+		status  int
+		errType string
+		field   string
+		value   string
+	}{
+		{"UnknownEndpoint", http.MethodPost, "/unknown", `{}`, nil, http.StatusNotFound, httpapi.TypeRouting, "", ""},
+		{"MethodNotAllowed", http.MethodGet, httpapi.PathSearch, ``, nil, http.StatusMethodNotAllowed, httpapi.TypeRouting, "", ""},
+		{"EmptyBody", http.MethodPost, httpapi.PathSearch, ``, nil, http.StatusBadRequest, httpapi.TypeDecode, "", ""},
+		{"UnknownField", http.MethodPost, httpapi.PathSearch, `{"from":"User","where":{}}`, nil, http.StatusBadRequest, httpapi.TypeDecode, "", ""},
+		{"TrailingValue", http.MethodPost, httpapi.PathSearch, `{"from":"User"} {}`, nil, http.StatusBadRequest, httpapi.TypeDecode, "", ""},
+		{"BodyTooLarge", http.MethodPost, httpapi.PathSearch, `{"from":"User"}`, []httpapi.Option{httpapi.WithMaxBodySize(8)}, http.StatusRequestEntityTooLarge, httpapi.TypeDecode, "", ""},
+		{"Validation", http.MethodPost, httpapi.PathSearch, `{"from":"Unknown"}`, nil, http.StatusBadRequest, httpapi.TypeValidation, "rule", "UnknowRootNode"},
+		{"Build", http.MethodPost, httpapi.PathGrouped, `[{"from":"Unknown","group_by":["id"],"aggregates":[{"type":"count"}]}]`, nil, http.StatusUnprocessableEntity, httpapi.TypeBuild, "op", "GroupedAggregate.Build"},
+		{
+			name: "Unauthenticated", method: http.MethodPost, path: httpapi.PathSearch, body: `{"from":"User"}`,
+			opts:   []httpapi.Option{httpapi.WithAuthenticator(func(*http.Request) error { return errors.New("missing token") })},
+			status: http.StatusUnauthorized, errType: httpapi.TypeAuth, field: "message", value: "missing token",
+		},
+		{
+			name: "CustomHookError", method: http.MethodPost, path: httpapi.PathSearch, body: `{"from":"User"}`,
+			opts: []httpapi.Option{httpapi.WithAuthenticator(func(*http.Request) error {
+				return &httpapi.Error{Status: http.StatusTooManyRequests, Type: "rate_limit", Message: "slow down"}
+			})},
+			status: http.StatusTooManyRequests, errType: "rate_limit", field: "message", value: "slow down",
+		},
+		{
+			name: "ContextDenied", method: http.MethodPost, path: httpapi.PathSearch, body: `{"from":"User"}`,
+			opts: []httpapi.Option{httpapi.WithContext(func(ctx context.Context, _ *http.Request) (context.Context, error) {
+				return ctx, privacy.Deny
+			})},
+			status: http.StatusForbidden, errType: httpapi.TypeAuth,
+		},
+		{
+			name: "Canceled", method: http.MethodPost, path: httpapi.PathSearch, body: `{"from":"User"}`,
+			opts: []httpapi.Option{httpapi.WithContext(func(ctx context.Context, _ *http.Request) (context.Context, error) {
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				return ctx, nil
+			})},
+			status: httpapi.StatusClientClosedRequest, errType: httpapi.TypeCanceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := httpapi.New(client, entx.Graph, &defaultConf, tt.opts...)
+			code, res := serveAPI(t, h, tt.method, tt.path, tt.body)
+			require.Equal(t, tt.status, code, res)
+			body, ok := res["error"].(map[string]any)
+			require.True(t, ok)
+			require.Equal(t, float64(tt.status), body["status"])
+			require.Equal(t, tt.errType, body["type"])
+			if tt.field != "" {
+				require.Equal(t, tt.value, body[tt.field])
+			}
+		})
+	}
+}
+
+func TestHTTPAPIHooks(t *testing.T) {
+	var (
+		viewer any
+		hooked *httpapi.Error
+	)
+	h := httpapi.New(client, entx.Graph, &defaultConf,
+		httpapi.WithAuthenticator(func(r *http.Request) error {
+			if r.Header.Get("Authorization") == "" {
+				return errors.New("missing token")
+			}
+			return nil
+		}),
+		httpapi.WithContext(func(ctx context.Context, r *http.Request) (context.Context, error) {
+			viewer = r.Header.Get("Authorization")
+			return context.WithValue(ctx, viewerKey{}, viewer), nil
+		}),
+		httpapi.WithErrorHook(func(_ *http.Request, e *httpapi.Error) { hooked = e }),
+	)
+
+	code, _ := serveAPI(t, h, http.MethodPost, httpapi.PathSearch, `{"from":"User"}`)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.NotNil(t, hooked)
+	require.EqualError(t, hooked.Err, "missing token")
+	require.Nil(t, viewer)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, httpapi.PathSearch, strings.NewReader(`{"from":"User"}`))
+	req.Header.Set("Authorization", "viewer-1")
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "viewer-1", viewer)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 
+	"e2e/ent"
+
 	"github.com/brice-74/entx/search"
 	"github.com/brice-74/entx/search/common"
 	"github.com/brice-74/entx/search/dsl"
@@ -19,7 +21,7 @@ func TestIncludeBuildErr(t *testing.T) {
 	t.Run("ErrBridgeNotFound", func(t *testing.T) {
 		q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Includes: dsl.Includes{{Relation: "unknow"}}}}
 
-		err := runExecutableErr(t, &q, &common.DefaultConf)
+		err := runExecutableErr(t, &q, &defaultConf)
 		expectedErr := &common.QueryBuildError{Op: "Include.PredicateQ", Err: fmt.Errorf(dsl.ErrBridgeNotFound, "unknow", "User")}
 		require.EqualError(t, err, expectedErr.Error())
 	})
@@ -32,15 +34,16 @@ func TestIncludeValidation(t *testing.T) {
 		cfg          *search.Config
 	}{
 		{"InvalidIncludeRelationFormat", dsl.Includes{{Relation: ".."}}, nil},
-		{"MaxIncludeRelationsDepth", dsl.Includes{{Relation: "a.b"}}, common.NewConfig(common.WithIncludeConfig(common.IncludeConfig{MaxIncludeRelationDepth: 1}))},
-		{"MaxIncludeRelationsDepth", dsl.Includes{{Relation: "a", Includes: dsl.Includes{{Relation: "b"}}}}, common.NewConfig(common.WithIncludeConfig(common.IncludeConfig{MaxIncludeRelationDepth: 1}))},
-		{"MaxIncludeTreeCount", dsl.Includes{{Relation: "a.b"}, {Relation: "a"}}, common.NewConfig(common.WithIncludeConfig(common.IncludeConfig{MaxIncludeTreeCount: 2}))},
+		{"MaxIncludeRelationsDepth", dsl.Includes{{Relation: "a.b"}}, newConfig(common.WithIncludeConfig(common.IncludeConfig{MaxIncludeRelationDepth: 1}))},
+		{"MaxIncludeRelationsDepth", dsl.Includes{{Relation: "a", Includes: dsl.Includes{{Relation: "b"}}}}, newConfig(common.WithIncludeConfig(common.IncludeConfig{MaxIncludeRelationDepth: 1}))},
+		{"MaxIncludeTreeCount", dsl.Includes{{Relation: "a.b"}, {Relation: "a"}}, newConfig(common.WithIncludeConfig(common.IncludeConfig{MaxIncludeTreeCount: 2}))},
+		{"IncludeOffset", dsl.Includes{{Relation: "articles", Offset: -1}}, nil},
 	}
 	for _, c := range cases {
 		t.Run(c.expectedRule, func(t *testing.T) {
 			q := search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{Includes: c.includes}}
 			if c.cfg == nil {
-				c.cfg = &common.DefaultConf
+				c.cfg = &defaultConf
 			}
 			err := runExecutableErr(t, &q, c.cfg)
 			var verr *search.ValidationError
@@ -49,3 +52,84 @@ func TestIncludeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestIncludePerParentLimit(t *testing.T) {
+	articleIDs := func(articles []*ent.Article) []int {
+		ids := make([]int, len(articles))
+		for i, a := range articles {
+			ids[i] = a.ID
+		}
+		return ids
+	}
+	usersQuery := func(inc *dsl.Include) *search.TargetedQuery {
+		return &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{
+				Filters:  dsl.Filters{{Field: "id", Operator: "in", Value: []any{1, 3}}},
+				Sorts:    dsl.Sorts{{Field: "id"}},
+				Includes: dsl.Includes{inc},
+			},
+		}
+	}
+
+	t.Run("O2M", func(t *testing.T) {
+		users := runTargetedQuery[*ent.User](t, usersQuery(&dsl.Include{Relation: "articles", Limit: dsl.Limit{Limit: 1}}), &defaultConf)
+		require.Len(t, users, 2)
+		require.Equal(t, []int{1}, articleIDs(users[0].Edges.Articles))
+		require.Equal(t, []int{3}, articleIDs(users[1].Edges.Articles))
+	})
+
+	t.Run("Sorted", func(t *testing.T) {
+		users := runTargetedQuery[*ent.User](t, usersQuery(&dsl.Include{
+			Relation: "articles",
+			Sort:     dsl.Sorts{{Field: "title", Direction: dsl.DirDESC}},
+			Limit:    dsl.Limit{Limit: 1},
+		}), &defaultConf)
+		require.Equal(t, []int{2}, articleIDs(users[0].Edges.Articles))
+		require.Equal(t, []int{3}, articleIDs(users[1].Edges.Articles))
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		users := runTargetedQuery[*ent.User](t, usersQuery(&dsl.Include{Relation: "articles", Limit: dsl.Limit{Limit: 1}, Offset: 1}), &defaultConf)
+		require.Equal(t, []int{2}, articleIDs(users[0].Edges.Articles))
+		require.Empty(t, users[1].Edges.Articles)
+	})
+
+	t.Run("M2M", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "in", Value: []any{1, 2, 3}}},
+				Sorts:   dsl.Sorts{{Field: "id"}},
+				Includes: dsl.Includes{{
+					Relation: "articles",
+					Sort:     dsl.Sorts{{Field: "id", Direction: dsl.DirDESC}},
+					Limit:    dsl.Limit{Limit: 1},
+				}},
+			},
+		}
+		tags := runTargetedQuery[*ent.Tag](t, q, &defaultConf)
+		require.Len(t, tags, 3)
+		require.Equal(t, []int{3}, articleIDs(tags[0].Edges.Articles))
+		require.Equal(t, []int{2}, articleIDs(tags[1].Edges.Articles))
+		require.Equal(t, []int{3}, articleIDs(tags[2].Edges.Articles))
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		users := runTargetedQuery[*ent.User](t, usersQuery(&dsl.Include{
+			Relation: "articles",
+			Limit:    dsl.Limit{Limit: 2},
+			Includes: dsl.Includes{{
+				Relation: "comments",
+				Sort:     dsl.Sorts{{Field: "id", Direction: dsl.DirDESC}},
+				Limit:    dsl.Limit{Limit: 1},
+			}},
+		}), &defaultConf)
+		articles := users[0].Edges.Articles
+		require.Equal(t, []int{1, 2}, articleIDs(articles))
+		require.Len(t, articles[0].Edges.Comments, 1)
+		require.Equal(t, 1, articles[0].Edges.Comments[0].ID)
+		require.Len(t, articles[1].Edges.Comments, 1)
+		require.Equal(t, 3, articles[1].Edges.Comments[0].ID)
+	})
+}
@@ -0,0 +1,130 @@
+package e2e_search_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"e2e/ent/entx"
+
+	"github.com/brice-74/entx/search/httpapi"
+	"github.com/brice-74/entx/search/jsonschema"
+	validator "github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/require"
+)
+
+// compileDef compiles a definition of a generated document, located by its JSON pointer.
+func compileDef(t *testing.T, doc any, pointer string) *validator.Schema {
+	t.Helper()
+	raw, err := json.Marshal(doc)
+	require.NoError(t, err)
+	res, err := validator.UnmarshalJSON(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	c := validator.NewCompiler()
+	c.DefaultDraft(validator.Draft2020)
+	require.NoError(t, c.AddResource("search.json", res))
+	s, err := c.Compile("search.json#" + pointer)
+	require.NoError(t, err)
+	return s
+}
+
+func validate(t *testing.T, s *validator.Schema, input string) error {
+	t.Helper()
+	v, err := validator.UnmarshalJSON(strings.NewReader(input))
+	require.NoError(t, err)
+	return s.Validate(v)
+}
+
+func TestJSONSchemaInputs(t *testing.T) {
+	doc := jsonschema.Generate(entx.Graph)
+	tests := []struct {
+		def   string
+		input string
+		valid bool
+	}{
+		{"TargetedQuery", `{"from":"User","select":["name","age"],"filters":[{"field":"age","operator":">=","value":40}],"sorts":[{"field":"age","direction":"DESC"}],"limit":10}`, true},
+		{"TargetedQuery", `{"from":"User","select":["unknown"]}`, false},
+		{"TargetedQuery", `{"from":"User","aggregates":[{"field":"articles","type":"count","alias":"article_count"}],"sorts":[{"field":"article_count","nulls":"last"},{"field":"name","values":["a","b"]}]}`, true},
+		{"TargetedQuery", `{"from":"User","sorts":[{"field":"age","nulls":"middle"}]}`, false},
+		{"TargetedQuery", `{"from":"User","sorts":[{"field":"articles.id","aggregate":"count","filters":[{"field":"published","operator":"=","value":true}]}]}`, true},
+		{"TargetedQuery", `{"from":"User","sorts":[{"field":"age","values":[]}]}`, false},
+		{"TargetedQuery", `{"from":"Unknown"}`, false},
+		{"TargetedQuery", `{"from":"User","where":{}}`, false},
+		{"TargetedQuery", `{"from":"User","filters":[{"field":"name","operator":"~"}]}`, false},
+		{"TargetedQuery", `{"from":"User","filters":[{"field":"articles.title","operator":"like","value":"Go"}]}`, true},
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"articles","quantifier":"all","field":"published","operator":"=","value":true}]}`, true},
+		// conditions of a relation filter apply to the related node
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"articles","field":"email","operator":"=","value":"x"}]}`, false},
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"articles","and":[{"field":"title","operator":"=","value":"x"}]}]}`, true},
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"friends"}]}`, false},
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"articles","count":{"operator":">","value":1}}]}`, true},
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"articles","count":{"operator":"between","value":[1,3]}}]}`, true},
+		{"TargetedQuery", `{"from":"User","filters":[{"relation":"articles","count":{"operator":"not between","value":[1]}}]}`, false},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"comments","with_cursor":true,"after":"x"}]}`, true},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"comments","with_cursor":true,"before":"x"}]}`, false},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"comments","select":["body"],"sort":[{"field":"id"}],"limit":2,"includes":[{"relation":"user"}]}]}`, true},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"comments","select":["title"]}]}`, false},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"tags","pivot":["position"],"sort":[{"field":"pivot.position"}]}]}`, true},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"tags","pivot":["name"]}]}`, false},
+		{"TargetedQuery", `{"from":"Article","includes":[{"relation":"comments","pivot":["position"]}]}`, false},
+		{"TargetedQuery", `{"from":"Article","aggregates":[{"field":"comments","type":"count","filters":[{"field":"body","operator":"like","value":"Go"}]}]}`, true},
+		{"TargetedQuery", `{"from":"Article","aggregates":[{"field":"comments","type":"median_value"}]}`, false},
+		{"NamedQueries", `[{"key":"users","from":"User"},{"key":"articles","from":"Article","with_pagination":true,"page":2}]`, true},
+		{"QueryBundle", `{"transactions":[{"searches":[{"from":"Tag"}],"aggregates":[{"field":"User.age","type":"max","alias":"oldest"}]}],"parallel_aggregates_groups":[[{"field":"Article","type":"count"}]]}`, true},
+		{"QueryBundle", `{"transactions":[{"aggregates":[{"field":"User.unknown","type":"max"}]}]}`, false},
+		{"QueryBundle", `{"searches":[{"key":"u","from":"User"}],"histograms":[{"field":"Article","type":"count","on":"created_at","interval":"day"}]}`, true},
+		{"QueryBundle", `{"searches":[{"key":"u","from":"Unknown"}]}`, false},
+		{"GroupedAggregates", `[{"from":"Comment","group_by":["article_id"],"aggregates":[{"type":"count"}],"having":[{"aggregate":"count","operator":">","value":1}]}]`, true},
+		{"GroupedAggregates", `[{"from":"Comment","group_by":[],"aggregates":[{"type":"count"}]}]`, false},
+		{"DateHistograms", `[{"field":"Article","type":"count","on":"created_at","interval":"month"}]`, true},
+		{"DateHistograms", `[{"field":"Article","type":"count","on":"title","interval":"month"}]`, false},
+	}
+	compiled := map[string]*validator.Schema{}
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			s, ok := compiled[tt.def]
+			if !ok {
+				s = compileDef(t, doc, "/$defs/"+tt.def)
+				compiled[tt.def] = s
+			}
+			if err := validate(t, s, tt.input); tt.valid {
+				require.NoError(t, err, tt.input)
+			} else {
+				require.Error(t, err, tt.input)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaEnumeratesGraph(t *testing.T) {
+	doc := jsonschema.Generate(entx.Graph)
+	for name, node := range entx.Graph {
+		var fields []any
+		for _, f := range node.Fields() {
+			fields = append(fields, f.Name)
+		}
+		require.Equal(t, fields, doc.Defs[name+".Field"].Enum, name)
+		if len(node.Bridges()) == 0 {
+			require.NotContains(t, doc.Defs, name+".Include")
+			continue
+		}
+		require.Len(t, doc.Defs[name+".Relation"].Enum, len(node.Bridges()), name)
+	}
+}
+
+func TestJSONSchemaOpenAPI(t *testing.T) {
+	doc := httpapi.OpenAPI(entx.Graph, jsonschema.Info{Title: "search", Version: "1.0.0"})
+	require.Equal(t, jsonschema.OpenAPIVersion, doc.OpenAPI)
+	require.Len(t, doc.Paths, 8)
+
+	op := doc.Paths[httpapi.PathSearch].Post
+	require.NotNil(t, op)
+	ref := op.RequestBody.Content["application/json"].Schema.Ref
+	require.Equal(t, jsonschema.ComponentsPrefix+"TargetedQuery", ref)
+
+	s := compileDef(t, doc, strings.TrimPrefix(ref, "#"))
+	require.NoError(t, validate(t, s, `{"from":"Tag","filters":[{"relation":"articles","field":"title","operator":"=","value":"x"}]}`))
+	require.Error(t, validate(t, s, `{"from":"Tag","filters":[{"relation":"articles","field":"name","operator":"=","value":"x"}]}`))
+}
@@ -9,8 +9,6 @@ import (
 	"e2e/ent/entx"
 	_ "e2e/ent/runtime"
 	"e2e/tests"
-
-	_ "github.com/go-sql-driver/mysql"
 )
 
 var (
@@ -0,0 +1,245 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"e2e/ent"
+	"e2e/ent/article"
+	"e2e/ent/articletag"
+	"e2e/ent/entx"
+	"e2e/ent/tag"
+
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/mutate"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultMutateConf = mutate.DefaultConf
+
+func runMutation(t *testing.T, m *mutate.TargetedMutation) *mutate.SearchResponse {
+	t.Helper()
+	res, err := m.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+	require.NoError(t, err)
+	return res
+}
+
+func cleanupTags(t *testing.T, ids ...int) {
+	t.Cleanup(func() {
+		ctx := context.Background()
+		_, err := client.Client.ArticleTag.Delete().Where(articletag.TagIDIn(ids...)).Exec(ctx)
+		require.NoError(t, err)
+		_, err = client.Client.Tag.Delete().Where(tag.IDIn(ids...)).Exec(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func tagArticleIDs(t *testing.T, id int) []int {
+	t.Helper()
+	ids, err := client.Client.Tag.Query().Where(tag.ID(id)).QueryArticles().Order(ent.Asc(article.FieldID)).IDs(context.Background())
+	require.NoError(t, err)
+	return ids
+}
+
+func TestMutateLifecycle(t *testing.T) {
+	cleanupTags(t, 100)
+
+	res := runMutation(t, &mutate.TargetedMutation{
+		Node:   "Tag",
+		Op:     mutate.OpCreate,
+		Fields: map[string]any{"id": float64(100), "name": "Mutations"},
+		Edges:  map[string]*mutate.Edge{"articles": {Attach: []any{float64(1), float64(2)}}},
+	})
+	tags := entxstd.AsTypedEntities[*ent.Tag](res.Data.([]entxstd.Entity))
+	require.Len(t, tags, 1)
+	require.Equal(t, 100, tags[0].ID)
+	require.Equal(t, "Mutations", tags[0].Name)
+	require.Equal(t, 1, res.Meta.Count)
+	require.Equal(t, []int{1, 2}, tagArticleIDs(t, 100))
+
+	// the written entity is readable through the search module
+	found := runTargetedQuery[*ent.Tag](t, &search.TargetedQuery{
+		From: "Tag",
+		QueryOptions: search.QueryOptions{
+			Filters:  dsl.Filters{{Field: "name", Operator: "=", Value: "Mutations"}},
+			Includes: dsl.Includes{{Relation: "articles"}},
+		},
+	}, &defaultConf)
+	require.Len(t, found, 1)
+	require.Len(t, found[0].Edges.Articles, 2)
+
+	res = runMutation(t, &mutate.TargetedMutation{
+		Node:   "Tag",
+		Op:     mutate.OpUpdate,
+		Match:  map[string]any{"name": "Mutations"},
+		Fields: map[string]any{"name": "Updated"},
+		Edges:  map[string]*mutate.Edge{"articles": {Attach: []any{float64(3)}, Detach: []any{float64(1)}}},
+	})
+	tags = entxstd.AsTypedEntities[*ent.Tag](res.Data.([]entxstd.Entity))
+	require.Equal(t, "Updated", tags[0].Name)
+	require.Equal(t, []int{2, 3}, tagArticleIDs(t, 100))
+
+	// the relations held by a pivot table are detached before deleting
+	runMutation(t, &mutate.TargetedMutation{
+		Node:  "Tag",
+		Op:    mutate.OpUpdate,
+		Match: map[string]any{"id": float64(100)},
+		Edges: map[string]*mutate.Edge{"articles": {Detach: []any{float64(2), float64(3)}}},
+	})
+	res = runMutation(t, &mutate.TargetedMutation{
+		Node:  "Tag",
+		Op:    mutate.OpDelete,
+		Match: map[string]any{"id": float64(100)},
+	})
+	require.Equal(t, 1, res.Meta.Count)
+	exists, err := client.Client.Tag.Query().Where(tag.ID(100)).Exist(context.Background())
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestMutateUpsert(t *testing.T) {
+	cleanupTags(t, 101)
+
+	upsert := func(name string) *ent.Tag {
+		res := runMutation(t, &mutate.TargetedMutation{
+			Node:   "Tag",
+			Op:     mutate.OpUpsert,
+			Match:  map[string]any{"id": 101},
+			Fields: map[string]any{"name": name},
+		})
+		return entxstd.AsTypedEntities[*ent.Tag](res.Data.([]entxstd.Entity))[0]
+	}
+
+	created := upsert("Created")
+	require.Equal(t, 101, created.ID)
+	require.Equal(t, "Created", created.Name)
+
+	updated := upsert("Upserted")
+	require.Equal(t, 101, updated.ID)
+	require.Equal(t, "Upserted", updated.Name)
+
+	count, err := client.Client.Tag.Query().Where(tag.ID(101)).Count(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestMutateUniqueEdge(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := client.Client.Article.Delete().Where(article.ID(100)).Exec(context.Background())
+		require.NoError(t, err)
+	})
+
+	res := runMutation(t, &mutate.TargetedMutation{
+		Node:   "Article",
+		Op:     mutate.OpCreate,
+		Fields: map[string]any{"id": 100, "title": "Mutating relations", "created_at": "2024-02-01T10:00:00Z"},
+		Edges:  map[string]*mutate.Edge{"author": {Attach: []any{float64(2)}}},
+	})
+	articles := entxstd.AsTypedEntities[*ent.Article](res.Data.([]entxstd.Entity))
+	require.Equal(t, 2, articles[0].UserID)
+	require.False(t, articles[0].Published)
+	require.Equal(t, 2024, articles[0].CreatedAt.Year())
+
+	// attaching a unique relation replaces the current entity
+	res = runMutation(t, &mutate.TargetedMutation{
+		Node:   "Article",
+		Op:     mutate.OpUpdate,
+		Match:  map[string]any{"id": 100},
+		Fields: map[string]any{"content": "with an author", "published": true},
+		Edges:  map[string]*mutate.Edge{"author": {Attach: []any{float64(3)}}},
+	})
+	articles = entxstd.AsTypedEntities[*ent.Article](res.Data.([]entxstd.Entity))
+	require.Equal(t, 3, articles[0].UserID)
+	require.Equal(t, "with an author", articles[0].Content)
+	require.True(t, articles[0].Published)
+
+	// optional fields are cleared with null
+	res = runMutation(t, &mutate.TargetedMutation{
+		Node:   "Article",
+		Op:     mutate.OpUpdate,
+		Match:  map[string]any{"id": 100},
+		Fields: map[string]any{"content": nil},
+	})
+	articles = entxstd.AsTypedEntities[*ent.Article](res.Data.([]entxstd.Entity))
+	require.Empty(t, articles[0].Content)
+}
+
+func TestMutateTransaction(t *testing.T) {
+	cleanupTags(t, 102)
+
+	mutations := mutate.NamedMutations{
+		{Key: "tag", TargetedMutation: mutate.TargetedMutation{
+			Node: "Tag", Op: mutate.OpCreate, Fields: map[string]any{"id": 102, "name": "Rolled back"},
+		}},
+		{TargetedMutation: mutate.TargetedMutation{
+			Node: "Tag", Op: mutate.OpUpdate, Match: map[string]any{"id": 999}, Fields: map[string]any{"name": "Missing"},
+		}},
+	}
+	_, err := mutations.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+	var verr *mutate.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "MutationMatchNotFound", verr.Rule)
+
+	// the mutations of a request are all or nothing
+	exists, err := client.Client.Tag.Query().Where(tag.ID(102)).Exist(context.Background())
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	mutations[1].Match = map[string]any{"id": 102}
+	res, err := mutations.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+	require.NoError(t, err)
+	require.Contains(t, res, "tag")
+	require.Contains(t, res, "mutation_2")
+	tags := entxstd.AsTypedEntities[*ent.Tag](res["mutation_2"].Data.([]entxstd.Entity))
+	require.Equal(t, "Missing", tags[0].Name)
+}
+
+func TestMutateValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutation mutate.TargetedMutation
+		rule     string
+	}{
+		{"UnknownOp", mutate.TargetedMutation{Node: "Tag", Op: "replace"}, "MutationOp"},
+		{"MatchOnCreate", mutate.TargetedMutation{Node: "Tag", Op: mutate.OpCreate, Match: map[string]any{"id": 1}}, "MutationMatchNotAllowed"},
+		{"MatchRequired", mutate.TargetedMutation{Node: "Tag", Op: mutate.OpUpdate}, "MutationMatchRequired"},
+		{"DeleteValues", mutate.TargetedMutation{Node: "Tag", Op: mutate.OpDelete, Match: map[string]any{"id": 1}, Fields: map[string]any{"name": "x"}}, "MutationDeleteValues"},
+		{"UnknownNode", mutate.TargetedMutation{Node: "Unknown", Op: mutate.OpCreate}, "UnknownNode"},
+		{"ValueType", mutate.TargetedMutation{Node: "User", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Fields: map[string]any{"age": "old"}}, "MutationValueType"},
+		{"IntegerValue", mutate.TargetedMutation{Node: "User", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Fields: map[string]any{"age": 1.5}}, "MutationValueType"},
+		{"TimeValue", mutate.TargetedMutation{Node: "User", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Fields: map[string]any{"updated_at": "yesterday"}}, "MutationValueType"},
+		{"NotNullable", mutate.TargetedMutation{Node: "User", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Fields: map[string]any{"name": nil}}, "MutationFieldNotNullable"},
+		{"ImmutableID", mutate.TargetedMutation{Node: "User", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Fields: map[string]any{"id": 2}}, "MutationFieldImmutable"},
+		{"Required", mutate.TargetedMutation{Node: "User", Op: mutate.OpCreate, Fields: map[string]any{"id": 900}}, "MutationFieldRequired"},
+		{"RequiredOnUpsert", mutate.TargetedMutation{Node: "Tag", Op: mutate.OpUpsert, Match: map[string]any{"id": 900}}, "MutationFieldRequired"},
+		{"UniqueEdgeIDs", mutate.TargetedMutation{Node: "Article", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Edges: map[string]*mutate.Edge{"author": {Attach: []any{1, 2}}}}, "MutationEdgeUnique"},
+		{"EdgeIDType", mutate.TargetedMutation{Node: "Tag", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Edges: map[string]*mutate.Edge{"articles": {Attach: []any{"one"}}}}, "MutationValueType"},
+		{"NotFound", mutate.TargetedMutation{Node: "Tag", Op: mutate.OpDelete, Match: map[string]any{"name": "Unknown"}}, "MutationMatchNotFound"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.mutation.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+			var verr *mutate.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.rule, verr.Rule)
+		})
+	}
+
+	t.Run("UnknownField", func(t *testing.T) {
+		m := mutate.TargetedMutation{Node: "Tag", Op: mutate.OpCreate, Fields: map[string]any{"label": "x"}}
+		_, err := m.Execute(context.Background(), client, entx.Graph, &defaultMutateConf)
+		var berr *mutate.QueryBuildError
+		require.ErrorAs(t, err, &berr)
+	})
+
+	t.Run("MaxEdgeIDs", func(t *testing.T) {
+		cfg := mutate.NewConfig(mutate.WithMaxEdgeIDs(1))
+		m := mutate.TargetedMutation{Node: "Tag", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Edges: map[string]*mutate.Edge{"articles": {Attach: []any{1, 2}}}}
+		_, err := m.Execute(context.Background(), client, entx.Graph, cfg)
+		var verr *mutate.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "MaxEdgeIDs", verr.Rule)
+	})
+}
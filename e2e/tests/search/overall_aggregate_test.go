@@ -32,14 +32,18 @@ func TestOverallAggregateValidationErrors(t *testing.T) {
 		{
 			aggregates:   dsl.OverallAggregates{{BaseAggregate: dsl.BaseAggregate{Type: dsl.AggCount, Field: "a"}}, {BaseAggregate: dsl.BaseAggregate{Type: dsl.AggCount, Field: "a"}}},
 			expectedRule: "MaxAggregatesPerRequest",
-			cfg:          common.NewConfig(common.WithMaxAggregatesPerRequest(1)),
+			cfg:          newConfig(common.WithMaxAggregatesPerRequest(1)),
+		},
+		{
+			aggregates:   dsl.OverallAggregates{{BaseAggregate: dsl.BaseAggregate{Type: dsl.AggAvg, Field: "User.name"}}},
+			expectedRule: "AggregateFieldType",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.expectedRule, func(t *testing.T) {
 			if tt.cfg == nil {
-				tt.cfg = &common.DefaultConf
+				tt.cfg = &defaultConf
 			}
 			err := runExecutableErr(t, tt.aggregates, tt.cfg)
 			var verr *search.ValidationError
@@ -75,7 +79,7 @@ func TestOverallAggregateBuildErr(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			err := runExecutableErr(t, c.aggregates, &common.DefaultConf)
+			err := runExecutableErr(t, c.aggregates, &defaultConf)
 			c.assert(err)
 		})
 	}
@@ -96,7 +100,7 @@ func TestOverallAggregateExecution(t *testing.T) {
 				{BaseAggregate: dsl.BaseAggregate{Field: "User", Type: dsl.AggCount, Alias: "c3"}},
 			},
 			expectedResponse: search.AggregatesResponse{"c1": int64(5), "c2": int64(5), "c3": int64(5)},
-			cfg:              common.NewConfig(common.WithScalarQueriesChunkSize(2)),
+			cfg:              newConfig(common.WithScalarQueriesChunkSize(2)),
 		},
 		{ // launch only one query in a single group
 			name: "OneScalar",
@@ -105,14 +109,49 @@ func TestOverallAggregateExecution(t *testing.T) {
 			},
 			expectedResponse: search.AggregatesResponse{"c1": float64(200)},
 		},
+		{
+			name: "ExtendedTypes",
+			aggs: search.OverallAggregates{
+				{BaseAggregate: dsl.BaseAggregate{Field: "User.is_active", Type: dsl.AggCountDistinct, Alias: "c1"}},
+				{BaseAggregate: dsl.BaseAggregate{Field: "User.is_active", Type: dsl.AggBoolOr, Alias: "c2"}},
+				{BaseAggregate: dsl.BaseAggregate{Field: "User.age", Type: dsl.AggVariance, Alias: "c3"}},
+				{BaseAggregate: dsl.BaseAggregate{Field: "User.age", Type: dsl.AggStringAgg, Alias: "c4", AggParams: dsl.AggParams{Order: dsl.DirDESC}}},
+			},
+			expectedResponse: search.AggregatesResponse{"c1": int64(2), "c2": true, "c3": float64(250), "c4": "60,50,40,30,20"},
+		},
+		{
+			name: "StddevWithFilters",
+			aggs: search.OverallAggregates{
+				{BaseAggregate: dsl.BaseAggregate{
+					Field:   "User.age",
+					Type:    dsl.AggStddev,
+					Alias:   "c1",
+					Filters: dsl.Filters{{Field: "age", Operator: dsl.OpIn, Value: []any{20, 40, 60}}},
+				}},
+			},
+			expectedResponse: search.AggregatesResponse{"c1": float64(20)},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.cfg == nil {
-				tt.cfg = &common.DefaultConf
+				tt.cfg = &defaultConf
 			}
 			require.Equal(t, tt.expectedResponse, runExecutable(t, tt.aggs, tt.cfg))
 		})
 	}
 }
+
+func TestOverallAggregateTransaction(t *testing.T) {
+	// the scalars of a transaction are executed chunk after chunk
+	group := &search.TxQueryGroup{QueryGroup: search.QueryGroup{
+		Aggregates: search.OverallAggregates{
+			{BaseAggregate: dsl.BaseAggregate{Field: "User", Type: dsl.AggCount, Alias: "c1"}},
+			{BaseAggregate: dsl.BaseAggregate{Field: "User", Type: dsl.AggCount, Alias: "c2"}},
+			{BaseAggregate: dsl.BaseAggregate{Field: "User.age", Type: dsl.AggSum, Alias: "c3"}},
+		},
+	}}
+	res := runExecutable(t, group, newConfig(common.WithScalarQueriesChunkSize(2)))
+	require.Equal(t, search.AggregatesResponse{"c1": int64(5), "c2": int64(5), "c3": float64(200)}, res.Meta.Aggregates)
+}
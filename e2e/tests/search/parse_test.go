@@ -0,0 +1,155 @@
+package e2e_search_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"e2e/ent"
+
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func requireJSON(t *testing.T, expected string, v any) {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(raw))
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"Comparison", `age>18`, `[{"field":"age","operator":">","value":18}]`},
+		{"TopLevelAnd", `age>=18 and name="Bob"`, `[{"field":"age","operator":">=","value":18},{"field":"name","operator":"=","value":"Bob"}]`},
+		{
+			"Precedence", `age>18 and (articles.title like "go" or not is_active=false)`,
+			`[{"field":"age","operator":">","value":18},{"or":[{"field":"articles.title","operator":"like","value":"go"},{"not":{"field":"is_active","operator":"=","value":false}}]}]`,
+		},
+		{"OrBindsLooser", `a=1 or b=2 and c=3`, `[{"or":[{"field":"a","operator":"=","value":1},{"and":[{"field":"b","operator":"=","value":2},{"field":"c","operator":"=","value":3}]}]}]`},
+		{"Lists", `id in [1, 2] and name not in ('a', 'b')`, `[{"field":"id","operator":"in","value":[1,2]},{"field":"name","operator":"not in","value":["a","b"]}]`},
+		{"Between", `age between 18 and 30 and score not between [-1.5, 2]`, `[{"field":"age","operator":"between","value":[18,30]},{"field":"score","operator":"not between","value":[-1.5,2]}]`},
+		{"Null", `age is null or age is not null`, `[{"or":[{"field":"age","operator":"is null"},{"field":"age","operator":"is not null"}]}]`},
+		{"WordOperators", `name ILIKE "bo" and email ends_with '.com'`, `[{"field":"name","operator":"ilike","value":"bo"},{"field":"email","operator":"ends_with","value":".com"}]`},
+		{"BareWordAndEscape", `role=admin and title="say \"hi\""`, `[{"field":"role","operator":"=","value":"admin"},{"field":"title","operator":"=","value":"say \"hi\""}]`},
+		{"BareDate", `created_at>=2025-01-01 and created_at<2025-01-01T10:30:00+02:00`, `[{"field":"created_at","operator":">=","value":"2025-01-01"},{"field":"created_at","operator":"<","value":"2025-01-01T10:30:00+02:00"}]`},
+		{"BareRelativeTime", `created_at>=now-7d and age>-1`, `[{"field":"created_at","operator":">=","value":"now-7d"},{"field":"age","operator":">","value":-1}]`},
+		{"Quantifiers", `all(articles: published=true) and exists(comments)`, `[{"relation":"articles","quantifier":"all","field":"published","operator":"=","value":true},{"relation":"comments","quantifier":"exists"}]`},
+		{"NestedQuantifier", `none(articles: any(comments: body like "x"))`, `[{"relation":"articles","quantifier":"none","and":[{"relation":"comments","quantifier":"any","field":"body","operator":"like","value":"x"}]}]`},
+		{"Count", `count(articles: published=true) >= 2`, `[{"relation":"articles","field":"published","operator":"=","value":true,"count":{"operator":">=","value":2}}]`},
+		{"Empty", `  `, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, err := dsl.ParseFilters(tt.input)
+			require.NoError(t, err)
+			requireJSON(t, tt.expect, fs)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (any, error)
+		input string
+		rule  string
+		err   string
+	}{
+		{"MissingOperator", parseFilters, `age 18`, "FilterSyntax", `expected operator at character 4: age 18`},
+		{"MissingValue", parseFilters, `age >`, "FilterSyntax", `expected value at character 5: age >`},
+		{"UnclosedParen", parseFilters, `(a=1 or b=2`, "FilterSyntax", `expected ")" at character 11: (a=1 or b=2`},
+		{"EmptySegment", parseFilters, `articles..title="x"`, "FilterSyntax", `invalid empty segment at character 9: articles..title="x"`},
+		{"UnterminatedString", parseFilters, `name="bob`, "FilterSyntax", `unterminated string at character 5: name="bob`},
+		{"UnknownQuantifier", parseFilters, `some(articles)`, "FilterSyntax", `unexpected "some" at character 0: some(articles)`},
+		{"Trailing", parseFilters, `a=1 b=2`, "FilterSyntax", `unexpected "b" at character 4: a=1 b=2`},
+		{"UnknownChar", parseFilters, `a=1 & b=2`, "FilterSyntax", `unexpected '&' at character 4: a=1 & b=2`},
+		{"SortTrailingComma", parseSorts, `-name,`, "SortSyntax", `expected field at character 6: -name,`},
+		{"IncludeOption", parseIncludes, `articles(page:5)`, "IncludeSyntax", `unexpected "page" at character 9: articles(page:5)`},
+		{"IncludeTwice", parseIncludes, `articles(limit:1),articles(limit:2)`, "IncludeSyntax", `relation "articles" is included twice with options at character 18: articles(limit:1),articles(limit:2)`},
+		{"SelectQuoted", parseSelect, `name,"email"`, "SelectSyntax", `expected field at character 5: name,"email"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.input)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.rule, verr.Rule)
+			require.EqualError(t, verr.Err, tt.err)
+		})
+	}
+}
+
+func parseFilters(s string) (any, error)  { return dsl.ParseFilters(s) }
+func parseSorts(s string) (any, error)    { return dsl.ParseSorts(s) }
+func parseIncludes(s string) (any, error) { return dsl.ParseIncludes(s) }
+func parseSelect(s string) (any, error)   { return dsl.ParseSelect(s) }
+
+func TestParseSortsIncludesSelect(t *testing.T) {
+	sorts, err := dsl.ParseSorts(`-created_at, +name,age,-count(articles),avg(articles.id)`)
+	require.NoError(t, err)
+	requireJSON(t, `[
+		{"field":"created_at","direction":"DESC"},
+		{"field":"name"},
+		{"field":"age"},
+		{"field":"articles","direction":"DESC","aggregate":"count"},
+		{"field":"articles.id","aggregate":"avg"}
+	]`, sorts)
+
+	incs, err := dsl.ParseIncludes(`articles(limit:5,sort:-id,title,fields:(id,title),filter:published=true and id>1).tags,articles.comments.user,employee`)
+	require.NoError(t, err)
+	requireJSON(t, `[
+		{
+			"relation":"articles","limit":5,
+			"sort":[{"field":"id","direction":"DESC"},{"field":"title"}],
+			"select":["id","title"],
+			"filters":[{"field":"published","operator":"=","value":true},{"field":"id","operator":">","value":1}],
+			"includes":[
+				{"relation":"tags"},
+				{"relation":"comments","includes":[{"relation":"user"}]}
+			]
+		},
+		{"relation":"employee"}
+	]`, incs)
+
+	// options given after the relation was first listed are merged
+	incs, err = dsl.ParseIncludes(`articles.tags,articles(limit:1,offset:2)`)
+	require.NoError(t, err)
+	requireJSON(t, `[{"relation":"articles","limit":1,"offset":2,"includes":[{"relation":"tags"}]}]`, incs)
+
+	sel, err := dsl.ParseSelect(`name, email`)
+	require.NoError(t, err)
+	require.Equal(t, dsl.Select{"name", "email"}, sel)
+}
+
+func TestParseQueryOptions(t *testing.T) {
+	values := url.Values{
+		"filter":   {`age<30 and any(articles: title like "SQL")`},
+		"sort":     {`-age`},
+		"include":  {`articles(fields:title,sort:id)`},
+		"fields":   {`name,age`},
+		"limit":    {`5`},
+		"paginate": {`true`},
+	}
+	opts, err := search.ParseQueryOptions(values)
+	require.NoError(t, err)
+
+	q := &search.TargetedQuery{From: "User", QueryOptions: *opts}
+	res := runExecutable(t, q, &defaultConf)
+	users := entxstd.AsTypedEntities[*ent.User](res.Data.([]entxstd.Entity))
+	require.Len(t, users, 1)
+	require.Equal(t, "User One", users[0].Name)
+	require.Len(t, users[0].Edges.Articles, 2)
+	require.Equal(t, 1, res.Meta.Paginate.Total)
+
+	_, err = search.ParseQueryOptions(url.Values{"limit": {"ten"}})
+	var verr *search.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "QueryParameter", verr.Rule)
+}
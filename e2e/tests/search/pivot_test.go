@@ -0,0 +1,259 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"e2e/ent"
+	"e2e/ent/entx"
+
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func articleIDs(articles []*ent.Article) []int {
+	ids := make([]int, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func tagIDs(tags []*ent.Tag) []int {
+	ids := make([]int, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+func TestPivotFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *dsl.Filter
+		expected []int
+	}{
+		{
+			name:     "Any",
+			filter:   &dsl.Filter{Relation: "tags", Field: "pivot.created_at", Operator: dsl.OpGreaterThan, Value: "2024-01-02"},
+			expected: []int{2, 3},
+		},
+		{
+			name: "SameRow",
+			filter: &dsl.Filter{Relation: "tags", And: dsl.Filters{
+				{Field: "name", Operator: "=", Value: "Go"},
+				{Field: "pivot.position", Operator: "=", Value: 2},
+			}},
+			expected: []int{3},
+		},
+		{
+			name:     "All",
+			filter:   &dsl.Filter{Relation: "tags", Quantifier: dsl.QuantAll, Field: "pivot.position", Operator: dsl.OpGreaterEqual, Value: 1},
+			expected: []int{3},
+		},
+		{
+			name:     "None",
+			filter:   &dsl.Filter{Relation: "tags", Quantifier: dsl.QuantNone, Field: "pivot.position", Operator: "=", Value: 2},
+			expected: []int{1, 2},
+		},
+		{
+			name:     "FieldChain",
+			filter:   &dsl.Filter{Field: "tags.pivot.position", Operator: "=", Value: 1},
+			expected: []int{3},
+		},
+		{
+			name:     "PivotRelation",
+			filter:   &dsl.Filter{Relation: "tags.pivot", Field: "created_at", Operator: dsl.OpLessThan, Value: "2024-01-02"},
+			expected: []int{1},
+		},
+		{
+			name:     "Count",
+			filter:   &dsl.Filter{Relation: "tags", Count: &dsl.RelationCount{Operator: "=", Value: 1}, Field: "pivot.position", Operator: "=", Value: 0},
+			expected: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &search.TargetedQuery{
+				From: "Article",
+				QueryOptions: search.QueryOptions{
+					Filters: dsl.Filters{tt.filter},
+					Sorts:   dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+				},
+			}
+			res := runTargetedQuery[*ent.Article](t, q, &defaultConf)
+			require.Equal(t, tt.expected, articleIDs(res))
+		})
+	}
+
+	t.Run("Inverse", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Relation: "articles", Field: "pivot.position", Operator: dsl.OpGreaterThan, Value: 0}},
+				Sorts:   dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+			},
+		}
+		res := runTargetedQuery[*ent.Tag](t, q, &defaultConf)
+		require.Equal(t, []int{1, 3}, tagIDs(res))
+	})
+
+	t.Run("Syntax", func(t *testing.T) {
+		filters, err := dsl.ParseFilters(`any(tags: name = "Go" and pivot.position > 0)`)
+		require.NoError(t, err)
+		q := &search.TargetedQuery{From: "Article", QueryOptions: search.QueryOptions{Filters: filters}}
+		res := runTargetedQuery[*ent.Article](t, q, &defaultConf)
+		require.Equal(t, []int{3}, articleIDs(res))
+	})
+
+	t.Run("PivotCount", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Article",
+			QueryOptions: search.QueryOptions{Filters: dsl.Filters{{
+				Relation: "tags.pivot", Count: &dsl.RelationCount{Operator: "=", Value: 1},
+			}}},
+		}
+		err := runExecutableErr(t, q, &defaultConf)
+		var berr *search.QueryBuildError
+		require.ErrorAs(t, err, &berr)
+	})
+
+	t.Run("WithoutEdgeSchema", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{Filters: dsl.Filters{{
+				Relation: "articles", Field: "pivot.position", Operator: "=", Value: 1,
+			}}},
+		}
+		err := runExecutableErr(t, q, &defaultConf)
+		var berr *search.QueryBuildError
+		require.ErrorAs(t, err, &berr)
+	})
+}
+
+func TestPivotInclude(t *testing.T) {
+	t.Run("SortAndSelect", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Article",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 3}},
+				Includes: dsl.Includes{{
+					Relation: "tags",
+					Sort:     dsl.Sorts{{Field: "pivot.position", Direction: dsl.DirASC}},
+					Pivot:    []string{"position", "created_at"},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Article](t, q, &defaultConf)
+		require.Len(t, res, 1)
+
+		tags := res[0].Edges.Tags
+		require.Equal(t, []int{3, 1}, tagIDs(tags))
+		for i, tag := range tags {
+			pivot := tag.Metadatas().Pivot
+			require.Equal(t, int64(i+1), pivot["position"])
+			require.NotNil(t, pivot["created_at"])
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 1}},
+				Includes: dsl.Includes{{
+					Relation: "articles",
+					Filters:  dsl.Filters{{Field: "pivot.created_at", Operator: dsl.OpGreaterThan, Value: "2024-01-10"}},
+					Pivot:    []string{"position"},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Tag](t, q, &defaultConf)
+		require.Len(t, res, 1)
+		require.Equal(t, []int{3}, articleIDs(res[0].Edges.Articles))
+		require.Equal(t, int64(2), res[0].Edges.Articles[0].Metadatas().Pivot["position"])
+	})
+
+	t.Run("LimitPerParent", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Article",
+			QueryOptions: search.QueryOptions{
+				Sorts: dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+				Includes: dsl.Includes{{
+					Relation: "tags",
+					Sort:     dsl.Sorts{{Field: "pivot.position", Direction: dsl.DirDESC}},
+					Limit:    dsl.Limit{Limit: 1},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Article](t, q, &defaultConf)
+		require.Len(t, res, 3)
+		require.Equal(t, []int{1}, tagIDs(res[0].Edges.Tags))
+		require.Equal(t, []int{2}, tagIDs(res[1].Edges.Tags))
+		require.Equal(t, []int{1}, tagIDs(res[2].Edges.Tags))
+	})
+
+	t.Run("WithoutEdgeSchema", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{Includes: dsl.Includes{{
+				Relation: "articles",
+				Pivot:    []string{"position"},
+			}}},
+		}
+		err := runExecutableErr(t, q, &defaultConf)
+		var berr *search.QueryBuildError
+		require.ErrorAs(t, err, &berr)
+	})
+
+	t.Run("Explain", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Article",
+			QueryOptions: search.QueryOptions{Includes: dsl.Includes{{
+				Relation: "tags",
+				Sort:     dsl.Sorts{{Field: "pivot.position", Direction: dsl.DirASC}},
+				Pivot:    []string{"position"},
+			}}},
+		}
+		res, err := q.Explain(context.Background(), client, entx.Graph, &defaultConf, false)
+		require.NoError(t, err)
+		require.Len(t, res.Queries, 3)
+		require.Contains(t, res.Queries[1].SQL, "article_tags")
+		require.Equal(t, "Article.tags.pivot", res.Queries[2].Include)
+		require.Contains(t, res.Queries[2].SQL, "__pivot_position")
+	})
+
+	t.Run("SharedAcrossParents", func(t *testing.T) {
+		// the tag Go is related to the articles 1 and 3, each with its own pivot row
+		q := &search.TargetedQuery{
+			From: "Article",
+			QueryOptions: search.QueryOptions{
+				Sorts: dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+				Includes: dsl.Includes{{
+					Relation: "tags",
+					Sort:     dsl.Sorts{{Field: "pivot.position", Direction: dsl.DirASC}},
+					Pivot:    []string{"position", "created_at"},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Article](t, q, &defaultConf)
+		require.Len(t, res, 3)
+
+		positions := make(map[int]map[int]any)
+		for _, a := range res {
+			positions[a.ID] = make(map[int]any)
+			for _, tag := range a.Edges.Tags {
+				positions[a.ID][tag.ID] = tag.Metadatas().Pivot["position"]
+			}
+			require.Nil(t, a.Edges.ArticleTag)
+		}
+		require.Equal(t, map[int]map[int]any{
+			1: {1: int64(0)},
+			2: {2: int64(0)},
+			3: {3: int64(1), 1: int64(2)},
+		}, positions)
+		require.NotEqual(t, res[0].Edges.Tags[0].Metadatas().Pivot["created_at"], res[2].Edges.Tags[1].Metadatas().Pivot["created_at"])
+	})
+}
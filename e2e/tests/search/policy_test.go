@@ -0,0 +1,134 @@
+package e2e_search_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"e2e/ent/entx"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/privacy"
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/mutate"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+// policyNode overrides the policy of a generated node.
+type policyNode struct {
+	entxstd.Node
+	policy ent.Policy
+}
+
+func (n *policyNode) Policy() ent.Policy { return n.policy }
+
+func graphWithMutationPolicy(node string, enforcer func(context.Context, *mutate.MutationModifier) (func(*sql.Selector), error)) entxstd.Graph {
+	graph := maps.Clone(entx.Graph)
+	graph[node] = &policyNode{
+		Node: graph[node],
+		policy: privacy.Policy{
+			Mutation: privacy.MutationPolicy{mutate.MutationPolicy{Enforcer: enforcer}},
+		},
+	}
+	return graph
+}
+
+func TestMutationPolicy(t *testing.T) {
+	cleanupTags(t, 120, 121, 122)
+
+	errForbidden := errors.New("forbidden")
+	var actions []mutate.MutationOp
+	graph := graphWithMutationPolicy("Tag", func(ctx context.Context, m *mutate.MutationModifier) (func(*sql.Selector), error) {
+		actions = append(actions, m.Action)
+		switch m.Action {
+		case mutate.OpMutationCreate, mutate.OpMutationUpdate:
+			if name, ok := m.Field("name"); ok {
+				if name == "Forbidden" {
+					return nil, errForbidden
+				}
+				if err := m.SetField("name", strings.ToUpper(name.(string))); err != nil {
+					return nil, err
+				}
+			}
+			// only the tags created by the test can be changed
+			return sql.FieldGTE("id", 120), nil
+		case mutate.OpMutationDelete:
+			return sql.FieldGTE("id", 120), nil
+		case mutate.OpMutationAttach:
+			m.IDs = slices.DeleteFunc(m.IDs, func(id any) bool { return id == 3 })
+		}
+		return nil, nil
+	})
+	execute := func(m *mutate.TargetedMutation) (*mutate.SearchResponse, error) {
+		return m.Execute(context.Background(), client, graph, &defaultMutateConf)
+	}
+
+	t.Run("RewriteFields", func(t *testing.T) {
+		actions = nil
+		_, err := execute(&mutate.TargetedMutation{
+			Node:   "Tag",
+			Op:     mutate.OpCreate,
+			Fields: map[string]any{"id": 120, "name": "policy"},
+			Edges:  map[string]*mutate.Edge{"articles": {Attach: []any{1, 3}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []mutate.MutationOp{mutate.OpMutationAttach, mutate.OpMutationCreate}, actions)
+		require.Equal(t, []string{"POLICY"}, tagNames(t, 120))
+		require.Equal(t, []int{1}, tagArticleIDs(t, 120))
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		_, err := execute(&mutate.TargetedMutation{Node: "Tag", Op: mutate.OpCreate, Fields: map[string]any{"id": 121, "name": "Forbidden"}})
+		require.ErrorIs(t, err, errForbidden)
+		require.Empty(t, tagNames(t, 121))
+	})
+
+	t.Run("RestrictRows", func(t *testing.T) {
+		_, err := execute(&mutate.TargetedMutation{Node: "Tag", Op: mutate.OpUpdate, Match: map[string]any{"id": 1}, Fields: map[string]any{"name": "hidden"}})
+		var verr *mutate.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "MutationMatchNotFound", verr.Rule)
+
+		_, err = execute(&mutate.TargetedMutation{Node: "Tag", Op: mutate.OpDelete, Match: map[string]any{"id": 1}})
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, "MutationMatchNotFound", verr.Rule)
+
+		_, err = execute(&mutate.TargetedMutation{Node: "Tag", Op: mutate.OpUpdate, Match: map[string]any{"id": 120}, Fields: map[string]any{"name": "updated"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"UPDATED"}, tagNames(t, 120))
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		bulk := &mutate.BulkMutation{
+			Node: "Tag",
+			Op:   mutate.OpCreate,
+			Items: []*mutate.BulkItem{
+				{Fields: map[string]any{"id": 121, "name": "bulk"}},
+				{Fields: map[string]any{"id": 122, "name": "Forbidden"}},
+			},
+			BestEffort: true,
+		}
+		res, err := bulk.Execute(context.Background(), client, graph, &defaultMutateConf)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Affected)
+		require.Len(t, res.Errors, 1)
+		require.ErrorIs(t, res.Errors[0], errForbidden)
+
+		// tag 1 is kept by the policy
+		bulk = &mutate.BulkMutation{
+			Node:    "Tag",
+			Op:      mutate.OpDelete,
+			Filters: dsl.Filters{{Field: "id", Operator: "in", Value: []any{1, 121}}},
+		}
+		res, err = bulk.Execute(context.Background(), client, graph, &defaultMutateConf)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Affected)
+		require.Equal(t, []string{"UPDATED"}, tagNames(t, 120, 121))
+		require.Len(t, tagNames(t, 1), 1)
+	})
+}
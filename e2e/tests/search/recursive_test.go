@@ -0,0 +1,370 @@
+package e2e_search_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"e2e/ent"
+	"e2e/ent/user"
+
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+// seedReportsChain extends the seeded organization with a chain of employees
+// below employee 2: 1 > 2 > 140 > 141 > 142 > 143.
+func seedReportsChain(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	ids := []int{140, 141, 142, 143}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		// deleted from the bottom of the chain, the managers being referenced
+		for i := len(ids) - 1; i >= 0; i-- {
+			require.NoError(t, client.Client.Employee.DeleteOneID(ids[i]).Exec(ctx))
+		}
+		_, err := client.Client.User.Delete().Where(user.IDIn(ids...)).Exec(ctx)
+		require.NoError(t, err)
+	})
+	manager := 2
+	for _, id := range ids {
+		require.NoError(t, client.Client.User.Create().
+			SetID(id).
+			SetName(fmt.Sprintf("User %d", id)).
+			SetEmail(fmt.Sprintf("user%d@example.com", id)).
+			Exec(ctx))
+		require.NoError(t, client.Client.Employee.Create().
+			SetID(id).
+			SetUserID(id).
+			SetDepartmentID(3).
+			SetManagerID(manager).
+			Exec(ctx))
+		manager = id
+	}
+}
+
+func employeeIDs(employees []*ent.Employee) []int {
+	ids := make([]int, len(employees))
+	for i, e := range employees {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestSelfReferentialInverse(t *testing.T) {
+	seedReportsChain(t)
+
+	t.Run("Sort", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: dsl.OpIn, Value: []any{1, 2, 140}}},
+				Sorts: dsl.Sorts{
+					{Field: "employee.reports.id", Aggregate: dsl.AggCount, Direction: dsl.DirDESC},
+					{Field: "id", Direction: dsl.DirASC},
+				},
+			},
+		}
+		users := runTargetedQuery[*ent.User](t, q, &defaultConf)
+		ids := make([]int, len(users))
+		for i, u := range users {
+			ids[i] = u.ID
+		}
+		require.Equal(t, []int{1, 2, 140}, ids)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Department",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 2}},
+				Aggregates: dsl.Aggregates{
+					{BaseAggregate: dsl.BaseAggregate{Field: "employees.reports", Type: dsl.AggCount, Alias: "second_line"}},
+				},
+			},
+		}
+		res := runTargetedQuery[entxstd.Entity](t, q, &defaultConf)
+		require.Equal(t, int64(1), res[0].Metadatas().Aggregates["second_line"])
+	})
+}
+
+func TestRecursiveFilter(t *testing.T) {
+	seedReportsChain(t)
+
+	tests := []struct {
+		name     string
+		filter   *dsl.Filter
+		expected []int
+	}{
+		{
+			name: "Descendants",
+			filter: &dsl.Filter{
+				Relation: "manager", Recursive: &dsl.Recursion{},
+				Field: "id", Operator: "=", Value: 2,
+			},
+			expected: []int{140, 141, 142, 143},
+		},
+		{
+			name: "DescendantsWithinDepth",
+			filter: &dsl.Filter{
+				Relation: "manager", Recursive: &dsl.Recursion{MaxDepth: 2},
+				Field: "id", Operator: "=", Value: 2,
+			},
+			expected: []int{140, 141},
+		},
+		{
+			name: "Ancestors",
+			filter: &dsl.Filter{
+				Relation: "reports", Recursive: &dsl.Recursion{},
+				Field: "id", Operator: "=", Value: 143,
+			},
+			expected: []int{1, 2, 140, 141, 142},
+		},
+		{
+			name: "None",
+			filter: &dsl.Filter{
+				Relation: "manager", Recursive: &dsl.Recursion{}, Quantifier: dsl.QuantNone,
+				Field: "id", Operator: "=", Value: 2,
+			},
+			expected: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "NotExists",
+			filter: &dsl.Filter{
+				Relation: "reports", Recursive: &dsl.Recursion{}, Quantifier: dsl.QuantNotExists,
+			},
+			expected: []int{3, 4, 5, 143},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &search.TargetedQuery{
+				From: "Employee",
+				QueryOptions: search.QueryOptions{
+					Filters: dsl.Filters{tt.filter},
+					Sorts:   dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+				},
+			}
+			res := runTargetedQuery[*ent.Employee](t, q, &defaultConf)
+			require.Equal(t, tt.expected, employeeIDs(res))
+		})
+	}
+
+	t.Run("ThroughRelation", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{
+					Relation: "employee.manager", Recursive: &dsl.Recursion{},
+					Field: "id", Operator: "=", Value: 140,
+				}},
+				Sorts: dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+			},
+		}
+		users := runTargetedQuery[*ent.User](t, q, &defaultConf)
+		require.Len(t, users, 3)
+		require.Equal(t, 141, users[0].ID)
+	})
+}
+
+func TestRecursiveValidation(t *testing.T) {
+	recursiveFilter := func(rec *dsl.Recursion) *search.TargetedQuery {
+		return &search.TargetedQuery{
+			From: "Employee",
+			QueryOptions: search.QueryOptions{Filters: dsl.Filters{{
+				Relation: "manager", Recursive: rec,
+				Field: "id", Operator: "=", Value: 1,
+			}}},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query *search.TargetedQuery
+		cfg   *search.Config
+		rule  string
+	}{
+		{
+			name:  "MaxDepth",
+			query: recursiveFilter(&dsl.Recursion{MaxDepth: 11}),
+			cfg:   &defaultConf,
+			rule:  "MaxRecursionDepth",
+		},
+		{
+			name:  "NoDepth",
+			query: recursiveFilter(&dsl.Recursion{}),
+			cfg:   newConfig(common.WithFilterConfig(common.FilterConfig{})),
+			rule:  "RecursionDepth",
+		},
+		{
+			name: "Quantifier",
+			query: &search.TargetedQuery{
+				From: "Employee",
+				QueryOptions: search.QueryOptions{Filters: dsl.Filters{{
+					Relation: "manager", Recursive: &dsl.Recursion{}, Quantifier: dsl.QuantAll,
+					Field: "id", Operator: "=", Value: 1,
+				}}},
+			},
+			cfg:  &defaultConf,
+			rule: "RecursiveQuantifier",
+		},
+		{
+			name: "IncludeCursor",
+			query: &search.TargetedQuery{
+				From: "Employee",
+				QueryOptions: search.QueryOptions{Includes: dsl.Includes{{
+					Relation:  "reports",
+					Recursive: &dsl.Recursion{},
+					Cursor:    dsl.Cursor{WithCursor: true},
+				}}},
+			},
+			cfg:  &defaultConf,
+			rule: "RecursiveIncludeCursor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runExecutableErr(t, tt.query, tt.cfg)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.rule, verr.Rule)
+		})
+	}
+
+	t.Run("NotSelfReferential", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Employee",
+			QueryOptions: search.QueryOptions{Filters: dsl.Filters{{
+				Relation: "department", Recursive: &dsl.Recursion{},
+				Field: "id", Operator: "=", Value: 1,
+			}}},
+		}
+		err := runExecutableErr(t, q, &defaultConf)
+		var berr *search.QueryBuildError
+		require.ErrorAs(t, err, &berr)
+	})
+}
+
+func TestRecursiveAggregate(t *testing.T) {
+	seedReportsChain(t)
+
+	q := &search.TargetedQuery{
+		From: "Employee",
+		QueryOptions: search.QueryOptions{
+			Filters: dsl.Filters{{Field: "id", Operator: dsl.OpIn, Value: []any{1, 2, 140, 143}}},
+			Sorts:   dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+			Aggregates: dsl.Aggregates{
+				{
+					BaseAggregate: dsl.BaseAggregate{Field: "reports", Type: dsl.AggCount, Alias: "headcount"},
+					Recursive:     &dsl.Recursion{},
+				},
+				{
+					BaseAggregate: dsl.BaseAggregate{Field: "reports", Type: dsl.AggCount, Alias: "two_levels"},
+					Recursive:     &dsl.Recursion{MaxDepth: 2},
+				},
+				{
+					BaseAggregate: dsl.BaseAggregate{
+						Field: "reports.id", Type: dsl.AggCount, Alias: "without_last",
+						Filters: dsl.Filters{{Field: "id", Operator: dsl.OpLessThan, Value: 143}},
+					},
+					Recursive: &dsl.Recursion{},
+				},
+			},
+		},
+	}
+
+	res := runTargetedQuery[*ent.Employee](t, q, &defaultConf)
+	require.Equal(t, []int{1, 2, 140, 143}, employeeIDs(res))
+
+	expected := []struct{ headcount, twoLevels, withoutLast int64 }{
+		{8, 5, 7},
+		{4, 2, 3},
+		{3, 2, 2},
+		{0, 0, 0},
+	}
+	for i, e := range res {
+		aggs := e.Metadatas().Aggregates
+		require.Equal(t, expected[i].headcount, aggs["headcount"], e.ID)
+		require.Equal(t, expected[i].twoLevels, aggs["two_levels"], e.ID)
+		require.Equal(t, expected[i].withoutLast, aggs["without_last"], e.ID)
+	}
+}
+
+func TestRecursiveInclude(t *testing.T) {
+	seedReportsChain(t)
+
+	t.Run("Descendants", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Employee",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 2}},
+				Includes: dsl.Includes{{
+					Relation:  "reports",
+					Recursive: &dsl.Recursion{MaxDepth: 3},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Employee](t, q, &defaultConf)
+		require.Len(t, res, 1)
+
+		current := res[0]
+		for depth, id := range []int{140, 141, 142} {
+			require.Len(t, current.Edges.Reports, 1)
+			current = current.Edges.Reports[0]
+			require.Equal(t, id, current.ID)
+			require.Equal(t, depth+1, current.Metadatas().Depth)
+		}
+		// the employee 143 is below the depth
+		require.Empty(t, current.Edges.Reports)
+	})
+
+	t.Run("Ancestors", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Employee",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 143}},
+				Includes: dsl.Includes{{
+					Relation:  "manager",
+					Recursive: &dsl.Recursion{},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Employee](t, q, &defaultConf)
+		require.Len(t, res, 1)
+
+		var ids, depths []int
+		for current := res[0].Edges.Manager; current != nil; current = current.Edges.Manager {
+			ids = append(ids, current.ID)
+			depths = append(depths, current.Metadatas().Depth)
+		}
+		require.Equal(t, []int{142, 141, 140, 2, 1}, ids)
+		require.Equal(t, []int{1, 2, 3, 4, 5}, depths)
+	})
+
+	t.Run("LimitPerParent", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Employee",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 1}},
+				Includes: dsl.Includes{{
+					Relation:  "reports",
+					Recursive: &dsl.Recursion{MaxDepth: 2},
+					Sort:      dsl.Sorts{{Field: "id", Direction: dsl.DirASC}},
+					Limit:     dsl.Limit{Limit: 2},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Employee](t, q, &defaultConf)
+		require.Len(t, res, 1)
+		require.Equal(t, []int{2, 3}, employeeIDs(res[0].Edges.Reports))
+		require.Equal(t, []int{140}, employeeIDs(res[0].Edges.Reports[0].Edges.Reports))
+		require.Empty(t, res[0].Edges.Reports[1].Edges.Reports)
+		require.Empty(t, res[0].Edges.Reports[0].Edges.Reports[0].Edges.Reports)
+	})
+}
@@ -0,0 +1,290 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"e2e/ent"
+	"e2e/ent/entx"
+
+	"entgo.io/ent/dialect"
+	entxstd "github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortNulls(t *testing.T) {
+	// only the employee 1 has no manager, the others being ordered by their primary key
+	tests := []struct {
+		name     string
+		sort     *dsl.Sort
+		expected []int
+	}{
+		{"First", &dsl.Sort{Field: "manager_id", Nulls: dsl.NullsFirst}, []int{1, 2, 3, 4, 5}},
+		{"Last", &dsl.Sort{Field: "manager_id", Nulls: dsl.NullsLast}, []int{2, 3, 4, 5, 1}},
+		{"DescFirst", &dsl.Sort{Field: "manager_id", Direction: dsl.DirDESC, Nulls: dsl.NullsFirst}, []int{1, 2, 3, 4, 5}},
+		{"DescLast", &dsl.Sort{Field: "manager_id", Direction: dsl.DirDESC, Nulls: dsl.NullsLast}, []int{2, 3, 4, 5, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &search.TargetedQuery{
+				From:         "Employee",
+				QueryOptions: search.QueryOptions{Sorts: dsl.Sorts{tt.sort}},
+			}
+			res := runTargetedQuery[*ent.Employee](t, q, &defaultConf)
+			require.Equal(t, tt.expected, employeeIDs(res))
+		})
+	}
+}
+
+func TestSortTieBreaker(t *testing.T) {
+	q := &search.TargetedQuery{
+		From: "Employee",
+		QueryOptions: search.QueryOptions{
+			Filters: dsl.Filters{{Field: "manager_id", Operator: "=", Value: 1}},
+			Sorts:   dsl.Sorts{{Field: "manager_id"}},
+			Pageable: dsl.Pageable{
+				Limit: dsl.Limit{Limit: 2},
+			},
+		},
+	}
+	var pages [][]int
+	for page := 1; page <= 2; page++ {
+		q.Page = page
+		pages = append(pages, employeeIDs(runTargetedQuery[*ent.Employee](t, q, &defaultConf)))
+	}
+	require.Equal(t, [][]int{{2, 3}, {4, 5}}, pages)
+}
+
+func TestSortAggregateAlias(t *testing.T) {
+	q := &search.TargetedQuery{
+		From: "User",
+		QueryOptions: search.QueryOptions{
+			Aggregates: dsl.Aggregates{
+				{BaseAggregate: dsl.BaseAggregate{Field: "articles", Type: dsl.AggCount, Alias: "article_count"}},
+			},
+			Sorts: dsl.Sorts{{Field: "article_count", Direction: dsl.DirDESC}},
+		},
+	}
+	res := runExecutable(t, q, &defaultConf)
+	require.Equal(t, []int{1, 3, 2, 4, 5}, userIDs(res))
+	users := entxstd.AsTypedEntities[*ent.User](res.Data.([]entxstd.Entity))
+	require.Equal(t, int64(2), users[0].Metadatas().Aggregates["article_count"])
+}
+
+func TestSortValues(t *testing.T) {
+	t.Run("Search", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Sorts: dsl.Sorts{{Field: "name", Values: []any{"SQL", "DevOps"}}},
+			},
+		}
+		res := runTargetedQuery[*ent.Tag](t, q, &defaultConf)
+		require.Equal(t, []int{2, 3, 1}, tagIDs(res))
+	})
+
+	t.Run("Descending", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Sorts: dsl.Sorts{{Field: "name", Values: []any{"SQL", "DevOps"}, Direction: dsl.DirDESC}},
+			},
+		}
+		res := runTargetedQuery[*ent.Tag](t, q, &defaultConf)
+		require.Equal(t, []int{1, 3, 2}, tagIDs(res))
+	})
+
+	t.Run("IncludeLimitPerParent", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "User",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: dsl.OpIn, Value: []any{1, 3}}},
+				Sorts:   dsl.Sorts{{Field: "id"}},
+				Includes: dsl.Includes{{
+					Relation: "articles",
+					Sort:     dsl.Sorts{{Field: "id", Values: []any{2, 3}}},
+					Limit:    dsl.Limit{Limit: 1},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.User](t, q, &defaultConf)
+		require.Len(t, res, 2)
+		require.Equal(t, []int{2}, articleIDs(res[0].Edges.Articles))
+		require.Equal(t, []int{3}, articleIDs(res[1].Edges.Articles))
+	})
+}
+
+func TestSortAggregateFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  dsl.Filters
+		expected []int
+	}{
+		{"Unfiltered", nil, []int{1, 3, 2, 4, 5}},
+		{"Field", dsl.Filters{{Field: "created_at", Operator: dsl.OpGreaterEqual, Value: "2024-01-10"}}, []int{3, 1, 2, 4, 5}},
+		{"Relation", dsl.Filters{{Field: "tags.name", Operator: "=", Value: "DevOps"}}, []int{3, 1, 2, 4, 5}},
+		{"NoMatch", dsl.Filters{{Field: "published", Operator: "=", Value: false}}, []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &search.TargetedQuery{
+				From: "User",
+				QueryOptions: search.QueryOptions{
+					Sorts: dsl.Sorts{{Field: "articles.id", Aggregate: dsl.AggCount, Direction: dsl.DirDESC, Filters: tt.filters}},
+				},
+			}
+			require.Equal(t, tt.expected, userIDs(runExecutable(t, q, &defaultConf)))
+		})
+	}
+
+	t.Run("Include", func(t *testing.T) {
+		q := &search.TargetedQuery{
+			From: "Tag",
+			QueryOptions: search.QueryOptions{
+				Filters: dsl.Filters{{Field: "id", Operator: "=", Value: 1}},
+				Includes: dsl.Includes{{
+					Relation: "articles",
+					Sort: dsl.Sorts{{
+						Field: "tags.id", Aggregate: dsl.AggCount, Direction: dsl.DirDESC,
+						Filters: dsl.Filters{{Field: "name", Operator: "=", Value: "DevOps"}},
+					}},
+					Limit: dsl.Limit{Limit: 1},
+				}},
+			},
+		}
+		res := runTargetedQuery[*ent.Tag](t, q, &defaultConf)
+		require.Len(t, res, 1)
+		require.Equal(t, []int{3}, articleIDs(res[0].Edges.Articles))
+	})
+}
+
+func TestSortAggregateFiltersWithoutFilterConfig(t *testing.T) {
+	s := &dsl.Sort{
+		Field: "articles.id", Aggregate: dsl.AggCount,
+		Filters: dsl.Filters{{Field: "title", Operator: "=", Value: "a"}},
+	}
+	require.NoError(t, s.ValidateAndPreprocess(&common.SortConfig{}))
+	_, err := s.Predicate(context.Background(), entx.Graph["User"], defaultConf.Dialect)
+	require.NoError(t, err)
+}
+
+func TestSortValidation(t *testing.T) {
+	userSorts := func(sorts ...*dsl.Sort) *search.TargetedQuery {
+		return &search.TargetedQuery{
+			From:         "User",
+			QueryOptions: search.QueryOptions{Sorts: sorts},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query *search.TargetedQuery
+		cfg   *search.Config
+		rule  string
+	}{
+		{
+			name:  "Nulls",
+			query: userSorts(&dsl.Sort{Field: "age", Nulls: "middle"}),
+			cfg:   &defaultConf,
+			rule:  "SortNulls",
+		},
+		{
+			name:  "AggregateDialect",
+			query: userSorts(&dsl.Sort{Field: "articles.id", Aggregate: dsl.AggMedian}),
+			cfg:   newConfig(common.WithDialect(dialect.MySQL)),
+			rule:  "AggregateDialect",
+		},
+		{
+			name:  "ValuesEmpty",
+			query: userSorts(&dsl.Sort{Field: "name", Values: []any{}}),
+			cfg:   &defaultConf,
+			rule:  "SortValues",
+		},
+		{
+			name:  "ValuesRelation",
+			query: userSorts(&dsl.Sort{Field: "articles.title", Values: []any{"Go"}}),
+			cfg:   &defaultConf,
+			rule:  "SortValues",
+		},
+		{
+			name:  "ValuesAggregate",
+			query: userSorts(&dsl.Sort{Field: "articles.id", Aggregate: dsl.AggCount, Values: []any{1}}),
+			cfg:   &defaultConf,
+			rule:  "SortValues",
+		},
+		{
+			name:  "ValuesPrimitive",
+			query: userSorts(&dsl.Sort{Field: "name", Values: []any{[]string{"a"}}}),
+			cfg:   &defaultConf,
+			rule:  "SortValues",
+		},
+		{
+			name:  "MaxValues",
+			query: userSorts(&dsl.Sort{Field: "age", Values: []any{20, 30}}),
+			cfg:   newConfig(common.WithSortConfig(common.SortConfig{MaxSortValues: 1})),
+			rule:  "MaxSortValues",
+		},
+		{
+			name:  "FiltersWithoutAggregate",
+			query: userSorts(&dsl.Sort{Field: "age", Filters: dsl.Filters{{Field: "age", Operator: "=", Value: 1}}}),
+			cfg:   &defaultConf,
+			rule:  "SortFiltersWithoutAggregate",
+		},
+		{
+			name: "FiltersRelationDepth",
+			query: userSorts(&dsl.Sort{
+				Field: "articles.id", Aggregate: dsl.AggCount,
+				Filters: dsl.Filters{{Field: "tags.name", Operator: "=", Value: "Go"}},
+			}),
+			cfg:  newConfig(common.WithSortConfig(common.SortConfig{MaxSortRelationDepth: 1})),
+			rule: "MaxSortRelationsDepth",
+		},
+		{
+			name: "FiltersConfig",
+			query: userSorts(&dsl.Sort{
+				Field: "articles.id", Aggregate: dsl.AggCount,
+				Filters: dsl.Filters{{Field: "title", Operator: "=", Value: "a"}, {Field: "title", Operator: "=", Value: "b"}},
+			}),
+			cfg:  newConfig(common.WithFilterConfig(common.FilterConfig{MaxFilterTreeCount: 1})),
+			rule: "MaxFilterTreeCount",
+		},
+		{
+			name: "AliasConflict",
+			query: &search.TargetedQuery{
+				From: "User",
+				QueryOptions: search.QueryOptions{
+					Aggregates: dsl.Aggregates{
+						{BaseAggregate: dsl.BaseAggregate{Field: "articles", Type: dsl.AggCount, Alias: "article_count"}},
+					},
+					Sorts: dsl.Sorts{{Field: "article_count", Aggregate: dsl.AggCount}},
+				},
+			},
+			cfg:  &defaultConf,
+			rule: "SortAliasConflict",
+		},
+		{
+			name: "Cursor",
+			query: &search.TargetedQuery{
+				From: "User",
+				QueryOptions: search.QueryOptions{
+					Sorts:    dsl.Sorts{{Field: "age", Nulls: dsl.NullsLast}},
+					Pageable: dsl.Pageable{Cursor: dsl.Cursor{WithCursor: true}},
+				},
+			},
+			cfg:  &defaultConf,
+			rule: "CursorSortUnsupported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runExecutableErr(t, tt.query, tt.cfg)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, tt.rule, verr.Rule)
+		})
+	}
+}
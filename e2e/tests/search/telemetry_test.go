@@ -0,0 +1,163 @@
+package e2e_search_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTelemetryConfig() (*search.Config, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	cfg := newConfig(common.WithTelemetry(
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	))
+	return cfg, spans, reader
+}
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestTelemetryGroup(t *testing.T) {
+	cfg, spans, reader := newTelemetryConfig()
+	group := &search.QueryGroup{
+		Searches: search.NamedQueries{{
+			Key: "users",
+			TargetedQuery: search.TargetedQuery{
+				From:         "User",
+				QueryOptions: search.QueryOptions{WithPagination: true, EnableTransaction: new(bool)},
+			},
+		}},
+		Aggregates: search.OverallAggregates{{BaseAggregate: dsl.BaseAggregate{Field: "Tag", Type: dsl.AggCount, Alias: "total"}}},
+	}
+	res := runExecutable(t, group, cfg)
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans.Ended() {
+		byName[span.Name()] = span
+	}
+	for _, name := range []string{common.SpanValidate, common.SpanBuild, common.SpanExecute, common.SpanQuery, common.SpanScalars} {
+		require.Contains(t, byName, name)
+	}
+
+	query := spanAttrs(byName[common.SpanQuery])
+	require.Equal(t, "User", query[common.AttrNode].AsString())
+	require.Equal(t, "users", query[common.AttrSearchKey].AsString())
+	require.Equal(t, int64(res.Searches["users"].Meta.Count), query[common.AttrRows].AsInt64())
+	require.Contains(t, query[common.AttrStatement].AsString(), "SELECT")
+
+	scalars := spanAttrs(byName[common.SpanScalars])
+	require.Equal(t, []string{"users", "total"}, scalars[common.AttrScalars].AsStringSlice())
+
+	// the search query is traced within the execution phase
+	require.Equal(t, byName[common.SpanExecute].SpanContext().SpanID(), byName[common.SpanQuery].Parent().SpanID())
+
+	metrics := collectMetrics(t, reader)
+	for _, name := range []string{common.MetricBuildDuration, common.MetricExecDuration} {
+		histogram, ok := metrics[name].(metricdata.Histogram[float64])
+		require.True(t, ok, name)
+		require.Equal(t, uint64(1), histogram.DataPoints[0].Count)
+	}
+}
+
+func TestTelemetryTransaction(t *testing.T) {
+	cfg, spans, _ := newTelemetryConfig()
+	q := &search.TargetedQuery{From: "Tag", QueryOptions: search.QueryOptions{WithPagination: true}}
+	runExecutable(t, q, cfg)
+
+	var names []string
+	for _, span := range spans.Ended() {
+		names = append(names, span.Name())
+	}
+	require.Contains(t, names, common.SpanTransaction)
+	require.Contains(t, names, common.SpanScalars)
+}
+
+func TestTelemetryValidationErrors(t *testing.T) {
+	cfg, spans, reader := newTelemetryConfig()
+	q := &search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{
+		WithPagination: true,
+		Pageable:       dsl.Pageable{Cursor: dsl.Cursor{After: "a"}},
+	}}
+	runExecutableErr(t, q, cfg)
+	runExecutableErr(t, q, cfg)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	require.Equal(t, common.SpanValidate, ended[0].Name())
+	require.Equal(t, "CursorPaginationConflict", spanAttrs(ended[0])[common.AttrRule].AsString())
+
+	counter, ok := collectMetrics(t, reader)[common.MetricValidationErrors].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, counter.DataPoints, 1)
+	require.Equal(t, int64(2), counter.DataPoints[0].Value)
+	rule, _ := counter.DataPoints[0].Attributes.Value(common.AttrRule)
+	require.Equal(t, "CursorPaginationConflict", rule.AsString())
+}
+
+func TestTelemetryTypeValidationErrors(t *testing.T) {
+	// the types of the fields are checked by the validation phase, before any build
+	requireValidateSpan := func(t *testing.T, err error, spans *tracetest.SpanRecorder, reader *sdkmetric.ManualReader, expected string) {
+		t.Helper()
+		var verr *search.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Equal(t, expected, verr.Rule)
+
+		ended := spans.Ended()
+		require.Len(t, ended, 1)
+		require.Equal(t, common.SpanValidate, ended[0].Name())
+		require.Equal(t, expected, spanAttrs(ended[0])[common.AttrRule].AsString())
+
+		counter, ok := collectMetrics(t, reader)[common.MetricValidationErrors].(metricdata.Sum[int64])
+		require.True(t, ok)
+		require.Len(t, counter.DataPoints, 1)
+		rule, _ := counter.DataPoints[0].Attributes.Value(common.AttrRule)
+		require.Equal(t, expected, rule.AsString())
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		cfg, spans, reader := newTelemetryConfig()
+		q := &search.TargetedQuery{From: "User", QueryOptions: search.QueryOptions{
+			Filters: dsl.Filters{{Field: "articles.title", Operator: dsl.OpEqual, Value: 1}},
+		}}
+		requireValidateSpan(t, runExecutableErr(t, q, cfg), spans, reader, "FilterValueType")
+	})
+
+	t.Run("Buckets", func(t *testing.T) {
+		cfg, spans, reader := newTelemetryConfig()
+		q := &search.QueryGroup{Histograms: search.DateHistograms{{
+			OverallAggregate: dsl.OverallAggregate{BaseAggregate: dsl.BaseAggregate{Field: "Article", Type: dsl.AggCount}},
+			On:               "title",
+			Interval:         dsl.IntervalDay,
+		}}}
+		requireValidateSpan(t, runExecutableErr(t, q, cfg), spans, reader, "HistogramFieldType")
+	})
+}
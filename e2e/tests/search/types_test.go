@@ -0,0 +1,37 @@
+package e2e_search_test
+
+import (
+	"testing"
+
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldTypeValidation(t *testing.T) {
+	cases := []struct {
+		name         string
+		expectedRule string
+		options      search.QueryOptions
+	}{
+		{"OrderedOperatorOnBool", "FilterOperatorType", search.QueryOptions{Filters: dsl.Filters{{Field: "is_active", Operator: dsl.OpGreaterThan, Value: 1}}}},
+		{"RangeOnString", "FilterOperatorType", search.QueryOptions{Filters: dsl.Filters{{Field: "name", Operator: dsl.OpBetween, Value: []any{"2024-01-01", "2024-12-31"}}}}},
+		{"LikeOnInt", "FilterOperatorType", search.QueryOptions{Filters: dsl.Filters{{Field: "age", Operator: dsl.OpLike, Value: "2%"}}}},
+		{"StringOnInt", "FilterValueType", search.QueryOptions{Filters: dsl.Filters{{Field: "age", Operator: dsl.OpEqual, Value: "20"}}}},
+		{"FloatOnInt", "FilterValueType", search.QueryOptions{Filters: dsl.Filters{{Field: "age", Operator: dsl.OpIn, Value: []any{20, 20.5}}}}},
+		{"NumberOnBool", "FilterValueType", search.QueryOptions{Filters: dsl.Filters{{Field: "is_active", Operator: dsl.OpEqual, Value: 1}}}},
+		{"NestedFieldType", "FilterValueType", search.QueryOptions{Filters: dsl.Filters{{Field: "articles.published", Operator: dsl.OpEqual, Value: "yes"}}}},
+		{"SumOnString", "AggregateFieldType", search.QueryOptions{Aggregates: dsl.Aggregates{{BaseAggregate: dsl.BaseAggregate{Field: "email", Type: dsl.AggSum}}}}},
+		{"MaxOnBool", "AggregateFieldType", search.QueryOptions{Aggregates: dsl.Aggregates{{BaseAggregate: dsl.BaseAggregate{Field: "articles.published", Type: dsl.AggMax}}}}},
+		{"SortAggregateOnString", "AggregateFieldType", search.QueryOptions{Sorts: dsl.Sorts{{Field: "articles.title", Aggregate: dsl.AggAvg}}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q := search.TargetedQuery{From: "User", QueryOptions: c.options}
+			err := runExecutableErr(t, &q, &defaultConf)
+			var verr *search.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Equal(t, c.expectedRule, verr.Rule)
+		})
+	}
+}
@@ -3,13 +3,23 @@ package e2e_search_test
 import (
 	"context"
 	"e2e/ent/entx"
+	"e2e/tests"
 	"testing"
 
 	entxstd "github.com/brice-74/entx"
 	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
 	"github.com/stretchr/testify/require"
 )
 
+// defaultConf is the default configuration bound to the dialect under test.
+var defaultConf = *newConfig()
+
+// newConfig builds a configuration for the dialect under test.
+func newConfig(opts ...common.Option) *search.Config {
+	return common.NewConfig(append([]common.Option{common.WithDialect(tests.Dialect())}, opts...)...)
+}
+
 type Executable[T any] interface {
 	Execute(ctx context.Context, client entxstd.Client, graph entxstd.Graph, cfg *search.Config) (T, error)
 }
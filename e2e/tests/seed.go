@@ -50,18 +50,21 @@ func SeedBaseData(ctx context.Context, client *ent.Client) error {
 				SetTitle("Go Concurrency Patterns").
 				SetContent("This article explores common concurrency patterns in Go, including channels and goroutines.").
 				SetPublished(true).
+				SetCreatedAt(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)).
 				SetUserID(1),
 			client.Article.Create().
 				SetID(2).
 				SetTitle("Understanding SQL Joins").
 				SetContent("Learn how different types of SQL joins work with real-world examples.").
 				SetPublished(true).
+				SetCreatedAt(time.Date(2024, 1, 3, 23, 30, 0, 0, time.UTC)).
 				SetUserID(1),
 			client.Article.Create().
 				SetID(3).
 				SetTitle("Docker for Developers").
 				SetContent("A beginner-friendly guide to using Docker for local development and deployments.").
 				SetPublished(true).
+				SetCreatedAt(time.Date(2024, 1, 17, 8, 0, 0, 0, time.UTC)).
 				SetUserID(3),
 		).Exec(ctx); err != nil {
 			return err
@@ -100,16 +103,22 @@ func SeedBaseData(ctx context.Context, client *ent.Client) error {
 		if err := client.ArticleTag.CreateBulk(
 			client.ArticleTag.Create().
 				SetArticleID(1). // Article 1
-				SetTagID(1),     // Tag Go
+				SetTagID(1).     // Tag Go
+				SetCreatedAt(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)),
 			client.ArticleTag.Create().
 				SetArticleID(2). // Article 2
-				SetTagID(2),     // Tag SQL
+				SetTagID(2).     // Tag SQL
+				SetCreatedAt(time.Date(2024, 1, 3, 23, 30, 0, 0, time.UTC)),
 			client.ArticleTag.Create().
 				SetArticleID(3). // Article 3
-				SetTagID(3),     // Tag DevOps
+				SetTagID(3).     // Tag DevOps
+				SetPosition(1).
+				SetCreatedAt(time.Date(2024, 1, 17, 8, 0, 0, 0, time.UTC)),
 			client.ArticleTag.Create().
 				SetArticleID(3). // Article 3
-				SetTagID(1),     // Tag Go
+				SetTagID(1).     // Tag Go, tagged later in second position
+				SetPosition(2).
+				SetCreatedAt(time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)),
 		).Exec(ctx); err != nil {
 			return err
 		}
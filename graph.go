@@ -27,10 +27,50 @@ type (
 
 	EntityClient interface {
 		Query() Query
+		Create() Create
+		CreateBulk(...Create) CreateBulk
+		Update() Update
+		UpdateOne(Entity) UpdateOne
+		Delete() Delete
+	}
+
+	// Create and UpdateOne expose the ent mutation of their builder,
+	// on which fields are set by name.
+	Create interface {
+		Mutation() ent.Mutation
+		// SetID sets the id of a node whose id is defined by the user.
+		SetID(id any) error
+		Save(ctx context.Context) (Entity, error)
+	}
+
+	CreateBulk interface {
+		Save(ctx context.Context) ([]Entity, error)
+	}
+
+	Update interface {
+		Mutation() ent.Mutation
+		Predicate(...func(s *sql.Selector)) Update
+		Save(ctx context.Context) (int, error)
+	}
+
+	UpdateOne interface {
+		Mutation() ent.Mutation
+		Save(ctx context.Context) (Entity, error)
+	}
+
+	Delete interface {
+		Predicate(...func(s *sql.Selector)) Delete
+		Exec(ctx context.Context) (int, error)
 	}
 
 	EntityMeta struct {
 		Aggregates map[string]any `json:"aggregates,omitempty"`
+		// opaque keyset position of the entity, set when cursor pagination is used
+		Cursor string `json:"cursor,omitempty"`
+		// level of the entity in a recursive include, starting at 1
+		Depth int `json:"depth,omitempty"`
+		// fields of the pivot row relating an included entity to its parent, when selected
+		Pivot map[string]any `json:"pivot,omitempty"`
 	}
 
 	Entity interface {
@@ -52,6 +92,8 @@ type (
 		Policy() ent.Policy
 		NewQuery(Client) Query
 		FieldByName(s string) *Field
+		Fields() []*Field
+		Bridges() map[string]Bridge
 		Bridge(string) Bridge
 	}
 
@@ -60,13 +102,36 @@ type (
 		JoinPivot(*sql.Selector, ...*sql.SelectTable) *sql.SelectTable
 		Join(*sql.Selector, ...*sql.SelectTable) []*sql.SelectTable
 		Include(parentQuery Query, childQuery func(Query), handlers ...EntityHandler) Query
+		// Attach and Detach add or remove the given child ids to the relation of a parent mutation,
+		// a unique relation being set by Attach and cleared by Detach.
+		Attach(m ent.Mutation, ids ...any) error
+		Detach(m ent.Mutation, ids ...any) error
 		FilterWith(...func(*sql.Selector)) func(*sql.Selector)
 		Filter() func(*sql.Selector)
 		Inverse() Bridge
+		// Pivot returns the edge schema node of an M2M relation, whose table is the pivot table,
+		// or nil when the relation has no edge schema.
+		Pivot() Node
 		Child() Node
 		Parent() Node
 	}
 
+	// TxNode is implemented by the nodes whose mutations may run in an ent transaction.
+	TxNode interface {
+		// OnCommit runs fn once the transaction of a mutation of the node committed,
+		// it returns false when the mutation does not run in a transaction.
+		OnCommit(m ent.Mutation, fn func(context.Context)) bool
+	}
+
+	// PivotIncluder is implemented by the bridges of M2M relations backed by an edge schema
+	// whose parent node has the relation to the pivot rows.
+	PivotIncluder interface {
+		// IncludePivot includes the children as Include does and loads the pivot rows of the parents
+		// with pivotQuery. A child related to several parents is copied for each of them, handler
+		// receiving each copy with the pivot row relating it to its parent.
+		IncludePivot(parentQuery Query, childQuery, pivotQuery func(Query), handler PivotHandler, handlers ...EntityHandler) Query
+	}
+
 	RelationInfos struct {
 		RelType sqlgraph.Rel
 		// used by O2O, O2M, M2O and M2M relations
@@ -79,4 +144,7 @@ type (
 	}
 
 	EntityHandler func(entities []Entity) error
+
+	// PivotHandler handles an included entity with the pivot row relating it to its parent.
+	PivotHandler func(child, pivot Entity) error
 )
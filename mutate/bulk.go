@@ -0,0 +1,328 @@
+package mutate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	stdsql "database/sql"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+	"github.com/brice-74/entx/search/dsl"
+)
+
+// BulkMutation creates, updates or deletes many rows of a node in a single request.
+type BulkMutation struct {
+	Node string `json:"node"`
+	Op   Op     `json:"op"`
+	// Items are the entities of a create operation.
+	Items []*BulkItem `json:"items,omitempty"`
+	// Filters select the rows of update and delete operations.
+	Filters dsl.Filters `json:"filters,omitempty"`
+	// Fields and Edges are applied to every row of an update operation.
+	Fields map[string]any   `json:"fields,omitempty"`
+	Edges  map[string]*Edge `json:"edges,omitempty"`
+	// BestEffort creates the valid items when others fail, instead of creating none of them.
+	BestEffort bool `json:"best_effort,omitempty"`
+}
+
+type BulkItem struct {
+	Fields map[string]any   `json:"fields,omitempty"`
+	Edges  map[string]*Edge `json:"edges,omitempty"`
+}
+
+// ItemError is the error of an item of a bulk create, identified by its position.
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+func (e *ItemError) Unwrap() error { return e.Err }
+
+func (e *ItemError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Index int    `json:"index"`
+		Error string `json:"error"`
+	}{e.Index, e.Err.Error()})
+}
+
+type BulkResponse struct {
+	// Data holds the created entities.
+	Data     []entx.Entity `json:"data,omitempty"`
+	Affected int           `json:"affected"`
+	Errors   []*ItemError  `json:"errors,omitempty"`
+}
+
+func (m *BulkMutation) Execute(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *Config,
+) (*BulkResponse, error) {
+	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
+	defer cancel()
+
+	if err := m.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	build, err := m.Build(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	return build.Execute(ctx, client, cfg)
+}
+
+func (m *BulkMutation) Validate(cfg *Config) error {
+	switch m.Op {
+	case OpCreate:
+		if len(m.Items) == 0 || len(m.Filters) > 0 || len(m.Fields) > 0 || len(m.Edges) > 0 {
+			return &ValidationError{
+				Rule: "BulkCreateInput",
+				Err:  errors.New("a bulk create requires items, without filters, fields nor edges"),
+			}
+		}
+		if count := len(m.Items); cfg.MaxBulkItems != 0 && count > cfg.MaxBulkItems {
+			return &ValidationError{
+				Rule: "MaxBulkItems",
+				Err:  fmt.Errorf("found %d items, but the maximum allowed is %d", count, cfg.MaxBulkItems),
+			}
+		}
+		for _, item := range m.Items {
+			if item == nil {
+				continue
+			}
+			if err := validateEdges(cfg, item.Edges); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OpUpdate, OpDelete:
+		if len(m.Items) > 0 {
+			return &ValidationError{
+				Rule: "BulkItemsNotAllowed",
+				Err:  fmt.Errorf("items are not allowed on a bulk %s", m.Op),
+			}
+		}
+		// a bulk mutation without filters would change the whole table
+		if len(m.Filters) == 0 {
+			return &ValidationError{
+				Rule: "BulkFiltersRequired",
+				Err:  fmt.Errorf("filters are required on a bulk %s", m.Op),
+			}
+		}
+		if m.Op == OpUpdate && len(m.Fields) == 0 && len(m.Edges) == 0 {
+			return &ValidationError{
+				Rule: "BulkUpdateValues",
+				Err:  errors.New("a bulk update requires fields or edges"),
+			}
+		}
+		if m.Op == OpDelete && (len(m.Fields) > 0 || len(m.Edges) > 0) {
+			return &ValidationError{
+				Rule: "MutationDeleteValues",
+				Err:  errors.New("fields and edges are not allowed on a delete operation"),
+			}
+		}
+		if err := validateEdges(cfg, m.Edges); err != nil {
+			return err
+		}
+		return m.Filters.ValidateAndPreprocess(&cfg.FilterConfig)
+	default:
+		return &ValidationError{
+			Rule: "BulkOp",
+			Err:  fmt.Errorf("invalid bulk operation %q, expected one of create, update or delete", m.Op),
+		}
+	}
+}
+
+// Build resolves the node and the items of the mutation. The items failing to build
+// are returned as errors of the build in best effort mode.
+func (m *BulkMutation) Build(graph entx.Graph) (*BulkMutationBuild, error) {
+	node, found := graph[m.Node]
+	if !found {
+		return nil, &ValidationError{
+			Rule: "UnknownNode",
+			Err:  fmt.Errorf(ErrNodeMissing, m.Node),
+		}
+	}
+
+	build := &BulkMutationBuild{Node: node, Op: m.Op, Input: m, BestEffort: m.BestEffort}
+	for i, item := range m.Items {
+		var (
+			ib  BulkItemBuild
+			err error
+		)
+		if item != nil {
+			ib.Fields, err = buildValues(node, item.Fields, false)
+			if err == nil {
+				ib.Edges, err = buildEdges(node, item.Edges)
+			}
+		}
+		if err == nil {
+			err = checkRequired(node, ib.Fields, ib.Edges)
+		}
+		if err != nil {
+			ierr := &ItemError{Index: i, Err: err}
+			if !m.BestEffort {
+				return nil, ierr
+			}
+			build.Errors = append(build.Errors, ierr)
+			continue
+		}
+		ib.Index = i
+		build.Items = append(build.Items, &ib)
+	}
+	if err := m.Filters.CheckTypes(node); err != nil {
+		return nil, err
+	}
+	var err error
+	if build.Predicates, err = m.Filters.Predicate(node); err != nil {
+		return nil, err
+	}
+	if build.Fields, err = buildValues(node, m.Fields, true); err != nil {
+		return nil, err
+	}
+	if build.Edges, err = buildEdges(node, m.Edges); err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+type BulkItemBuild struct {
+	// position of the item in the input
+	Index  int
+	Fields []*FieldValue
+	Edges  []*EdgeBuild
+}
+
+type BulkMutationBuild struct {
+	Node entx.Node
+	Op   Op
+	// Input is given to the mutation policy of the node.
+	Input      any
+	BestEffort bool
+	Items      []*BulkItemBuild
+	Predicates []func(*sql.Selector)
+	Fields     []*FieldValue
+	Edges      []*EdgeBuild
+	// errors of the items discarded while building
+	Errors []*ItemError
+}
+
+func (build *BulkMutationBuild) Execute(ctx context.Context, client entx.Client, cfg *Config) (*BulkResponse, error) {
+	res := &BulkResponse{Errors: build.Errors}
+	txOpts := &stdsql.TxOptions{Isolation: cfg.IsolationLevel}
+
+	var err error
+	switch build.Op {
+	case OpCreate:
+		if len(build.Items) == 0 {
+			return res, nil
+		}
+		res.Data, err = search.WithTx(ctx, client, txOpts, func(ctx context.Context, tx entx.Client) ([]entx.Entity, error) {
+			return build.createBulk(ctx, tx, build.Items...)
+		})
+		if err != nil && build.BestEffort {
+			return build.createOneByOne(ctx, client, txOpts, res)
+		}
+	case OpUpdate:
+		res.Affected, err = search.WithTx(ctx, client, txOpts, build.update)
+	case OpDelete:
+		res.Affected, err = search.WithTx(ctx, client, txOpts, func(ctx context.Context, tx entx.Client) (int, error) {
+			ec, err := tx.GetEntityClient(build.Node)
+			if err != nil {
+				return 0, err
+			}
+			modifier, err := enforce(ctx, build.Node, build.Input, OpMutationDelete, nil)
+			if err != nil {
+				return 0, err
+			}
+			return ec.Delete().Predicate(build.predicates(modifier)...).Exec(ctx)
+		})
+	}
+	if err != nil {
+		return nil, execError("BulkMutation.execute", err)
+	}
+	if build.Op == OpCreate {
+		res.Affected = len(res.Data)
+	}
+	return res, nil
+}
+
+func (build *BulkMutationBuild) createBulk(ctx context.Context, client entx.Client, items ...*BulkItemBuild) ([]entx.Entity, error) {
+	ec, err := client.GetEntityClient(build.Node)
+	if err != nil {
+		return nil, err
+	}
+	creates := make([]entx.Create, len(items))
+	for i, item := range items {
+		if creates[i], err = newCreate(ctx, ec, build.Node, build.Input, item.Fields, item.Edges); err != nil {
+			return nil, &ItemError{Index: item.Index, Err: err}
+		}
+	}
+	return ec.CreateBulk(creates...).Save(ctx)
+}
+
+// createOneByOne creates each item in its own transaction after the bulk insert failed,
+// to report the items at fault and keep the others.
+func (build *BulkMutationBuild) createOneByOne(
+	ctx context.Context,
+	client entx.Client,
+	txOpts *stdsql.TxOptions,
+	res *BulkResponse,
+) (*BulkResponse, error) {
+	res.Data = nil
+	for _, item := range build.Items {
+		entities, err := search.WithTx(ctx, client, txOpts, func(ctx context.Context, tx entx.Client) ([]entx.Entity, error) {
+			return build.createBulk(ctx, tx, item)
+		})
+		if err != nil {
+			var ierr *ItemError
+			if !errors.As(err, &ierr) {
+				ierr = &ItemError{Index: item.Index, Err: err}
+			}
+			res.Errors = append(res.Errors, ierr)
+			continue
+		}
+		res.Data = append(res.Data, entities...)
+	}
+	res.Affected = len(res.Data)
+	slices.SortFunc(res.Errors, func(a, b *ItemError) int { return a.Index - b.Index })
+	return res, nil
+}
+
+func (build *BulkMutationBuild) update(ctx context.Context, client entx.Client) (int, error) {
+	ec, err := client.GetEntityClient(build.Node)
+	if err != nil {
+		return 0, err
+	}
+	u := ec.Update()
+	if err := setFields(u.Mutation(), build.Fields); err != nil {
+		return 0, err
+	}
+	if err := applyEdges(ctx, build.Node, build.Input, u.Mutation(), build.Edges); err != nil {
+		return 0, err
+	}
+	modifier, err := enforce(ctx, build.Node, build.Input, OpMutationUpdate, u.Mutation())
+	if err != nil {
+		return 0, err
+	}
+	return u.Predicate(build.predicates(modifier)...).Save(ctx)
+}
+
+// predicates returns the predicates of the filters restricted by the policy modifier.
+func (build *BulkMutationBuild) predicates(modifier func(*sql.Selector)) []func(*sql.Selector) {
+	if modifier == nil {
+		return build.Predicates
+	}
+	return append(slices.Clone(build.Predicates), modifier)
+}
@@ -0,0 +1,77 @@
+package mutate
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/brice-74/entx/search/common"
+)
+
+type Option func(*Config)
+
+// DefaultConf runs the mutations without any restriction.
+var DefaultConf = Config{}
+
+type Config struct {
+	// IsolationLevel is the isolation level of the transaction running the mutations of a request.
+	IsolationLevel sql.IsolationLevel
+	RequestTimeout time.Duration
+	// MaxMutationsPerRequest is the maximum number of mutations executed by a request.
+	MaxMutationsPerRequest int
+	// MaxEdgeIDs is the maximum number of ids attached or detached per relation.
+	MaxEdgeIDs int
+	// MaxBulkItems is the maximum number of items created by a bulk mutation.
+	MaxBulkItems int
+	// FilterConfig limits the filters selecting the rows of bulk updates and deletes.
+	FilterConfig common.FilterConfig
+}
+
+func NewConfig(opts ...Option) *Config {
+	cfg := DefaultConf
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &cfg
+}
+
+// WithIsolationLevel sets the isolation level of the mutation transactions.
+func WithIsolationLevel(level sql.IsolationLevel) Option {
+	return func(c *Config) {
+		c.IsolationLevel = level
+	}
+}
+
+// WithRequestTimeout sets the global request timeout.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.RequestTimeout = d
+	}
+}
+
+// WithMaxMutationsPerRequest sets the maximum number of mutations per request.
+func WithMaxMutationsPerRequest(max int) Option {
+	return func(c *Config) {
+		c.MaxMutationsPerRequest = max
+	}
+}
+
+// WithMaxEdgeIDs sets the maximum number of ids attached or detached per relation.
+func WithMaxEdgeIDs(max int) Option {
+	return func(c *Config) {
+		c.MaxEdgeIDs = max
+	}
+}
+
+// WithMaxBulkItems sets the maximum number of items created by a bulk mutation.
+func WithMaxBulkItems(max int) Option {
+	return func(c *Config) {
+		c.MaxBulkItems = max
+	}
+}
+
+// WithFilterConfig sets the limits of the filters of bulk updates and deletes.
+func WithFilterConfig(cfg common.FilterConfig) Option {
+	return func(c *Config) {
+		c.FilterConfig = cfg
+	}
+}
@@ -0,0 +1,536 @@
+// Package mutate creates, updates, upserts and deletes the entities of an entx graph
+// from declarative inputs, attaching and detaching their relations through the graph bridges.
+package mutate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	stdsql "database/sql"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/common"
+)
+
+type ValidationError = common.ValidationError
+type QueryBuildError = common.QueryBuildError
+type ExecError = common.ExecError
+
+type SearchResponse = common.SearchResponse
+type SearchesResponse = common.SearchesResponse
+
+type MutationPolicy = common.MutationPolicy
+type MutationModifier = common.MutationModifier
+type MutationOp = common.MutationOp
+
+const OpMutationCreate = common.OpMutationCreate
+const OpMutationUpdate = common.OpMutationUpdate
+const OpMutationDelete = common.OpMutationDelete
+const OpMutationAttach = common.OpMutationAttach
+const OpMutationDetach = common.OpMutationDetach
+
+var (
+	ErrNodeMissing      = "node named %s not found"
+	ErrFieldMissing     = "field %s not found in node %s"
+	ErrRelationMissing  = "relation %s not found in node %s"
+	ErrMatchNotFound    = "no %s entity matches %v"
+	ErrMatchAmbiguous   = "several %s entities match %v"
+	ErrFieldValueType   = "field %q of type %s cannot be set with a %T value"
+	ErrFieldNotNullable = "field %q is neither optional nor nillable and cannot be set to null"
+	ErrFieldImmutable   = "field %q cannot be updated"
+	ErrFieldRequired    = "field %q is required on creation"
+	ErrFieldEnumValue   = "value %q is not allowed for enum field %q, expected one of %q"
+)
+
+type Op string
+
+const (
+	OpCreate Op = "create"
+	OpUpdate Op = "update"
+	// OpUpsert updates the matched entity, or creates it from the match and the fields.
+	OpUpsert Op = "upsert"
+	OpDelete Op = "delete"
+)
+
+func (op Op) IsValid() bool {
+	switch op {
+	case OpCreate, OpUpdate, OpUpsert, OpDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Edge attaches and detaches the entities of a relation, identified by their ids.
+// The entity of a unique relation is replaced by Attach and removed by Detach.
+type Edge struct {
+	Attach []any `json:"attach,omitempty"`
+	Detach []any `json:"detach,omitempty"`
+}
+
+type NamedMutation struct {
+	Key string `json:"key"`
+	TargetedMutation
+}
+
+type TargetedMutation struct {
+	Node string `json:"node"`
+	Op   Op     `json:"op"`
+	// Match identifies the entity of update, upsert and delete operations,
+	// by its primary key or by any set of fields matching a single entity.
+	Match  map[string]any   `json:"match,omitempty"`
+	Fields map[string]any   `json:"fields,omitempty"`
+	Edges  map[string]*Edge `json:"edges,omitempty"`
+}
+
+// Execute runs the mutation in its own transaction and returns the mutated entity.
+func (m *TargetedMutation) Execute(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *Config,
+) (*SearchResponse, error) {
+	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
+	defer cancel()
+
+	if err := m.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	build, err := m.Build(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := search.WithTx(ctx, client, &stdsql.TxOptions{Isolation: cfg.IsolationLevel}, build.Execute)
+	if err != nil {
+		return nil, execError("TargetedMutation.execute", err)
+	}
+	return res, nil
+}
+
+func (m *TargetedMutation) Validate(cfg *Config) error {
+	if !m.Op.IsValid() {
+		return &ValidationError{
+			Rule: "MutationOp",
+			Err:  fmt.Errorf("invalid operation %q, expected one of create, update, upsert or delete", m.Op),
+		}
+	}
+	switch hasMatch := len(m.Match) > 0; {
+	case m.Op == OpCreate && hasMatch:
+		return &ValidationError{
+			Rule: "MutationMatchNotAllowed",
+			Err:  errors.New("match is not allowed on a create operation"),
+		}
+	case m.Op != OpCreate && !hasMatch:
+		return &ValidationError{
+			Rule: "MutationMatchRequired",
+			Err:  fmt.Errorf("match is required on a %s operation", m.Op),
+		}
+	}
+	if m.Op == OpDelete && (len(m.Fields) > 0 || len(m.Edges) > 0) {
+		return &ValidationError{
+			Rule: "MutationDeleteValues",
+			Err:  errors.New("fields and edges are not allowed on a delete operation"),
+		}
+	}
+	for name, v := range m.Match {
+		if v == nil {
+			return &ValidationError{
+				Rule: "MutationMatchValue",
+				Err:  fmt.Errorf("match on field %q cannot be null", name),
+			}
+		}
+	}
+	return validateEdges(cfg, m.Edges)
+}
+
+func validateEdges(cfg *Config, edges map[string]*Edge) error {
+	for name, e := range edges {
+		if e == nil {
+			continue
+		}
+		if count := len(e.Attach) + len(e.Detach); cfg.MaxEdgeIDs != 0 && count > cfg.MaxEdgeIDs {
+			return &ValidationError{
+				Rule: "MaxEdgeIDs",
+				Err:  fmt.Errorf("found %d ids on relation %q, but the maximum allowed is %d", count, name, cfg.MaxEdgeIDs),
+			}
+		}
+	}
+	return nil
+}
+
+// Build resolves the node, the fields and the relations of the mutation,
+// and converts their values to the types of the ent mutations.
+func (m *TargetedMutation) Build(graph entx.Graph) (*MutationBuild, error) {
+	node, found := graph[m.Node]
+	if !found {
+		return nil, &ValidationError{
+			Rule: "UnknownNode",
+			Err:  fmt.Errorf(ErrNodeMissing, m.Node),
+		}
+	}
+
+	build := &MutationBuild{Node: node, Op: m.Op, Input: m}
+	var err error
+	if build.Match, err = buildValues(node, m.Match, false); err != nil {
+		return nil, err
+	}
+	if build.Fields, err = buildValues(node, m.Fields, m.Op == OpUpdate); err != nil {
+		return nil, err
+	}
+	if build.Edges, err = buildEdges(node, m.Edges); err != nil {
+		return nil, err
+	}
+	if m.Op == OpCreate {
+		if err := checkRequired(node, build.Fields, build.Edges); err != nil {
+			return nil, err
+		}
+	}
+	return build, nil
+}
+
+// FieldValue is a field of a mutation with its converted value, nil clearing the field.
+type FieldValue struct {
+	Field *entx.Field
+	Value any
+}
+
+type EdgeBuild struct {
+	Name   string
+	Bridge entx.Bridge
+	Edge
+}
+
+type MutationBuild struct {
+	Node entx.Node
+	Op   Op
+	// Input is given to the mutation policy of the node.
+	Input  any
+	Match  []*FieldValue
+	Fields []*FieldValue
+	Edges  []*EdgeBuild
+}
+
+// Execute runs the mutation with the client, which is expected to be transactional.
+func (build *MutationBuild) Execute(ctx context.Context, client entx.Client) (*SearchResponse, error) {
+	ec, err := client.GetEntityClient(build.Node)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entity entx.Entity
+		count  = 1
+	)
+	switch build.Op {
+	case OpCreate:
+		entity, err = build.create(ctx, ec, build.Fields)
+	case OpUpdate:
+		if entity, err = build.lookup(ctx, client, true); err == nil {
+			entity, err = build.update(ctx, client, ec, entity, build.Fields)
+		}
+	case OpUpsert:
+		if entity, err = build.lookup(ctx, client, false); err == nil {
+			if entity == nil {
+				fields := slices.Concat(build.Match, build.Fields)
+				if err = checkRequired(build.Node, fields, build.Edges); err == nil {
+					entity, err = build.create(ctx, ec, fields)
+				}
+			} else {
+				entity, err = build.update(ctx, client, ec, entity, mutableFields(build.Node, build.Fields))
+			}
+		}
+	case OpDelete:
+		var modifier func(*sql.Selector)
+		if modifier, err = enforce(ctx, build.Node, build.Input, OpMutationDelete, nil); err != nil {
+			break
+		}
+		preds := []func(*sql.Selector){build.matchPredicate()}
+		if modifier != nil {
+			preds = append(preds, modifier)
+		}
+		if entity, err = build.lookup(ctx, client, true, preds[1:]...); err == nil {
+			count, err = ec.Delete().Predicate(preds...).Exec(ctx)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchResponse{
+		Data: []entx.Entity{entity},
+		Meta: &common.MetaSearchResponse{Count: count},
+	}, nil
+}
+
+func (build *MutationBuild) create(ctx context.Context, ec entx.EntityClient, fields []*FieldValue) (entx.Entity, error) {
+	c, err := newCreate(ctx, ec, build.Node, build.Input, fields, build.Edges)
+	if err != nil {
+		return nil, err
+	}
+	return c.Save(ctx)
+}
+
+func (build *MutationBuild) update(
+	ctx context.Context,
+	client entx.Client,
+	ec entx.EntityClient,
+	entity entx.Entity,
+	fields []*FieldValue,
+) (entx.Entity, error) {
+	u := ec.UpdateOne(entity)
+	if err := setFields(u.Mutation(), fields); err != nil {
+		return nil, err
+	}
+	if err := applyEdges(ctx, build.Node, build.Input, u.Mutation(), build.Edges); err != nil {
+		return nil, err
+	}
+	modifier, err := enforce(ctx, build.Node, build.Input, OpMutationUpdate, u.Mutation())
+	if err != nil {
+		return nil, err
+	}
+	// the rows restricted by the policy are handled as not matched
+	if modifier != nil {
+		if _, err := build.lookup(ctx, client, true, modifier); err != nil {
+			return nil, err
+		}
+	}
+	return u.Save(ctx)
+}
+
+// newCreate returns a create builder of the node with the fields and the edges applied,
+// once allowed by the mutation policy of the node.
+func newCreate(
+	ctx context.Context,
+	ec entx.EntityClient,
+	node entx.Node,
+	input any,
+	fields []*FieldValue,
+	edges []*EdgeBuild,
+) (entx.Create, error) {
+	c := ec.Create()
+	pks := node.PKs()
+	for _, fv := range fields {
+		if len(pks) == 1 && fv.Field == pks[0] {
+			if err := c.SetID(fv.Value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if fv.Value == nil {
+			continue
+		}
+		if err := c.Mutation().SetField(fv.Field.Name, fv.Value); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEdges(ctx, node, input, c.Mutation(), edges); err != nil {
+		return nil, err
+	}
+	if _, err := enforce(ctx, node, input, OpMutationCreate, c.Mutation()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// setFields sets the fields of an update mutation, nil values clearing them.
+func setFields(m ent.Mutation, fields []*FieldValue) error {
+	for _, fv := range fields {
+		var err error
+		if fv.Value == nil {
+			err = m.ClearField(fv.Field.Name)
+		} else {
+			err = m.SetField(fv.Field.Name, fv.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEdges detaches then attaches the ids of the edges allowed by the mutation policy of the node.
+func applyEdges(ctx context.Context, node entx.Node, input any, m ent.Mutation, edges []*EdgeBuild) error {
+	for _, e := range edges {
+		for _, step := range []struct {
+			action MutationOp
+			ids    []any
+			apply  func(ent.Mutation, ...any) error
+		}{
+			{OpMutationDetach, e.Detach, e.Bridge.Detach},
+			{OpMutationAttach, e.Attach, e.Bridge.Attach},
+		} {
+			if len(step.ids) == 0 {
+				continue
+			}
+			mod := &MutationModifier{Mutation: m, Action: step.action, Node: node, Input: input, Edge: e.Name, IDs: step.ids}
+			if _, err := common.EnforceMutationPolicy(ctx, mod); err != nil {
+				return err
+			}
+			if len(mod.IDs) == 0 {
+				continue
+			}
+			if err := step.apply(m, mod.IDs...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// enforce evaluates the mutation policy of the node, m being nil on delete.
+func enforce(ctx context.Context, node entx.Node, input any, action MutationOp, m ent.Mutation) (func(*sql.Selector), error) {
+	return common.EnforceMutationPolicy(ctx, &MutationModifier{Mutation: m, Action: action, Node: node, Input: input})
+}
+
+// lookup returns the entity matched by the mutation, or nil if none is found and not required.
+func (build *MutationBuild) lookup(
+	ctx context.Context,
+	client entx.Client,
+	required bool,
+	preds ...func(*sql.Selector),
+) (entx.Entity, error) {
+	entities, err := build.Node.NewQuery(client).
+		Predicate(slices.Concat(preds, []func(*sql.Selector){build.matchPredicate(), func(s *sql.Selector) { s.Limit(2) }})...).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch len(entities) {
+	case 0:
+		if required {
+			return nil, &ValidationError{
+				Rule: "MutationMatchNotFound",
+				Err:  fmt.Errorf(ErrMatchNotFound, build.Node.Name(), build.matchValues()),
+			}
+		}
+		return nil, nil
+	case 1:
+		return entities[0], nil
+	default:
+		return nil, &ValidationError{
+			Rule: "MutationMatchAmbiguous",
+			Err:  fmt.Errorf(ErrMatchAmbiguous, build.Node.Name(), build.matchValues()),
+		}
+	}
+}
+
+func (build *MutationBuild) matchPredicate() func(*sql.Selector) {
+	preds := make([]func(*sql.Selector), len(build.Match))
+	for i, fv := range build.Match {
+		preds[i] = sql.FieldEQ(fv.Field.StorageName, fv.Value)
+	}
+	return entx.CombinePredicates(preds...)
+}
+
+func (build *MutationBuild) matchValues() map[string]any {
+	values := make(map[string]any, len(build.Match))
+	for _, fv := range build.Match {
+		values[fv.Field.Name] = fv.Value
+	}
+	return values
+}
+
+// mutableFields drops the fields an update cannot change, which an upsert only sets on creation.
+func mutableFields(node entx.Node, fields []*FieldValue) []*FieldValue {
+	return slices.DeleteFunc(slices.Clone(fields), func(fv *FieldValue) bool {
+		return isImmutable(node, fv.Field)
+	})
+}
+
+func isImmutable(node entx.Node, f *entx.Field) bool {
+	return f.Immutable || slices.Contains(node.PKs(), f)
+}
+
+type NamedMutations []*NamedMutation
+
+// Execute runs the mutations in order inside a single transaction,
+// the responses being keyed by the mutation keys.
+func (mutations NamedMutations) Execute(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *Config,
+) (SearchesResponse, error) {
+	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
+	defer cancel()
+
+	if err := mutations.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	builds, err := mutations.Build(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := search.WithTx(ctx, client, &stdsql.TxOptions{Isolation: cfg.IsolationLevel},
+		func(ctx context.Context, tx entx.Client) (SearchesResponse, error) {
+			res := make(SearchesResponse, len(builds))
+			for i, build := range builds {
+				r, err := build.Execute(ctx, tx)
+				if err != nil {
+					return nil, err
+				}
+				res[mutations[i].Key] = r
+			}
+			return res, nil
+		})
+	if err != nil {
+		return nil, execError("NamedMutations.execute", err)
+	}
+	return res, nil
+}
+
+func (mutations NamedMutations) Validate(cfg *Config) error {
+	if count := len(mutations); cfg.MaxMutationsPerRequest != 0 && count > cfg.MaxMutationsPerRequest {
+		return &ValidationError{
+			Rule: "MaxMutationsPerRequest",
+			Err:  fmt.Errorf("found %d mutations, but the maximum allowed is %d", count, cfg.MaxMutationsPerRequest),
+		}
+	}
+	keys := make(map[string]struct{}, len(mutations))
+	for i, m := range mutations {
+		if m.Key == "" {
+			m.Key = fmt.Sprintf("mutation_%d", i+1)
+		}
+		if _, found := keys[m.Key]; found {
+			return &ValidationError{
+				Rule: "MutationKeyDuplicate",
+				Err:  fmt.Errorf("mutation key %q is used more than once", m.Key),
+			}
+		}
+		keys[m.Key] = struct{}{}
+		if err := m.TargetedMutation.Validate(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mutations NamedMutations) Build(graph entx.Graph) ([]*MutationBuild, error) {
+	builds := make([]*MutationBuild, len(mutations))
+	for i, m := range mutations {
+		build, err := m.TargetedMutation.Build(graph)
+		if err != nil {
+			return nil, err
+		}
+		builds[i] = build
+	}
+	return builds, nil
+}
+
+// execError keeps the validation errors raised while executing, such as an unmatched entity.
+func execError(op string, err error) error {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr
+	}
+	return &ExecError{Op: op, Err: err}
+}
@@ -0,0 +1,178 @@
+package mutate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/dsl"
+)
+
+// buildValues checks the values against the field metadata of the node and converts them,
+// update rejecting the fields that cannot be changed once created.
+func buildValues(node entx.Node, values map[string]any, update bool) ([]*FieldValue, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	res := make([]*FieldValue, 0, len(values))
+	for name, v := range values {
+		f := node.FieldByName(name)
+		if f == nil {
+			return nil, &QueryBuildError{
+				Op:  "TargetedMutation.Build",
+				Err: fmt.Errorf(ErrFieldMissing, name, node.Name()),
+			}
+		}
+		if update && isImmutable(node, f) {
+			return nil, &ValidationError{
+				Rule: "MutationFieldImmutable",
+				Err:  fmt.Errorf(ErrFieldImmutable, name),
+			}
+		}
+		converted, err := buildValue(f, v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &FieldValue{Field: f, Value: converted})
+	}
+	// map iteration is random, the mutations are applied in a stable order
+	slices.SortFunc(res, func(a, b *FieldValue) int { return strings.Compare(a.Field.Name, b.Field.Name) })
+	return res, nil
+}
+
+// checkRequired rejects the creation of an entity missing a field that is neither optional
+// nor defaulted, the foreign key of a unique relation being set by attaching the relation.
+// A single primary key may be generated by the database and is never required.
+func checkRequired(node entx.Node, fields []*FieldValue, edges []*EdgeBuild) error {
+	set := make(map[string]bool, len(fields)+len(edges))
+	for _, fv := range fields {
+		set[fv.Field.StorageName] = true
+	}
+	for _, e := range edges {
+		if len(e.Attach) > 0 && isUnique(e.Bridge) {
+			set[e.Bridge.RelInfos().FinalLeftField] = true
+		}
+	}
+	pks := node.PKs()
+	for _, f := range node.Fields() {
+		if f.Optional || f.Default || set[f.StorageName] || len(pks) == 1 && f == pks[0] {
+			continue
+		}
+		return &ValidationError{
+			Rule: "MutationFieldRequired",
+			Err:  fmt.Errorf(ErrFieldRequired, f.Name),
+		}
+	}
+	return nil
+}
+
+// isUnique reports whether the relation holds a single entity.
+func isUnique(b entx.Bridge) bool {
+	rel := b.RelInfos().RelType
+	return rel == sqlgraph.M2O || rel == sqlgraph.O2O
+}
+
+func buildValue(f *entx.Field, v any) (any, error) {
+	if v == nil {
+		if !f.IsNullable() {
+			return nil, &ValidationError{
+				Rule: "MutationFieldNotNullable",
+				Err:  fmt.Errorf(ErrFieldNotNullable, f.Name),
+			}
+		}
+		return nil, nil
+	}
+	if err := checkFieldValue(f, v); err != nil {
+		return nil, err
+	}
+	converted, err := entx.ConvertValue(f, v)
+	if err != nil {
+		return nil, &ValidationError{
+			Rule: "MutationValueType",
+			Err:  err,
+		}
+	}
+	return converted, nil
+}
+
+func checkFieldValue(f *entx.Field, value any) error {
+	var ok bool
+	switch f.Type {
+	case entx.TypeInt:
+		ok = dsl.IsInteger(value)
+	case entx.TypeFloat:
+		ok = dsl.IsNumber(value)
+	case entx.TypeString, entx.TypeUUID:
+		ok = dsl.IsString(value)
+	case entx.TypeBool:
+		_, ok = value.(bool)
+	case entx.TypeTime:
+		switch value.(type) {
+		case string, time.Time:
+			ok = true
+		}
+	case entx.TypeEnum:
+		s, isString := value.(string)
+		if isString && !f.HasEnum(s) {
+			return &ValidationError{
+				Rule: "MutationEnumValue",
+				Err:  fmt.Errorf(ErrFieldEnumValue, s, f.Name, f.Enums),
+			}
+		}
+		ok = isString
+	default:
+		ok = true
+	}
+	if !ok {
+		return &ValidationError{
+			Rule: "MutationValueType",
+			Err:  fmt.Errorf(ErrFieldValueType, f.Name, f.Type, value),
+		}
+	}
+	return nil
+}
+
+// buildEdges resolves the relations of the node and checks the ids against the primary key of their entities.
+func buildEdges(node entx.Node, edges map[string]*Edge) ([]*EdgeBuild, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	res := make([]*EdgeBuild, 0, len(edges))
+	for name, e := range edges {
+		if e == nil {
+			continue
+		}
+		bridge := node.Bridge(name)
+		if bridge == nil {
+			return nil, &QueryBuildError{
+				Op:  "TargetedMutation.Build",
+				Err: fmt.Errorf(ErrRelationMissing, name, node.Name()),
+			}
+		}
+		if len(e.Attach) > 1 && isUnique(bridge) {
+			return nil, &ValidationError{
+				Rule: "MutationEdgeUnique",
+				Err:  fmt.Errorf(entx.ErrAttachUnique, name, len(e.Attach)),
+			}
+		}
+		if pks := bridge.Child().PKs(); len(pks) == 1 {
+			for _, id := range slices.Concat(e.Attach, e.Detach) {
+				if id == nil {
+					return nil, &ValidationError{
+						Rule: "MutationEdgeID",
+						Err:  fmt.Errorf("relation %q cannot be attached or detached with a null id", name),
+					}
+				}
+				if err := checkFieldValue(pks[0], id); err != nil {
+					return nil, err
+				}
+			}
+		}
+		res = append(res, &EdgeBuild{Name: name, Bridge: bridge, Edge: *e})
+	}
+	slices.SortFunc(res, func(a, b *EdgeBuild) int { return strings.Compare(a.Name, b.Name) })
+	return res, nil
+}
@@ -23,21 +23,31 @@ func (q *QueryBundle) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	if err := q.ValidateAndPreprocessFinal(cfg); err != nil {
+	cost, err := validateAndCost(ctx, cfg,
+		func() error { return q.ValidateAndPreprocessFinal(cfg) },
+		func() error { return q.CheckTypes(graph) },
+		func(w *CostWeights) int { return q.Cost(graph, w) },
+		q.lowerIncludeLimits,
+	)
+	if err != nil {
 		return nil, err
 	}
 
-	build, err := q.BuildClassified(ctx, cfg, graph)
+	build, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (*ClassifiedBuilds, error) {
+		return q.BuildClassified(ctx, cfg, graph)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := build.Execute(ctx, client, cfg)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*GroupResponse, error) {
+		return build.Execute(ctx, client, cfg)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	return withGroupCost(res, cost), nil
 }
 
 func (q *QueryBundle) BuildClassified(
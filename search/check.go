@@ -0,0 +1,74 @@
+package search
+
+import (
+	"github.com/brice-74/entx"
+)
+
+// CheckTypes verifies that the types of the fields read by preprocessed query options
+// support their operators, aggregates and sorts.
+func (qo *QueryOptions) CheckTypes(node entx.Node) error {
+	if err := qo.Filters.CheckTypes(node); err != nil {
+		return err
+	}
+	if err := qo.Sorts.CheckTypes(node); err != nil {
+		return err
+	}
+	if err := qo.Aggregates.CheckTypes(node); err != nil {
+		return err
+	}
+	return qo.Includes.CheckTypes(node)
+}
+
+func (q *TargetedQuery) CheckTypes(graph entx.Graph) error {
+	node := graph[q.From]
+	if node == nil {
+		return nil
+	}
+	return q.QueryOptions.CheckTypes(node)
+}
+
+func (queries NamedQueries) CheckTypes(graph entx.Graph) error {
+	for _, q := range queries {
+		if err := q.CheckTypes(graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (group *QueryGroup) CheckTypes(graph entx.Graph) error {
+	if err := group.Searches.CheckTypes(graph); err != nil {
+		return err
+	}
+	if err := group.Aggregates.CheckTypes(graph); err != nil {
+		return err
+	}
+	if err := group.Grouped.CheckTypes(graph); err != nil {
+		return err
+	}
+	return group.Histograms.CheckTypes(graph)
+}
+
+func (groups TxQueryGroups) CheckTypes(graph entx.Graph) error {
+	for _, group := range groups {
+		if err := group.CheckTypes(graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *QueryBundle) CheckTypes(graph entx.Graph) error {
+	if err := q.QueryGroup.CheckTypes(graph); err != nil {
+		return err
+	}
+	if err := q.Transactions.CheckTypes(graph); err != nil {
+		return err
+	}
+	for _, aggregates := range q.ParallelGroups {
+		if err := aggregates.CheckTypes(graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
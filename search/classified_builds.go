@@ -15,6 +15,7 @@ type ClassifiedBuilds struct {
 	Searches          []*NamedQueryBuild
 	Aggregates        []*common.ScalarQuery
 	GroupedAggregates [][]*common.ScalarQuery
+	Buckets           []*common.BucketQuery
 }
 
 func (builds *ClassifiedBuilds) Execute(
@@ -25,15 +26,14 @@ func (builds *ClassifiedBuilds) Execute(
 	s := builds.calculateSizes()
 
 	var (
-		paginations   map[string]*common.PaginateInfos
-		response      common.GroupResponseSync
-		scalarQueries = make([]*common.ScalarQuery, s.TotalScalarQueries)
+		paginations map[string]*common.PaginateInfos
+		response    common.GroupResponseSync
 	)
 	if s.NumPaginated > 0 {
 		paginations = make(map[string]*common.PaginateInfos, s.NumPaginated)
 	}
-	if s.TotalScalarQueries > 0 {
-		response.Aggregates = *common.NewMapSync(make(map[string]any, s.TotalScalarQueries))
+	if size := s.TotalScalarQueries + s.NumBuckets + s.NumTxAggregates; size > 0 {
+		response.Aggregates = *common.NewMapSync(make(map[string]any, size))
 	}
 	if s.NumSearches > 0 {
 		response.Searches = *common.NewMapSync(make(map[string]*SearchResponse, s.NumSearches))
@@ -42,10 +42,9 @@ func (builds *ClassifiedBuilds) Execute(
 	errg, wgctx := errgroup.WithContext(ctx)
 	errg.SetLimit(cfg.MaxParallelWorkersPerRequest)
 
-	idx := 0
 	for _, build := range builds.Searches {
 		errg.Go(func() error {
-			res, err := build.ExecuteSearchOnly(wgctx, client, cfg)
+			res, err := build.ExecuteSearchOnly(common.ContextWithSearchKey(wgctx, build.Key), client, cfg)
 			if err != nil {
 				return err
 			}
@@ -55,6 +54,37 @@ func (builds *ClassifiedBuilds) Execute(
 
 		if build.IsPaginated() {
 			paginations[build.Key] = build.Paginate
+		}
+	}
+
+	common.ExecuteScalarGroupsAsync(wgctx, errg, client, cfg, &response.Aggregates, builds.scalarGroups(cfg, s)...)
+
+	common.ExecuteBucketQueriesAsync(wgctx, errg, client, &response.Aggregates, builds.Buckets...)
+
+	builds.Transactions.Execute(wgctx, client, cfg, errg, &response)
+
+	if err := errg.Wait(); err != nil {
+		return nil, err
+	}
+
+	if err := common.AttachPaginationAndCleanSync(&response.Searches, &response.Aggregates, paginations); err != nil {
+		return nil, err
+	}
+
+	return (&response).UnsafeResponse(), nil
+}
+
+// scalarGroups returns the scalar queries executed together, the pagination counts and
+// the aggregates being batched in chunks.
+func (builds *ClassifiedBuilds) scalarGroups(cfg *Config, s *BuildSizes) [][]*common.ScalarQuery {
+	if s.TotalScalarQueries == 0 {
+		return nil
+	}
+
+	scalarQueries := make([]*common.ScalarQuery, s.TotalScalarQueries)
+	idx := 0
+	for _, build := range builds.Searches {
+		if build.IsPaginated() {
 			scalarQueries[idx] = build.Paginate.ToScalarQuery(build.Key)
 			idx++
 		}
@@ -73,23 +103,8 @@ func (builds *ClassifiedBuilds) Execute(
 		}
 	}
 
-	if s.TotalScalarQueries > 0 {
-		chunked := common.SplitInChunks(scalarQueries, cfg.ScalarQueriesChunkSize)
-		groups := common.MergeSlices(chunked, builds.GroupedAggregates)
-		common.ExecuteScalarGroupsAsync(wgctx, errg, client, cfg, &response.Aggregates, groups...)
-	}
-
-	builds.Transactions.Execute(wgctx, client, cfg, errg, &response)
-
-	if err := errg.Wait(); err != nil {
-		return nil, err
-	}
-
-	if err := common.AttachPaginationAndCleanSync(&response.Searches, &response.Aggregates, paginations); err != nil {
-		return nil, err
-	}
-
-	return (&response).UnsafeResponse(), nil
+	chunked := common.SplitInChunks(scalarQueries, cfg.ScalarQueriesChunkSize)
+	return common.MergeSlices(chunked, builds.GroupedAggregates)
 }
 
 type BuildSizes struct {
@@ -97,6 +112,8 @@ type BuildSizes struct {
 	NumPaginated       int
 	NumAggregates      int
 	NumGroupedAggs     int
+	NumBuckets         int
+	NumTxAggregates    int
 	TotalScalarQueries int
 }
 
@@ -105,6 +122,7 @@ func (b *ClassifiedBuilds) calculateSizes() *BuildSizes {
 	m.NumSearches = len(b.Searches)
 	for _, tx := range b.Transactions {
 		m.NumSearches += len(tx.Searches)
+		m.NumTxAggregates += len(tx.Aggregates) + len(tx.Buckets)
 	}
 	for _, s := range b.Searches {
 		if s.IsPaginated() {
@@ -117,6 +135,8 @@ func (b *ClassifiedBuilds) calculateSizes() *BuildSizes {
 		m.NumGroupedAggs += len(grp)
 	}
 
+	m.NumBuckets = len(b.Buckets)
+
 	m.TotalScalarQueries = m.NumPaginated + m.NumAggregates + m.NumGroupedAggs
 	return &m
 }
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"golang.org/x/sync/errgroup"
+)
+
+// Bucket is one group of a grouped aggregate, keys are indexed by grouped field
+// and values by aggregate alias.
+type Bucket struct {
+	Keys   map[string]any `json:"keys"`
+	Values map[string]any `json:"values"`
+}
+
+// BucketQuery is a query returning one row per bucket.
+// Selected columns must be the keys followed by the values.
+type BucketQuery struct {
+	Selector *sql.Selector
+	Key      string // response key of the buckets
+	KeyNames []string
+	// ValueNames are the aliases of the aggregate values.
+	ValueNames []string
+	// NewDests returns the scan destinations of a row, ordered like the selected columns.
+	NewDests func() []any
+	// Finalize optionally post-processes the scanned buckets.
+	Finalize func([]*Bucket) ([]*Bucket, error)
+}
+
+func ExecuteBucketQuery(ctx context.Context, client entx.Client, q *BucketQuery) ([]*Bucket, error) {
+	query, args := q.Selector.Query()
+	rows, err := client.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]*Bucket, 0)
+	for rows.Next() {
+		dests := q.NewDests()
+		if err := rows.Scan(dests...); err != nil {
+			return nil, err
+		}
+
+		bucket := &Bucket{
+			Keys:   make(map[string]any, len(q.KeyNames)),
+			Values: make(map[string]any, len(q.ValueNames)),
+		}
+		for i, name := range q.KeyNames {
+			if bucket.Keys[name], err = scannedValue(dests[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i, name := range q.ValueNames {
+			if bucket.Values[name], err = scannedValue(dests[len(q.KeyNames)+i]); err != nil {
+				return nil, err
+			}
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if q.Finalize != nil {
+		return q.Finalize(buckets)
+	}
+	return buckets, nil
+}
+
+// scannedValue unwraps a scan destination into a plain value.
+func scannedValue(dest any) (any, error) {
+	switch d := dest.(type) {
+	case driver.Valuer:
+		return handleValuer(d)
+	case *any:
+		if b, ok := (*d).([]byte); ok {
+			return string(b), nil
+		}
+		return *d, nil
+	default:
+		return dest, nil
+	}
+}
+
+func ExecuteBucketQueries(ctx context.Context, client entx.Client, response map[string]any, queries ...*BucketQuery) error {
+	for _, q := range queries {
+		buckets, err := ExecuteBucketQuery(ctx, client, q)
+		if err != nil {
+			return err
+		}
+		response[q.Key] = buckets
+	}
+	return nil
+}
+
+func ExecuteBucketQueriesAsync(
+	ctx context.Context,
+	wg *errgroup.Group,
+	client entx.Client,
+	response *MapSync[string, any],
+	queries ...*BucketQuery,
+) {
+	for _, q := range queries {
+		wg.Go(func() error {
+			buckets, err := ExecuteBucketQuery(ctx, client, q)
+			if err != nil {
+				return err
+			}
+			response.Set(q.Key, buckets)
+			return nil
+		})
+	}
+}
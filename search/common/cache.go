@@ -0,0 +1,278 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"entgo.io/ent"
+	"github.com/brice-74/entx"
+)
+
+// Cache stores the results of searches and scalar queries, each entry being tagged
+// with the names of the nodes it reads so that Invalidate drops it once one of them is mutated.
+// Values are shared between requests and must not be modified, a store living outside
+// the process has to encode them itself, the data of search responses holding ent entities.
+type Cache interface {
+	Get(ctx context.Context, key string) (any, bool)
+	Set(ctx context.Context, key string, value any, ttl time.Duration, nodes []string)
+	Invalidate(ctx context.Context, nodes ...string)
+}
+
+type CacheConfig struct {
+	Store Cache
+	// DefaultTTL is the time to live of the entries, zero disabling the cache of the nodes without their own TTL.
+	DefaultTTL time.Duration
+	// NodeTTLs overrides DefaultTTL per node name, zero or a negative TTL disabling the cache of the node.
+	NodeTTLs map[string]time.Duration
+	// Scope returns the policy context of a request, such as the user or the tenant, which is part of the keys.
+	// The nodes having a policy are not cached without it.
+	Scope func(context.Context) string
+}
+
+// CacheEntry identifies a cacheable result, its TTL being the lowest of its nodes.
+type CacheEntry struct {
+	Key   string
+	Nodes []string
+	TTL   time.Duration
+}
+
+func (c *CacheConfig) TTL(node string) time.Duration {
+	if ttl, ok := c.NodeTTLs[node]; ok {
+		return ttl
+	}
+	return c.DefaultTTL
+}
+
+// NewEntry returns the entry of a result of the kind computed from the validated input and reading the nodes,
+// or nil when the cache is disabled for one of them.
+func (c *CacheConfig) NewEntry(ctx context.Context, kind string, input any, nodes []entx.Node) *CacheEntry {
+	if c.Store == nil || len(nodes) == 0 || IsTxContext(ctx) {
+		return nil
+	}
+	entry := &CacheEntry{Nodes: make([]string, len(nodes))}
+	for i, n := range nodes {
+		if n.Policy() != nil && c.Scope == nil {
+			return nil
+		}
+		ttl := c.TTL(n.Name())
+		if ttl <= 0 {
+			return nil
+		}
+		if i == 0 || ttl < entry.TTL {
+			entry.TTL = ttl
+		}
+		entry.Nodes[i] = n.Name()
+	}
+
+	var scope string
+	if c.Scope != nil {
+		scope = c.Scope(ctx)
+	}
+	// encoded maps have sorted keys, equal inputs give equal hashes
+	b, err := json.Marshal(struct {
+		Kind  string   `json:"kind"`
+		Scope string   `json:"scope"`
+		Nodes []string `json:"nodes"`
+		Input any      `json:"input"`
+	}{kind, scope, entry.Nodes, input})
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(b)
+	entry.Key = kind + ":" + hex.EncodeToString(sum[:])
+	return entry
+}
+
+// ScalarEntry returns the entry of a scalar query, keyed by its SQL, or nil when its nodes are unknown.
+func (c *CacheConfig) ScalarEntry(ctx context.Context, q *ScalarQuery) *CacheEntry {
+	if c.Store == nil || len(q.Nodes) == 0 {
+		return nil
+	}
+	query, args := q.Selector.Query()
+	return c.NewEntry(ctx, "scalar", []any{query, args}, q.Nodes)
+}
+
+// ExecuteCached returns the value cached for the entry, or runs fn and caches its result.
+// A nil entry or a transaction context runs fn without cache.
+func ExecuteCached[T any](ctx context.Context, c *CacheConfig, entry *CacheEntry, fn func() (T, error)) (T, error) {
+	if entry == nil || IsTxContext(ctx) {
+		return fn()
+	}
+	if v, ok := c.Store.Get(ctx, entry.Key); ok {
+		if res, ok := v.(T); ok {
+			return res, nil
+		}
+	}
+	res, err := fn()
+	if err != nil {
+		return res, err
+	}
+	c.Store.Set(ctx, entry.Key, res, entry.TTL, entry.Nodes)
+	return res, nil
+}
+
+// cachedScalars sets the cached values of the scalars in the response,
+// and returns the scalars left to execute with the entries caching their results.
+func (c *CacheConfig) cachedScalars(ctx context.Context, response *MapSync[string, any], scalars []*ScalarQuery) ([]*ScalarQuery, []*CacheEntry) {
+	if c.Store == nil {
+		return scalars, make([]*CacheEntry, len(scalars))
+	}
+	var (
+		missed  = make([]*ScalarQuery, 0, len(scalars))
+		entries = make([]*CacheEntry, 0, len(scalars))
+	)
+	for _, q := range scalars {
+		entry := c.ScalarEntry(ctx, q)
+		if entry != nil {
+			if v, ok := c.Store.Get(ctx, entry.Key); ok {
+				response.Set(q.Key, v)
+				continue
+			}
+		}
+		missed = append(missed, q)
+		entries = append(entries, entry)
+	}
+	return missed, entries
+}
+
+func (c *CacheConfig) storeScalars(ctx context.Context, response *MapSync[string, any], scalars []*ScalarQuery, entries []*CacheEntry) {
+	for i, q := range scalars {
+		if entry := entries[i]; entry != nil {
+			if v, ok := response.Get(q.Key); ok {
+				c.Store.Set(ctx, entry.Key, v, entry.TTL, entry.Nodes)
+			}
+		}
+	}
+}
+
+type txToken struct{}
+
+// ContextWithTx marks the context of a transaction, whose reads are not cached.
+func ContextWithTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txToken{}, struct{}{})
+}
+
+func IsTxContext(ctx context.Context) bool {
+	_, ok := ctx.Value(txToken{}).(struct{})
+	return ok
+}
+
+// DependentNodes returns the root node and the nodes reached through the relations named
+// in the "relation" and "field" paths of the input. A relation name found on several nodes
+// adds all of their children, which may invalidate more entries than needed but never misses one.
+// The pivot rows read through the "pivot" relation or the "pivot" option of an include add
+// the edge schema node of the named M2M relations.
+func DependentNodes(root entx.Node, input any) ([]entx.Node, error) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := json.Unmarshal(b, &tree); err != nil {
+		return nil, err
+	}
+	segments := make(map[string]struct{})
+	collectPathSegments(tree, segments)
+
+	reachable := []entx.Node{root}
+	seen := map[string]struct{}{root.Name(): {}}
+	for i := 0; i < len(reachable); i++ {
+		for _, b := range reachable[i].Bridges() {
+			if child := b.Child(); child != nil {
+				if _, ok := seen[child.Name()]; !ok {
+					seen[child.Name()] = struct{}{}
+					reachable = append(reachable, child)
+				}
+			}
+		}
+	}
+
+	deps := []entx.Node{root}
+	added := map[string]struct{}{root.Name(): {}}
+	for _, n := range reachable {
+		for name, b := range n.Bridges() {
+			if _, ok := segments[name]; !ok {
+				continue
+			}
+			nodes := []entx.Node{b.Child()}
+			if _, ok := segments[pivotSegment]; ok {
+				nodes = append(nodes, b.Pivot())
+			}
+			for _, dep := range nodes {
+				if dep == nil {
+					continue
+				}
+				if _, ok := added[dep.Name()]; !ok {
+					added[dep.Name()] = struct{}{}
+					deps = append(deps, dep)
+				}
+			}
+		}
+	}
+	slices.SortFunc(deps[1:], func(a, b entx.Node) int { return strings.Compare(a.Name(), b.Name()) })
+	return deps, nil
+}
+
+// pivotSegment names the pivot row of a related row in a path, and the include option selecting its fields.
+const pivotSegment = "pivot"
+
+func collectPathSegments(v any, segments map[string]struct{}) {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, val := range t {
+			if s, ok := val.(string); ok && (key == "relation" || key == "field") {
+				for _, seg := range strings.Split(s, ".") {
+					segments[seg] = struct{}{}
+				}
+				continue
+			}
+			if fields, ok := val.([]any); ok && key == pivotSegment && len(fields) > 0 {
+				segments[pivotSegment] = struct{}{}
+				continue
+			}
+			collectPathSegments(val, segments)
+		}
+	case []any:
+		for _, val := range t {
+			collectPathSegments(val, segments)
+		}
+	}
+}
+
+// CacheInvalidationHook returns an ent hook invalidating the entries of the mutated node
+// and of the nodes of its changed relations once a mutation succeeded.
+// Mutations run in a transaction invalidate once it committed, a rolled back one invalidating nothing.
+func CacheInvalidationHook(store Cache, graph entx.Graph) ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			v, err := next.Mutate(ctx, m)
+			if err != nil {
+				return v, err
+			}
+			nodes := []string{m.Type()}
+			node := graph[m.Type()]
+			if node != nil {
+				for _, edges := range [][]string{m.AddedEdges(), m.RemovedEdges(), m.ClearedEdges()} {
+					for _, name := range edges {
+						if b := node.Bridge(name); b != nil {
+							nodes = append(nodes, b.Child().Name())
+						}
+					}
+				}
+			}
+			invalidate := func(ctx context.Context) {
+				store.Invalidate(ctx, nodes...)
+			}
+			if txNode, ok := node.(entx.TxNode); ok && txNode.OnCommit(m, invalidate) {
+				return v, nil
+			}
+			invalidate(ctx)
+			return v, nil
+		})
+	}
+}
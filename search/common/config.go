@@ -5,12 +5,17 @@ import (
 	"time"
 
 	"entgo.io/ent/dialect"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type SortConfig struct {
 	// MaxSortRelationDepth is the maximum allowed nesting depth for sorting fields
 	// (number of relationship hops + field segments).
 	MaxSortRelationDepth int
+	// MaxSortValues is the maximum number of values of a sort on a list of values, unlimited when zero.
+	MaxSortValues int
+	*FilterConfig
 }
 
 type PageableConfig struct {
@@ -28,6 +33,7 @@ type IncludeConfig struct {
 	*FilterConfig
 	*AggregateConfig
 	*PageableConfig
+	*SortConfig
 }
 
 type AggregateConfig struct {
@@ -47,6 +53,29 @@ type FilterConfig struct {
 	// MaxRelationTotalCount is the total number of relation segments permitted
 	// across the entire filter tree.
 	MaxRelationTotalCount int
+	// MaxRecursionDepth is the maximum depth of a recursive relation, followed by filters,
+	// aggregates and includes. It is also the depth of those setting none.
+	MaxRecursionDepth int
+	// clock is bound from Config.Clock.
+	clock func() time.Time
+	// dialect is bound from Config.Dialect.
+	dialect string
+}
+
+// Now returns the reference time used to resolve relative time expressions.
+func (c *FilterConfig) Now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+// Dialect returns the SQL dialect the validated inputs are built for, empty when unbound.
+func (c *FilterConfig) Dialect() string {
+	if c == nil {
+		return ""
+	}
+	return c.dialect
 }
 
 type Option func(*Config)
@@ -62,6 +91,9 @@ var defaultConf = Config{
 		MaxLimit:     100,
 		DefaultLimit: 25,
 	},
+	FilterConfig: FilterConfig{
+		MaxRecursionDepth: 10,
+	},
 	ScalarQueriesChunkSize:       7,
 	MaxParallelWorkersPerRequest: -1,
 }
@@ -79,12 +111,24 @@ type Config struct {
 	Dialect        string
 	Transaction    TransactionConfig
 	RequestTimeout time.Duration
+	// Clock is the time source used to resolve relative time expressions such as "now-7d".
+	// Defaults to time.Now.
+	Clock func() time.Time
 	// Batch sizing
 	ScalarQueriesChunkSize       int
 	MaxParallelWorkersPerRequest int
 	// Validations
 	MaxAggregatesPerRequest int
 	MaxSearchesPerRequest   int
+	// MaxBuckets is the maximum number of buckets returned by a grouped aggregate,
+	// it is also the default bucket limit.
+	MaxBuckets int
+	// Cache stores the search responses and scalar aggregates, disabled without store.
+	Cache CacheConfig
+	// Telemetry traces the phases of the requests and records their metrics, disabled when nil.
+	Telemetry *Telemetry
+	// Cost weighs the requests and enforces the budget of their caller, disabled when nil.
+	Cost *CostConfig
 	PageableConfig
 	SortConfig
 	FilterConfig
@@ -105,10 +149,22 @@ func (cfg *Config) BindDeeply() *Config {
 	cfg.IncludeConfig.AggregateConfig = &cfg.AggregateConfig
 	cfg.IncludeConfig.FilterConfig = &cfg.FilterConfig
 	cfg.IncludeConfig.PageableConfig = &cfg.PageableConfig
+	cfg.IncludeConfig.SortConfig = &cfg.SortConfig
 	cfg.AggregateConfig.FilterConfig = &cfg.FilterConfig
+	cfg.SortConfig.FilterConfig = &cfg.FilterConfig
+	cfg.FilterConfig.clock = cfg.Clock
+	cfg.FilterConfig.dialect = cfg.Dialect
 	return cfg
 }
 
+// WithDialect sets the SQL dialect of the generated queries,
+// one of dialect.MySQL, dialect.Postgres or dialect.SQLite.
+func WithDialect(name string) Option {
+	return func(c *Config) {
+		c.Dialect = name
+	}
+}
+
 func WithTransactionConfig(cfg TransactionConfig) Option {
 	return func(c *Config) {
 		c.Transaction = cfg
@@ -126,6 +182,13 @@ func WithRequestTimeout(d time.Duration) Option {
 	}
 }
 
+// WithClock sets the time source used to resolve relative time expressions.
+func WithClock(clock func() time.Time) Option {
+	return func(c *Config) {
+		c.Clock = clock
+	}
+}
+
 // ------------------------------
 // BatchSizing
 // ------------------------------
@@ -162,6 +225,35 @@ func WithMaxSearchesPerRequest(max int) Option {
 	}
 }
 
+// WithMaxBuckets sets the maximum number of buckets returned by a grouped aggregate.
+func WithMaxBuckets(max int) Option {
+	return func(c *Config) {
+		c.MaxBuckets = max
+	}
+}
+
+// WithCache sets the store and the TTLs of the result cache.
+func WithCache(cfg CacheConfig) Option {
+	return func(c *Config) {
+		c.Cache = cfg
+	}
+}
+
+// WithTelemetry instruments the requests with OpenTelemetry, using the tracer and the meter providers.
+// A nil provider disables its signal.
+func WithTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) Option {
+	return func(c *Config) {
+		c.Telemetry = NewTelemetry(tp, mp)
+	}
+}
+
+// WithCost enables the cost model, reporting the cost of the requests and enforcing their budget.
+func WithCost(cfg CostConfig) Option {
+	return func(c *Config) {
+		c.Cost = &cfg
+	}
+}
+
 func WithPageableConfig(cfg PageableConfig) Option {
 	return func(c *Config) {
 		c.PageableConfig = cfg
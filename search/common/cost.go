@@ -0,0 +1,79 @@
+package common
+
+import (
+	"context"
+	"fmt"
+)
+
+// CostWeights are the costs of the features of a request, a zero weight ignoring its feature.
+type CostWeights struct {
+	// Search is the cost of each search query.
+	Search int
+	// RelationHop is the cost of each relation traversed by a filter, a sort, an aggregate or an include.
+	RelationHop int
+	// M2MJoin is added to RelationHop for a many-to-many relation, joined through its pivot table.
+	M2MJoin int
+	// Aggregate is the cost of each aggregate sub-select, relation count filter and pagination count.
+	Aggregate int
+	// AggregateSort is the cost of each sort on an aggregate.
+	AggregateSort int
+	// IncludeRow is the cost of each row an include may load, its depth times its limit.
+	IncludeRow int
+	// UnindexedField is the cost of each filter or sort on a field without index.
+	UnindexedField int
+}
+
+var DefaultCostWeights = CostWeights{
+	Search:         10,
+	RelationHop:    5,
+	M2MJoin:        5,
+	Aggregate:      10,
+	AggregateSort:  20,
+	IncludeRow:     1,
+	UnindexedField: 10,
+}
+
+type CostConfig struct {
+	Weights CostWeights
+	// Budget returns the maximum cost of a request for the caller of the context,
+	// zero or negative for no limit. The cost is only computed and reported without it.
+	Budget func(context.Context) int
+	// Downgrade lowers the include limits of a request exceeding its budget, halving them
+	// down to one row, instead of rejecting it at once.
+	Downgrade bool
+}
+
+// Cost is the cost of a request, reported in the meta of its response.
+type Cost struct {
+	Total  int `json:"total"`
+	Budget int `json:"budget,omitempty"`
+	// Downgraded is set when the include limits were lowered to fit the budget.
+	Downgraded bool `json:"downgraded,omitempty"`
+}
+
+var ErrCostBudgetExceeded = "request cost %d exceeds the budget of %d"
+
+// EnforceCost computes the cost of a request and checks it against the budget of the caller.
+// When the downgrade is enabled, lower is called until the request fits or it reports
+// that nothing can be lowered anymore.
+func (c *CostConfig) EnforceCost(ctx context.Context, compute func(*CostWeights) int, lower func() bool) (*Cost, error) {
+	cost := &Cost{Total: compute(&c.Weights)}
+	if c.Budget == nil {
+		return cost, nil
+	}
+	if cost.Budget = c.Budget(ctx); cost.Budget <= 0 {
+		cost.Budget = 0
+		return cost, nil
+	}
+	for c.Downgrade && cost.Total > cost.Budget && lower() {
+		cost.Total = compute(&c.Weights)
+		cost.Downgraded = true
+	}
+	if cost.Total > cost.Budget {
+		return nil, &ValidationError{
+			Rule: "CostBudgetExceeded",
+			Err:  fmt.Errorf(ErrCostBudgetExceeded, cost.Total, cost.Budget),
+		}
+	}
+	return cost, nil
+}
@@ -0,0 +1,256 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/brice-74/entx"
+)
+
+type CursorResponse struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasNext    bool   `json:"has_next"`
+}
+
+// CursorKey names a cursor column appended to the selection. The value of a column which
+// may hold NULL is selected with a flag telling whether it is NULL, under NullAlias,
+// as a NULL value cannot be scanned without the type of its column.
+type CursorKey struct {
+	Alias     string
+	NullAlias string
+}
+
+// CursorInfos holds what is needed to turn a keyset query result into a cursor response.
+// The query is expected to fetch Limit+1 rows so that the presence of a next page
+// can be detected without a count query.
+type CursorInfos struct {
+	// Keys are the cursor columns appended to the selection.
+	Keys     []CursorKey
+	Limit    int
+	Backward bool
+	// HasCursor reports whether the request already carried an after/before cursor.
+	HasCursor bool
+}
+
+// Calculate trims the extra row fetched to detect the next page, restores the natural
+// order of a backward query and computes the surrounding cursors.
+func (c *CursorInfos) Calculate(entities []entx.Entity) ([]entx.Entity, *CursorResponse, error) {
+	hasMore := c.Limit > 0 && len(entities) > c.Limit
+	if hasMore {
+		entities = entities[:c.Limit]
+	}
+	if c.Backward {
+		slices.Reverse(entities)
+	}
+
+	if err := SetEntitiesCursor(entities, c.Keys); err != nil {
+		return nil, nil, err
+	}
+
+	res := new(CursorResponse)
+	if len(entities) == 0 {
+		return entities, res, nil
+	}
+
+	first, last := entities[0].Metadatas().Cursor, entities[len(entities)-1].Metadatas().Cursor
+	if c.Backward {
+		res.HasNext = true
+		res.NextCursor = last
+		if hasMore {
+			res.PrevCursor = first
+		}
+	} else {
+		res.HasNext = hasMore
+		if hasMore {
+			res.NextCursor = last
+		}
+		if c.HasCursor {
+			res.PrevCursor = first
+		}
+	}
+	return entities, res, nil
+}
+
+// SetEntitiesCursor encodes the cursor columns selected under keys into each entity metadatas.
+func SetEntitiesCursor(entities []entx.Entity, keys []CursorKey) error {
+	values := make([]any, len(keys))
+	for _, e := range entities {
+		for i, k := range keys {
+			v, err := cursorKeyValue(e, k)
+			if err != nil {
+				return &ExecError{
+					Op:  "SetEntitiesCursor",
+					Err: err,
+				}
+			}
+			values[i] = v
+		}
+		cursor, err := EncodeCursor(values)
+		if err != nil {
+			return err
+		}
+		e.Metadatas().Cursor = cursor
+	}
+	return nil
+}
+
+func cursorKeyValue(e entx.Entity, k CursorKey) (any, error) {
+	if k.NullAlias != "" {
+		null, err := e.Value(k.NullAlias)
+		if err != nil {
+			return nil, err
+		}
+		if isNullFlag(null) {
+			return nil, nil
+		}
+	}
+	return e.Value(k.Alias)
+}
+
+// isNullFlag reads the flag selected next to a cursor column which may hold NULL,
+// returned as an integer or a boolean depending on the driver.
+func isNullFlag(v any) bool {
+	switch f := v.(type) {
+	case bool:
+		return f
+	case int64:
+		return f != 0
+	case int32:
+		return f != 0
+	case float64:
+		return f != 0
+	case []byte:
+		return string(f) == "1"
+	case string:
+		return f == "1"
+	default:
+		return false
+	}
+}
+
+const (
+	cursorNull   = "n"
+	cursorInt    = "i"
+	cursorFloat  = "f"
+	cursorString = "s"
+	cursorBool   = "b"
+	cursorTime   = "t"
+)
+
+// cursorValue keeps the value type next to the value so that it can be bound
+// back as a query argument with the same type it was read with.
+type cursorValue struct {
+	T string `json:"t"`
+	V any    `json:"v,omitempty"`
+}
+
+// EncodeCursor serializes keyset values into an opaque url-safe string.
+func EncodeCursor(values []any) (string, error) {
+	encoded := make([]cursorValue, len(values))
+	for i, v := range values {
+		switch val := v.(type) {
+		case nil:
+			encoded[i] = cursorValue{T: cursorNull}
+		case int:
+			encoded[i] = cursorValue{T: cursorInt, V: int64(val)}
+		case int32:
+			encoded[i] = cursorValue{T: cursorInt, V: int64(val)}
+		case int64:
+			encoded[i] = cursorValue{T: cursorInt, V: val}
+		case uint64:
+			encoded[i] = cursorValue{T: cursorInt, V: val}
+		case float32:
+			encoded[i] = cursorValue{T: cursorFloat, V: float64(val)}
+		case float64:
+			encoded[i] = cursorValue{T: cursorFloat, V: val}
+		case bool:
+			encoded[i] = cursorValue{T: cursorBool, V: val}
+		case []uint8:
+			encoded[i] = cursorValue{T: cursorString, V: string(val)}
+		case string:
+			encoded[i] = cursorValue{T: cursorString, V: val}
+		case time.Time:
+			encoded[i] = cursorValue{T: cursorTime, V: val.Format(time.RFC3339Nano)}
+		default:
+			return "", &ExecError{
+				Op:  "EncodeCursor",
+				Err: fmt.Errorf("unsupported cursor value type %T", v),
+			}
+		}
+	}
+	raw, err := json.Marshal(encoded)
+	if err != nil {
+		return "", &ExecError{
+			Op:  "EncodeCursor",
+			Err: err,
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor.
+func DecodeCursor(cursor string) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded []cursorValue
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&encoded); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(encoded))
+	for i, ev := range encoded {
+		switch ev.T {
+		case cursorNull:
+			values[i] = nil
+		case cursorInt:
+			n, ok := ev.V.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("value %d: expected integer, got %T", i, ev.V)
+			}
+			if values[i], err = n.Int64(); err != nil {
+				return nil, fmt.Errorf("value %d: %w", i, err)
+			}
+		case cursorFloat:
+			n, ok := ev.V.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("value %d: expected number, got %T", i, ev.V)
+			}
+			if values[i], err = n.Float64(); err != nil {
+				return nil, fmt.Errorf("value %d: %w", i, err)
+			}
+		case cursorBool:
+			b, ok := ev.V.(bool)
+			if !ok {
+				return nil, fmt.Errorf("value %d: expected boolean, got %T", i, ev.V)
+			}
+			values[i] = b
+		case cursorString:
+			s, ok := ev.V.(string)
+			if !ok {
+				return nil, fmt.Errorf("value %d: expected string, got %T", i, ev.V)
+			}
+			values[i] = s
+		case cursorTime:
+			s, ok := ev.V.(string)
+			if !ok {
+				return nil, fmt.Errorf("value %d: expected time, got %T", i, ev.V)
+			}
+			if values[i], err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, fmt.Errorf("value %d: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("value %d: unknown type %q", i, ev.T)
+		}
+	}
+	return values, nil
+}
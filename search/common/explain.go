@@ -0,0 +1,163 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+)
+
+type ExplainKind string
+
+const (
+	ExplainKindSearch   ExplainKind = "search"
+	ExplainKindInclude  ExplainKind = "include"
+	ExplainKindPaginate ExplainKind = "paginate"
+	ExplainKindScalars  ExplainKind = "scalars"
+	ExplainKindBuckets  ExplainKind = "buckets"
+)
+
+// ExplainedQuery is a statement a request would run, rendered without being executed.
+type ExplainedQuery struct {
+	Kind ExplainKind `json:"kind"`
+	// Key is the response key of the search or of the buckets.
+	Key string `json:"key,omitempty"`
+	// Include is the relation path of an include query, starting with the name of its parent node.
+	Include string `json:"include,omitempty"`
+	// Scalars are the aliases of the scalar queries batched in the statement.
+	Scalars []string `json:"scalars,omitempty"`
+	SQL     string   `json:"sql"`
+	Args    []any    `json:"args,omitempty"`
+	// Plan holds the rows returned by EXPLAIN when the plan is requested.
+	Plan []map[string]any `json:"plan,omitempty"`
+}
+
+type ExplainResponse struct {
+	Queries []*ExplainedQuery `json:"queries"`
+}
+
+// ExplainRecorder collects the include queries applied to a search query,
+// which are only run by ent once their parents are loaded.
+type ExplainRecorder struct {
+	includes []*RecordedInclude
+}
+
+type RecordedInclude struct {
+	Path  string
+	Query entx.Query
+}
+
+type explainToken struct{}
+
+// ContextWithExplain returns a context whose builds record their include queries.
+func ContextWithExplain(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explainToken{}, new(ExplainRecorder))
+}
+
+// ExplainRecorderFrom returns the recorder of the context, nil outside an explain.
+func ExplainRecorderFrom(ctx context.Context) *ExplainRecorder {
+	r, _ := ctx.Value(explainToken{}).(*ExplainRecorder)
+	return r
+}
+
+func (r *ExplainRecorder) AddInclude(path string, q entx.Query) {
+	r.includes = append(r.includes, &RecordedInclude{Path: path, Query: q})
+}
+
+// TakeIncludes returns the includes recorded since the last call.
+func (r *ExplainRecorder) TakeIncludes() []*RecordedInclude {
+	includes := r.includes
+	r.includes = nil
+	return includes
+}
+
+// DryRun renders the statement of the query without executing it:
+// the last modifier records the final selector and cancels the context,
+// so that the driver returns before reaching the database.
+func DryRun(ctx context.Context, q entx.Query) (string, []any, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		query string
+		args  []any
+		built bool
+	)
+	q.Predicate(func(s *sql.Selector) {
+		query, args = s.Query()
+		built = true
+		cancel()
+	})
+	if _, err := q.All(ctx); !built {
+		if err == nil {
+			err = errors.New("query not built")
+		}
+		return "", nil, &ExecError{Op: "DryRun", Err: err}
+	}
+	return query, args, nil
+}
+
+// ExplainScalars renders a group of scalar queries as executed, batched in a single SELECT.
+func ExplainScalars(scalars []*ScalarQuery) *ExplainedQuery {
+	res := &ExplainedQuery{Kind: ExplainKindScalars, Scalars: make([]string, len(scalars))}
+	for i, q := range scalars {
+		res.Scalars[i] = q.Key
+	}
+	res.SQL, res.Args = scalarsSelector(scalars).Query()
+	return res
+}
+
+func ExplainBucketQuery(q *BucketQuery) *ExplainedQuery {
+	res := &ExplainedQuery{Kind: ExplainKindBuckets, Key: q.Key}
+	res.SQL, res.Args = q.Selector.Query()
+	return res
+}
+
+// ExplainPlan runs EXPLAIN on the statement in the given dialect and sets its plan.
+func ExplainPlan(ctx context.Context, client entx.Client, dialectName string, q *ExplainedQuery) error {
+	prefix := "EXPLAIN "
+	if dialectName == dialect.SQLite {
+		prefix = "EXPLAIN QUERY PLAN "
+	}
+	rows, err := client.QueryContext(ctx, prefix+q.SQL, q.Args...)
+	if err != nil {
+		return &ExecError{Op: "ExplainPlan", Err: err}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		dests := make([]any, len(columns))
+		for i := range values {
+			dests[i] = &values[i]
+		}
+		if err := rows.Scan(dests...); err != nil {
+			return err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		q.Plan = append(q.Plan, row)
+	}
+	return rows.Err()
+}
+
+// ExplainPlans sets the plan of all the statements of the response.
+func (res *ExplainResponse) ExplainPlans(ctx context.Context, client entx.Client, dialectName string) error {
+	for _, q := range res.Queries {
+		if err := ExplainPlan(ctx, client, dialectName, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUCache is an in-memory Cache holding a bounded number of entries,
+// the least recently used being evicted first.
+type LRUCache struct {
+	mu     sync.Mutex
+	size   int
+	order  *list.List
+	items  map[string]*list.Element
+	byNode map[string]map[string]struct{}
+	now    func() time.Time
+}
+
+type lruItem struct {
+	key     string
+	value   any
+	expires time.Time
+	nodes   []string
+}
+
+// NewLRUCache returns an in-memory cache of at most size entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:   size,
+		order:  list.New(),
+		items:  make(map[string]*list.Element),
+		byNode: make(map[string]map[string]struct{}),
+		now:    time.Now,
+	}
+}
+
+func (c *LRUCache) Get(_ context.Context, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*lruItem)
+	if !c.now().Before(item.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return item.value, true
+}
+
+func (c *LRUCache) Set(_ context.Context, key string, value any, ttl time.Duration, nodes []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	item := &lruItem{key: key, value: value, expires: c.now().Add(ttl), nodes: nodes}
+	c.items[key] = c.order.PushFront(item)
+	for _, node := range nodes {
+		keys, ok := c.byNode[node]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.byNode[node] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	for c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUCache) Invalidate(_ context.Context, nodes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, node := range nodes {
+		for key := range c.byNode[node] {
+			if el, ok := c.items[key]; ok {
+				c.remove(el)
+			}
+		}
+	}
+}
+
+// Len returns the number of entries, expired ones included until they are read or evicted.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRUCache) remove(el *list.Element) {
+	item := c.order.Remove(el).(*lruItem)
+	delete(c.items, item.key)
+	for _, node := range item.nodes {
+		if keys, ok := c.byNode[node]; ok {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(c.byNode, node)
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
 )
 
 type PaginateResponse struct {
@@ -19,6 +20,8 @@ type PaginateInfos struct {
 	CountSelector *sql.Selector
 	Page          int
 	Limit         int
+	// Nodes read by the count query, set when the cache is enabled.
+	Nodes []entx.Node
 }
 
 func (p *PaginateInfos) ToScalarQuery(key string) *ScalarQuery {
@@ -26,6 +29,7 @@ func (p *PaginateInfos) ToScalarQuery(key string) *ScalarQuery {
 		Selector: p.CountSelector,
 		Key:      key,
 		Dest:     new(sql.NullInt64),
+		Nodes:    p.Nodes,
 	}
 }
 
@@ -18,11 +18,13 @@ type QueryModifier struct {
 type QueryOp string
 
 const (
-	OpAggregate        QueryOp = "Aggregate"
-	OpAggregateOverall QueryOp = "AggregateOverall"
-	OpRootQuery        QueryOp = "RootQuery"
-	OpIncludeQuery     QueryOp = "IncludeQuery"
-	OpLastIncludeQuery QueryOp = "IncludeQuery"
+	OpAggregate          QueryOp = "Aggregate"
+	OpAggregateOverall   QueryOp = "AggregateOverall"
+	OpAggregateGrouped   QueryOp = "AggregateGrouped"
+	OpAggregateHistogram QueryOp = "AggregateHistogram"
+	OpRootQuery          QueryOp = "RootQuery"
+	OpIncludeQuery       QueryOp = "IncludeQuery"
+	OpLastIncludeQuery   QueryOp = "IncludeQuery"
 )
 
 // QueryPolicy must be placed first in the policy rules and does not skip
@@ -31,7 +33,7 @@ const (
 // if the search module isn't used, its policies will be skipped to the next ones.
 type QueryPolicy struct {
 	// Enforcer is called during build phases of:
-	// aggregate | overall agreggate | base query | include
+	// aggregate | overall agreggate | grouped aggregate | base query | include
 	Enforcer func(context.Context, QueryOp) (func(*sql.Selector), error)
 }
 
@@ -84,3 +86,72 @@ func EnforcePolicy(ctx context.Context, node entx.Node, op QueryOp) (func(*sql.S
 
 	return nil, nil
 }
+
+// MutationModifier is evaluated by the MutationPolicy of a node before the mutate module writes it.
+// It embeds the ent mutation being built, which the enforcer can read and rewrite,
+// and which is nil on delete operations.
+type MutationModifier struct {
+	ent.Mutation
+	Action MutationOp
+	Node   entx.Node
+	// Input is the mutation input of the request.
+	Input any
+	// Edge and IDs are the relation and the ids of attach and detach operations,
+	// IDs can be rewritten by the enforcer.
+	Edge     string
+	IDs      []any
+	Modifier func(*sql.Selector)
+}
+
+type MutationOp string
+
+const (
+	OpMutationCreate MutationOp = "Create"
+	OpMutationUpdate MutationOp = "Update"
+	OpMutationDelete MutationOp = "Delete"
+	OpMutationAttach MutationOp = "Attach"
+	OpMutationDetach MutationOp = "Detach"
+)
+
+// MutationPolicy is the counterpart of QueryPolicy for the mutate module,
+// and must be placed first in the mutation policy rules for the same reasons.
+type MutationPolicy struct {
+	// Enforcer is called before each create, update and delete, and before the relations are attached or detached.
+	// It rejects the mutation with an error, and restricts the rows of updates and deletes with the returned selector modifier.
+	Enforcer func(context.Context, *MutationModifier) (func(*sql.Selector), error)
+}
+
+func (p MutationPolicy) EvalMutation(ctx context.Context, m ent.Mutation) error {
+	if !IsPolicyTokenPresent(ctx) {
+		return privacy.Skip
+	}
+
+	switch t := m.(type) {
+	case *MutationModifier:
+		if p.Enforcer != nil {
+			switch modifier, decision := p.Enforcer(ctx, t); {
+			case decision != nil &&
+				!errors.Is(decision, privacy.Skip) &&
+				!errors.Is(decision, privacy.Allow):
+				return decision
+			default:
+				t.Modifier = modifier
+			}
+		}
+		return privacy.Allow
+	default:
+		return privacy.Allow
+	}
+}
+
+// EnforceMutationPolicy evaluates the policy of the modifier node and returns the selector modifier of the enforcer.
+func EnforceMutationPolicy(ctx context.Context, m *MutationModifier) (func(*sql.Selector), error) {
+	if policy := m.Node.Policy(); policy != nil {
+		if err := policy.EvalMutation(ctx, m); err != nil && !errors.Is(err, privacy.Allow) {
+			return nil, err
+		}
+		return m.Modifier, nil
+	}
+
+	return nil, nil
+}
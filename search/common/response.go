@@ -4,11 +4,14 @@ type AggregatesResponse = map[string]any
 
 type MetaResponse struct {
 	Aggregates AggregatesResponse `json:"aggregates,omitempty"`
+	Cost       *Cost              `json:"cost,omitempty"`
 }
 
 type MetaSearchResponse struct {
 	Paginate *PaginateResponse `json:"paginate,omitempty"`
+	Cursor   *CursorResponse   `json:"cursor,omitempty"`
 	Count    int               `json:"count,omitempty"`
+	Cost     *Cost             `json:"cost,omitempty"`
 }
 
 type SearchResponse struct {
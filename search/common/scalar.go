@@ -14,6 +14,7 @@ type ScalarQuery struct {
 	Selector *sql.Selector // subquery (must already be a SELECT returning 1 column)
 	Key      string        // unique SQL alias for this sub-select
 	Dest     any           // destination pointer, destinations that implement the driver.Valuer are processed.
+	Nodes    []entx.Node   // nodes read by the query, which is cached only when they are known
 }
 
 func ExecuteScalar(ctx context.Context, client entx.Client, scalar *ScalarQuery) (any, error) {
@@ -58,14 +59,12 @@ func executeScalars(
 		return []any{scalarRes}, nil
 	}
 
-	sel := sql.Select()
 	dests := make([]any, len(scalars))
 	for i, q := range scalars {
-		sel.AppendSelectExprAs(q.Selector, q.Key)
 		dests[i] = q.Dest
 	}
 
-	query, args := sel.Query()
+	query, args := scalarsSelector(scalars).Query()
 	rows, err := client.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -91,6 +90,19 @@ func executeScalars(
 	return dests, nil
 }
 
+// scalarsSelector returns the selector of the scalars, batched as sub-selects when there are several.
+func scalarsSelector(scalars []*ScalarQuery) *sql.Selector {
+	if len(scalars) == 1 {
+		return scalars[0].Selector
+	}
+	// batched sub-selects are selected without FROM, valid in all supported dialects
+	sel := sql.Dialect(scalars[0].Selector.Dialect()).Select()
+	for _, q := range scalars {
+		sel.AppendSelectExprAs(q.Selector, q.Key)
+	}
+	return sel
+}
+
 func handleValuer(dest any) (any, error) {
 	v, ok := dest.(driver.Valuer)
 	if ok {
@@ -143,20 +155,31 @@ func ExecuteScalarGroupsAsync(
 	}
 
 	for _, group := range scalarGroups {
+		group, entries := cfg.Cache.cachedScalars(ctx, response, group)
 		switch len(group) {
 		case 0:
 		case 1:
 			wg.Go(func() error {
+				ctx, span := cfg.Telemetry.StartScalars(ctx, group)
 				res, err := ExecuteScalar(ctx, client, group[0])
+				EndSpan(span, err)
 				if err != nil {
 					return err
 				}
 				response.Set(group[0].Key, res)
+				cfg.Cache.storeScalars(ctx, response, group, entries)
 				return nil
 			})
 		default:
 			wg.Go(func() error {
-				return ExecuteScalarsAsync(ctx, client, response, group...)
+				ctx, span := cfg.Telemetry.StartScalars(ctx, group)
+				err := ExecuteScalarsAsync(ctx, client, response, group...)
+				EndSpan(span, err)
+				if err != nil {
+					return err
+				}
+				cfg.Cache.storeScalars(ctx, response, group, entries)
+				return nil
 			})
 		}
 	}
@@ -173,15 +196,25 @@ func ExecuteScalarGroups(
 		return
 	}
 
+	synced := NewMapSync(response)
 	for _, group := range scalarGroups {
+		group, entries := cfg.Cache.cachedScalars(ctx, synced, group)
 		switch len(group) {
 		case 0:
+			continue
 		case 1:
+			ctx, span := cfg.Telemetry.StartScalars(ctx, group)
 			response[group[0].Key], err = ExecuteScalar(ctx, client, group[0])
-			return
+			EndSpan(span, err)
 		default:
-			return ExecuteScalars(ctx, client, response, group...)
+			ctx, span := cfg.Telemetry.StartScalars(ctx, group)
+			err = ExecuteScalars(ctx, client, response, group...)
+			EndSpan(span, err)
+		}
+		if err != nil {
+			return err
 		}
+		cfg.Cache.storeScalars(ctx, synced, group, entries)
 	}
 
 	return nil
@@ -0,0 +1,203 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "github.com/brice-74/entx/search"
+
+// Span names of the search pipeline.
+const (
+	SpanValidate    = "entx.search.validate"
+	SpanBuild       = "entx.search.build"
+	SpanExecute     = "entx.search.execute"
+	SpanQuery       = "entx.search.query"
+	SpanScalars     = "entx.search.scalars"
+	SpanTransaction = "entx.search.transaction"
+)
+
+// Metric names of the search pipeline.
+const (
+	MetricBuildDuration    = "entx.search.build.duration"
+	MetricExecDuration     = "entx.search.exec.duration"
+	MetricValidationErrors = "entx.search.validation.errors"
+)
+
+// Attribute keys set on the spans and the metrics.
+const (
+	AttrNode           = attribute.Key("entx.node")
+	AttrSearchKey      = attribute.Key("entx.search.key")
+	AttrRows           = attribute.Key("entx.rows")
+	AttrScalars        = attribute.Key("entx.scalars")
+	AttrRule           = attribute.Key("entx.rule")
+	AttrIsolationLevel = attribute.Key("entx.isolation_level")
+	AttrStatement      = attribute.Key("db.query.text")
+)
+
+// Telemetry instruments the phases of the search pipeline with OpenTelemetry.
+// A nil Telemetry disables the instrumentation.
+type Telemetry struct {
+	tracer           trace.Tracer
+	buildDuration    metric.Float64Histogram
+	execDuration     metric.Float64Histogram
+	validationErrors metric.Int64Counter
+}
+
+// NewTelemetry returns the instrumentation using the providers, a nil provider disabling its signal.
+func NewTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *Telemetry {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+	t := &Telemetry{tracer: tp.Tracer(instrumentationName)}
+
+	var err error
+	if t.buildDuration, err = meter.Float64Histogram(MetricBuildDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of the build phase of the search requests."),
+	); err != nil {
+		otel.Handle(err)
+		t.buildDuration = metricnoop.Float64Histogram{}
+	}
+	if t.execDuration, err = meter.Float64Histogram(MetricExecDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of the execution phase of the search requests."),
+	); err != nil {
+		otel.Handle(err)
+		t.execDuration = metricnoop.Float64Histogram{}
+	}
+	if t.validationErrors, err = meter.Int64Counter(MetricValidationErrors,
+		metric.WithDescription("Number of search requests rejected by a validation rule."),
+	); err != nil {
+		otel.Handle(err)
+		t.validationErrors = metricnoop.Int64Counter{}
+	}
+	return t
+}
+
+// Start starts a span of the pipeline, a non-recording one when the telemetry is disabled.
+func (t *Telemetry) Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, tracenoop.Span{}
+	}
+	return t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartQuery starts the span of a search query on the node,
+// with the key of the search when the context carries one.
+func (t *Telemetry) StartQuery(ctx context.Context, node entx.Node) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{AttrNode.String(node.Name())}
+	if key, ok := SearchKeyFromContext(ctx); ok {
+		attrs = append(attrs, AttrSearchKey.String(key))
+	}
+	return t.Start(ctx, SpanQuery, attrs...)
+}
+
+// StartScalars starts the span of a group of scalar queries executed together.
+func (t *Telemetry) StartScalars(ctx context.Context, scalars []*ScalarQuery) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, tracenoop.Span{}
+	}
+	keys := make([]string, len(scalars))
+	for i, q := range scalars {
+		keys[i] = q.Key
+	}
+	query, _ := scalarsSelector(scalars).Query()
+	return t.Start(ctx, SpanScalars, AttrScalars.StringSlice(keys), AttrStatement.String(query))
+}
+
+// RecordStatement returns a query modifier, to be applied last, setting the statement on the span.
+func (t *Telemetry) RecordStatement(span trace.Span) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		if t != nil && span.IsRecording() {
+			query, _ := s.Query()
+			span.SetAttributes(AttrStatement.String(query))
+		}
+	}
+}
+
+// EndSpan records the error of the span, if any, and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceValidate runs the validation phase, counting the rejections per rule.
+func TraceValidate(ctx context.Context, cfg *Config, fn func() error) error {
+	t := cfg.Telemetry
+	if t == nil {
+		return fn()
+	}
+	ctx, span := t.Start(ctx, SpanValidate)
+	err := fn()
+	if verr := (*ValidationError)(nil); errors.As(err, &verr) {
+		rule := AttrRule.String(verr.Rule)
+		span.SetAttributes(rule)
+		t.validationErrors.Add(ctx, 1, metric.WithAttributes(rule))
+	}
+	EndSpan(span, err)
+	return err
+}
+
+// TraceBuild runs the build phase, recording its latency.
+func TraceBuild[T any](ctx context.Context, cfg *Config, fn func(context.Context) (T, error)) (T, error) {
+	t := cfg.Telemetry
+	if t == nil {
+		return fn(ctx)
+	}
+	return tracePhase(ctx, t, SpanBuild, t.buildDuration, fn)
+}
+
+// TraceExec runs the execution phase, recording its latency.
+func TraceExec[T any](ctx context.Context, cfg *Config, fn func(context.Context) (T, error)) (T, error) {
+	t := cfg.Telemetry
+	if t == nil {
+		return fn(ctx)
+	}
+	return tracePhase(ctx, t, SpanExecute, t.execDuration, fn)
+}
+
+func tracePhase[T any](
+	ctx context.Context,
+	t *Telemetry,
+	name string,
+	latency metric.Float64Histogram,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	start := time.Now()
+	ctx, span := t.Start(ctx, name)
+	res, err := fn(ctx)
+	latency.Record(ctx, time.Since(start).Seconds())
+	EndSpan(span, err)
+	return res, err
+}
+
+type searchKeyToken struct{}
+
+// ContextWithSearchKey returns a context carrying the response key of the executed search.
+func ContextWithSearchKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, searchKeyToken{}, key)
+}
+
+func SearchKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(searchKeyToken{}).(string)
+	return key, ok && key != ""
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"context"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// Cost returns the cost of preprocessed query options on the node.
+func (qo *QueryOptions) Cost(node entx.Node, w *CostWeights) int {
+	cost := w.Search +
+		qo.Filters.Cost(node, w) +
+		qo.Sorts.Cost(node, w) +
+		qo.Aggregates.Cost(node, w) +
+		qo.Includes.Cost(node, 1, w)
+	if qo.WithPagination {
+		cost += w.Aggregate
+	}
+	return cost
+}
+
+func (qo *QueryOptions) lowerIncludeLimits() bool {
+	return qo.Includes.LowerLimits()
+}
+
+func (q *TargetedQuery) Cost(graph entx.Graph, w *CostWeights) int {
+	node := graph[q.From]
+	if node == nil {
+		return 0
+	}
+	return q.QueryOptions.Cost(node, w)
+}
+
+func (queries NamedQueries) Cost(graph entx.Graph, w *CostWeights) (cost int) {
+	for _, q := range queries {
+		cost += q.Cost(graph, w)
+	}
+	return
+}
+
+func (queries NamedQueries) lowerIncludeLimits() (lowered bool) {
+	for _, q := range queries {
+		if q.lowerIncludeLimits() {
+			lowered = true
+		}
+	}
+	return
+}
+
+func (group *QueryGroup) Cost(graph entx.Graph, w *CostWeights) int {
+	return group.Searches.Cost(graph, w) +
+		group.Aggregates.Cost(graph, w) +
+		group.Grouped.Cost(graph, w) +
+		group.Histograms.Cost(graph, w)
+}
+
+func (group *QueryGroup) lowerIncludeLimits() bool {
+	return group.Searches.lowerIncludeLimits()
+}
+
+func (groups TxQueryGroups) Cost(graph entx.Graph, w *CostWeights) (cost int) {
+	for _, group := range groups {
+		cost += group.Cost(graph, w)
+	}
+	return
+}
+
+func (groups TxQueryGroups) lowerIncludeLimits() (lowered bool) {
+	for _, group := range groups {
+		if group.lowerIncludeLimits() {
+			lowered = true
+		}
+	}
+	return
+}
+
+func (q *QueryBundle) Cost(graph entx.Graph, w *CostWeights) int {
+	cost := q.QueryGroup.Cost(graph, w) + q.Transactions.Cost(graph, w)
+	for _, aggregates := range q.ParallelGroups {
+		cost += aggregates.Cost(graph, w)
+	}
+	return cost
+}
+
+func (q *QueryBundle) lowerIncludeLimits() bool {
+	// both are lowered, the limits of every search being halved at each step
+	group, txs := q.QueryGroup.lowerIncludeLimits(), q.Transactions.lowerIncludeLimits()
+	return group || txs
+}
+
+// enforceCost computes the cost of a validated request and enforces the budget of its caller.
+// It returns a nil cost when the cost model is disabled.
+func enforceCost(
+	ctx context.Context,
+	cfg *Config,
+	compute func(*CostWeights) int,
+	lower func() bool,
+) (*Cost, error) {
+	if cfg.Cost == nil {
+		return nil, nil
+	}
+	return cfg.Cost.EnforceCost(ctx, compute, lower)
+}
+
+// withSearchCost reports the cost in the meta of a search response.
+func withSearchCost(res *SearchResponse, cost *Cost) *SearchResponse {
+	if res == nil || cost == nil {
+		return res
+	}
+	if res.Meta == nil {
+		res.Meta = &MetaSearchResponse{}
+	}
+	res.Meta.Cost = cost
+	return res
+}
+
+// withGroupCost reports the cost in the meta of a group response.
+func withGroupCost(res *GroupResponse, cost *Cost) *GroupResponse {
+	if res == nil || cost == nil {
+		return res
+	}
+	if res.Meta == nil {
+		res.Meta = &MetaResponse{}
+	}
+	res.Meta.Cost = cost
+	return res
+}
+
+// validateAndCost runs the validation phase of a request, its cost being part of it.
+// The types of the fields are checked with the graph once the request is preprocessed,
+// so that a rejected request is not costed.
+func validateAndCost(
+	ctx context.Context,
+	cfg *Config,
+	validate func() error,
+	check func() error,
+	compute func(*CostWeights) int,
+	lower func() bool,
+) (cost *Cost, err error) {
+	err = common.TraceValidate(ctx, cfg, func() error {
+		if err := validate(); err != nil {
+			return err
+		}
+		if err := check(); err != nil {
+			return err
+		}
+		cost, err = enforceCost(ctx, cfg, compute, lower)
+		return err
+	})
+	return
+}
@@ -0,0 +1,255 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// AggParams holds the parameters of the aggregate types needing them.
+type AggParams struct {
+	// Percentile is the fraction, between 0 and 1 excluded, computed by a percentile aggregate.
+	Percentile float64 `json:"percentile,omitempty"`
+	// Separator joins the values of a string aggregate, defaults to ",".
+	Separator string `json:"separator,omitempty"`
+	// Order sorts the values of a string aggregate before joining them.
+	Order Direction `json:"order,omitempty"`
+}
+
+var (
+	ErrAggDialect    = "aggregate %q is not supported by dialect %q"
+	ErrAggPercentile = "percentile must be between 0 and 1 excluded, got %v"
+	ErrAggParam      = "parameter %q is not allowed for aggregate type %q"
+)
+
+const defaultAggSeparator = ","
+
+// IsValid reports whether the aggregate type is supported.
+func (a Agg) IsValid() bool {
+	switch a {
+	case AggAvg, AggSum, AggMin, AggMax, AggCount,
+		AggCountDistinct, AggStddev, AggVariance,
+		AggMedian, AggPercentile,
+		AggBoolAnd, AggBoolOr,
+		AggStringAgg, AggGroupConcat:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a Agg) isStringAgg() bool {
+	return a == AggStringAgg || a == AggGroupConcat
+}
+
+// validate checks the parameters of the aggregate and its support by the dialect,
+// which is skipped when unknown.
+func (p *AggParams) validate(agg Agg, d string) error {
+	if agg == AggPercentile {
+		if p.Percentile <= 0 || p.Percentile >= 1 {
+			return &common.ValidationError{
+				Rule: "AggregatePercentile",
+				Err:  fmt.Errorf(ErrAggPercentile, p.Percentile),
+			}
+		}
+	} else if p.Percentile != 0 {
+		return &common.ValidationError{
+			Rule: "AggregateParamNotAllowed",
+			Err:  fmt.Errorf(ErrAggParam, "percentile", agg),
+		}
+	}
+
+	if (agg == AggMedian || agg == AggPercentile) && d != "" && d != dialect.Postgres {
+		return &common.ValidationError{
+			Rule: "AggregateDialect",
+			Err:  fmt.Errorf(ErrAggDialect, agg, d),
+		}
+	}
+
+	if !agg.isStringAgg() {
+		var param string
+		switch {
+		case p.Separator != "":
+			param = "separator"
+		case p.Order != "":
+			param = "order"
+		default:
+			return nil
+		}
+		return &common.ValidationError{
+			Rule: "AggregateParamNotAllowed",
+			Err:  fmt.Errorf(ErrAggParam, param, agg),
+		}
+	}
+
+	switch p.Order {
+	case DirASC, DirDESC, "":
+	default:
+		return &common.ValidationError{
+			Rule: "AggregateOrder",
+			Err:  fmt.Errorf("unsupported order %q", p.Order),
+		}
+	}
+	return nil
+}
+
+// aggFunc returns the function applying the aggregate on a column for the dialect.
+// Functions missing in a dialect are emulated when possible.
+func aggFunc(d string, agg Agg, p *AggParams) (func(string) string, error) {
+	switch agg {
+	case AggAvg:
+		return sql.Avg, nil
+	case AggSum:
+		return sql.Sum, nil
+	case AggMin:
+		return sql.Min, nil
+	case AggMax:
+		return sql.Max, nil
+	case AggCount:
+		return sql.Count, nil
+	case AggCountDistinct:
+		return func(col string) string { return sql.Count(sql.Distinct(col)) }, nil
+	case AggVariance:
+		if d == dialect.SQLite {
+			return sqliteVariance, nil
+		}
+		return func(col string) string { return "VAR_SAMP(" + col + ")" }, nil
+	case AggStddev:
+		if d == dialect.SQLite {
+			return func(col string) string { return "SQRT(" + sqliteVariance(col) + ")" }, nil
+		}
+		return func(col string) string { return "STDDEV_SAMP(" + col + ")" }, nil
+	case AggMedian, AggPercentile:
+		// rejected by the validation of the inputs bound to the dialect
+		if d != dialect.Postgres {
+			return nil, &common.QueryBuildError{
+				Op:  "aggFunc",
+				Err: fmt.Errorf(ErrAggDialect, agg, d),
+			}
+		}
+		fraction := p.Percentile
+		if agg == AggMedian {
+			fraction = 0.5
+		}
+		return func(col string) string {
+			return "percentile_cont(" + strconv.FormatFloat(fraction, 'f', -1, 64) + ") WITHIN GROUP (ORDER BY " + col + ")"
+		}, nil
+	case AggBoolAnd:
+		// booleans are stored as integers outside of PostgreSQL
+		if d == dialect.Postgres {
+			return func(col string) string { return "bool_and(" + col + ")" }, nil
+		}
+		return sql.Min, nil
+	case AggBoolOr:
+		if d == dialect.Postgres {
+			return func(col string) string { return "bool_or(" + col + ")" }, nil
+		}
+		return sql.Max, nil
+	case AggStringAgg, AggGroupConcat:
+		sep := p.Separator
+		if sep == "" {
+			sep = defaultAggSeparator
+		}
+		sep = quoteString(d, sep)
+		return func(col string) string {
+			var order string
+			if p.Order != "" {
+				order = " ORDER BY " + col + " " + string(p.Order)
+			}
+			switch d {
+			case dialect.Postgres:
+				return "string_agg(CAST(" + col + " AS TEXT), " + sep + order + ")"
+			case dialect.MySQL:
+				return "GROUP_CONCAT(" + col + order + " SEPARATOR " + sep + ")"
+			default:
+				return "group_concat(" + col + ", " + sep + order + ")"
+			}
+		}, nil
+	default:
+		return nil, &common.QueryBuildError{
+			Op:  "aggFunc",
+			Err: fmt.Errorf(ErrUnsupportedAggType, agg),
+		}
+	}
+}
+
+// sqliteVariance emulates the sample variance, the operands are
+// converted to real to avoid integer divisions.
+func sqliteVariance(col string) string {
+	return fmt.Sprintf("((SUM(%[1]s * %[1]s * 1.0) - SUM(%[1]s * 1.0) * SUM(%[1]s * 1.0) / COUNT(%[1]s)) / (COUNT(%[1]s) - 1))", col)
+}
+
+// quoteString returns s as a string literal of the dialect.
+func quoteString(d, s string) string {
+	if d == dialect.MySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// aggDest returns a scan destination constructor matching the aggregate result.
+func aggDest(agg Agg) func() any {
+	switch agg {
+	case AggCount, AggCountDistinct:
+		return func() any { return new(sql.NullInt64) }
+	case AggBoolAnd, AggBoolOr:
+		return func() any { return new(sql.NullBool) }
+	case AggStringAgg, AggGroupConcat:
+		return func() any { return new(sql.NullString) }
+	default:
+		return func() any { return new(sql.NullFloat64) }
+	}
+}
+
+// normalizeAggValue converts a raw aggregate value, whose type depends on the driver
+// and the dialect, to the type of the aggregate result.
+func normalizeAggValue(agg Agg, v any) any {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	switch agg {
+	case AggCount, AggCountDistinct:
+		switch n := v.(type) {
+		case int64:
+			return n
+		case float64:
+			return int64(n)
+		case string:
+			if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+				return i
+			}
+		}
+	case AggAvg, AggSum, AggStddev, AggVariance, AggMedian, AggPercentile:
+		switch n := v.(type) {
+		case float64:
+			return n
+		case int64:
+			return float64(n)
+		case string:
+			if f, err := strconv.ParseFloat(n, 64); err == nil {
+				return f
+			}
+		}
+	case AggBoolAnd, AggBoolOr:
+		switch b := v.(type) {
+		case bool:
+			return b
+		case int64:
+			return b != 0
+		case string:
+			if parsed, err := strconv.ParseBool(b); err == nil {
+				return parsed
+			}
+		}
+	case AggStringAgg, AggGroupConcat:
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return entx.NormalizeAggregateValue(v)
+}
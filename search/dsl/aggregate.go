@@ -15,11 +15,20 @@ import (
 type Agg string
 
 const (
-	AggAvg   Agg = "avg"
-	AggSum   Agg = "sum"
-	AggMin   Agg = "min"
-	AggMax   Agg = "max"
-	AggCount Agg = "count"
+	AggAvg           Agg = "avg"
+	AggSum           Agg = "sum"
+	AggMin           Agg = "min"
+	AggMax           Agg = "max"
+	AggCount         Agg = "count"
+	AggCountDistinct Agg = "count_distinct"
+	AggStddev        Agg = "stddev"
+	AggVariance      Agg = "variance"
+	AggMedian        Agg = "median"
+	AggPercentile    Agg = "percentile"
+	AggBoolAnd       Agg = "bool_and"
+	AggBoolOr        Agg = "bool_or"
+	AggStringAgg     Agg = "string_agg"
+	AggGroupConcat   Agg = "group_concat"
 )
 
 type BaseAggregate struct {
@@ -28,6 +37,7 @@ type BaseAggregate struct {
 	Type     Agg     `json:"type"`
 	Distinct bool    `json:"distinct,omitempty"`
 	Filters  Filters `json:"filters,omitempty"`
+	AggParams
 	// pre-processed segments
 	fieldParts   []string
 	preprocessed bool
@@ -39,8 +49,8 @@ var (
 	ErrUnsupportedAggType   = "unsupported aggregate type %q"
 )
 
-// buildExpr builds the aggregate function, expression, and alias.
-func (b *BaseAggregate) buildExpr(tbl *sql.SelectTable, resolvedField string) (
+// buildExpr builds the aggregate function of the dialect, expression, and alias.
+func (b *BaseAggregate) buildExpr(dialect string, tbl *sql.SelectTable, resolvedField string) (
 	fn func(string) string, expr string, alias string, err error,
 ) {
 	if !b.preprocessed {
@@ -63,22 +73,8 @@ func (b *BaseAggregate) buildExpr(tbl *sql.SelectTable, resolvedField string) (
 		resolvedField = "*"
 	}
 
-	switch b.Type {
-	case AggAvg:
-		fn = sql.Avg
-	case AggSum:
-		fn = sql.Sum
-	case AggMin:
-		fn = sql.Min
-	case AggMax:
-		fn = sql.Max
-	case AggCount:
-		fn = sql.Count
-	default:
-		return nil, "", "", &common.QueryBuildError{
-			Op:  "BaseAggregate.buildExpr",
-			Err: fmt.Errorf(ErrUnsupportedAggType, b.Type),
-		}
+	if fn, err = aggFunc(dialect, b.Type, &b.AggParams); err != nil {
+		return nil, "", "", err
 	}
 
 	if resolvedField == "*" {
@@ -91,17 +87,20 @@ func (b *BaseAggregate) buildExpr(tbl *sql.SelectTable, resolvedField string) (
 		}
 	}
 
-	alias = b.Alias
-	if alias == "" {
-		prefix := strings.ToLower(string(b.Type))
-		if b.Distinct {
-			prefix += "_distinct"
-		}
-		safe := strings.ReplaceAll(b.Field, ".", "_")
-		alias = fmt.Sprintf("%s_%s", prefix, safe)
-	}
+	return fn, expr, b.alias(), nil
+}
 
-	return fn, expr, alias, nil
+// alias returns the given alias or one derived from the aggregate type and field.
+func (b *BaseAggregate) alias() string {
+	if b.Alias != "" {
+		return b.Alias
+	}
+	prefix := strings.ToLower(string(b.Type))
+	if b.Distinct {
+		prefix += "_distinct"
+	}
+	safe := strings.ReplaceAll(b.Field, ".", "_")
+	return fmt.Sprintf("%s_%s", prefix, safe)
 }
 
 func (b *BaseAggregate) preprocess(filterCfg *common.FilterConfig, allowEmptyField bool) error {
@@ -123,14 +122,15 @@ func (b *BaseAggregate) preprocess(filterCfg *common.FilterConfig, allowEmptyFie
 		b.fieldParts = parts
 	}
 
-	switch b.Type {
-	case AggCount, AggSum, AggAvg, AggMin, AggMax:
-	default:
+	if !b.Type.IsValid() {
 		return &common.ValidationError{
 			Rule: "AggregateTypeUnsupported",
 			Err:  fmt.Errorf("unsupported aggregate type %q", b.Type),
 		}
 	}
+	if err := b.AggParams.validate(b.Type, filterCfg.Dialect()); err != nil {
+		return err
+	}
 
 	if b.Distinct && !(b.Type == AggCount || b.Type == AggSum || b.Type == AggAvg) {
 		return &common.ValidationError{
@@ -160,6 +160,9 @@ func applyBridgesInverseJoins(sel *sql.Selector, bridges []entx.Bridge, base *sq
 
 type Aggregate struct {
 	BaseAggregate
+	// Recursive aggregates the rows related through the closure of the relation of the field,
+	// which must relate the node to itself.
+	Recursive *Recursion `json:"recursive,omitempty"`
 }
 
 func (a *Aggregate) Predicate(ctx context.Context, root entx.Node, dialect string) (func(*sql.Selector), string, error) {
@@ -167,6 +170,10 @@ func (a *Aggregate) Predicate(ctx context.Context, root entx.Node, dialect strin
 		panic("Aggregate.Predicate: called before preprocess")
 	}
 
+	if a.Recursive != nil {
+		return a.recursivePredicate(ctx, root, dialect)
+	}
+
 	node, finalField, bridges, err := resolveChain(root, a.fieldParts)
 	if err != nil {
 		return nil, "", &common.QueryBuildError{
@@ -188,8 +195,8 @@ func (a *Aggregate) Predicate(ctx context.Context, root entx.Node, dialect strin
 		}
 	}
 
-	tbl := sql.Table(node.Table()).As("t0")
-	fn, expr, alias, err := a.BaseAggregate.buildExpr(tbl, finalField)
+	tbl := sql.Dialect(dialect).Table(node.Table()).As("t0")
+	fn, expr, alias, err := a.BaseAggregate.buildExpr(dialect, tbl, finalField)
 	if err != nil {
 		return nil, "", err
 	}
@@ -254,6 +261,17 @@ func (as Aggregates) Predicate(ctx context.Context, root entx.Node, dialect stri
 	return appliesAgg, metaFields, nil
 }
 
+// NormalizeValue converts the raw value selected for an aggregate alias
+// to the type of the aggregate result.
+func (as Aggregates) NormalizeValue(field string, v any) any {
+	for _, a := range as {
+		if a.Alias == field {
+			return normalizeAggValue(a.Type, v)
+		}
+	}
+	return entx.NormalizeAggregateValue(v)
+}
+
 func (ags Aggregates) ValidateAndPreprocess(cfg *common.AggregateConfig) error {
 	if cfg == nil {
 		cfg = &common.AggregateConfig{}
@@ -278,7 +296,7 @@ func (a *Aggregate) ValidateAndPreprocess(cfg *common.AggregateConfig) error {
 			Err:  fmt.Errorf("aggregate relation depth of %d exceeds max %d", depth, cfg.MaxAggregateRelationDepth),
 		}
 	}
-	return nil
+	return a.Recursive.validate(cfg.FilterConfig)
 }
 
 var (
@@ -329,14 +347,14 @@ func (a *OverallAggregate) Build(ctx context.Context, graph entx.Graph, dialect
 		return nil, "", err
 	}
 
-	tbl := sql.Table(node.Table()).As("t0")
-	fn, expr, alias, err := a.BaseAggregate.buildExpr(tbl, field)
+	tbl := sql.Dialect(dialect).Table(node.Table()).As("t0")
+	fn, expr, alias, err := a.BaseAggregate.buildExpr(dialect, tbl, field)
 	if err != nil {
 		return nil, "", err
 	}
 	a.Alias = alias
 
-	sel := sql.Dialect(dialect).Select(fn(expr)).As(alias).From(tbl)
+	sel := sql.Dialect(dialect).Select(fn(expr)).From(tbl)
 	if policyPred != nil {
 		policyPred(sel)
 	}
@@ -347,6 +365,8 @@ func (a *OverallAggregate) Build(ctx context.Context, graph entx.Graph, dialect
 			p(sel)
 		}
 	}
+	// aliased last, predicates would otherwise qualify columns with the alias
+	sel.As(alias)
 
 	return sel, alias, nil
 }
@@ -360,10 +380,9 @@ func (a *OverallAggregate) BuildScalar(ctx context.Context, graph entx.Graph, di
 		Selector: sel,
 		Key:      alias,
 	}
-	if a.Type == AggCount {
-		sq.Dest = new(sql.NullInt64)
-	} else {
-		sq.Dest = new(sql.NullFloat64)
+	sq.Dest = aggDest(a.Type)()
+	if sq.Nodes, err = common.DependentNodes(graph[a.fieldParts[0]], a); err != nil {
+		return nil, err
 	}
 	return sq, nil
 }
@@ -395,6 +414,9 @@ func (oas OverallAggregates) Execute(
 	defer cancel()
 
 	err, count := oas.ValidateAndPreprocessFinal(cfg), len(oas)
+	if err == nil {
+		err = oas.CheckTypes(graph)
+	}
 	if err != nil || count == 0 {
 		return nil, err
 	}
@@ -0,0 +1,244 @@
+package dsl
+
+import (
+	"fmt"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// The types are checked on preprocessed inputs, with the graph, during the validation phase:
+// a field whose type does not support its operator, aggregate, sort or grouping is rejected
+// before the cost of the request is computed, the build relying on this pass. The paths are
+// resolved as the build resolves them, one that cannot be resolved being left to the build,
+// which rejects it.
+
+// chainField returns the node and the field a path ends on, both nil when the path cannot be resolved.
+func chainField(node entx.Node, parts []string, resolve func(entx.Node, []string) (entx.Node, string, []entx.Bridge, error)) (entx.Node, *entx.Field) {
+	final, name, _, err := resolve(node, parts)
+	if err != nil {
+		return nil, nil
+	}
+	if name == "" {
+		return final, nil
+	}
+	return final, final.FieldByName(name)
+}
+
+func (fs Filters) CheckTypes(node entx.Node) error {
+	for _, f := range fs {
+		if err := f.CheckTypes(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Filter) CheckTypes(node entx.Node) error {
+	if f == nil {
+		return nil
+	}
+	if len(f.relationParts) > 0 {
+		final, _, bridges, err := resolvePivotChain(node, f.relationParts)
+		if err != nil || len(bridges) != len(f.relationParts) {
+			return nil
+		}
+		node = final
+	}
+	if err := f.Not.CheckTypes(node); err != nil {
+		return err
+	}
+	if err := f.And.CheckTypes(node); err != nil {
+		return err
+	}
+	if err := f.Or.CheckTypes(node); err != nil {
+		return err
+	}
+	if len(f.fieldParts) == 0 {
+		return nil
+	}
+	_, field := chainField(node, f.fieldParts, resolvePivotChain)
+	if field == nil {
+		return nil
+	}
+	if err := checkFilterField(field, f.Operator, f.Value); err != nil {
+		return err
+	}
+	if field.Type == entx.TypeTime {
+		if _, err := resolveTimeValue(f.Value, f.now); err != nil {
+			return &common.ValidationError{
+				Rule: "FilterTimeValue",
+				Err:  err,
+			}
+		}
+	}
+	return nil
+}
+
+func (sorts Sorts) CheckTypes(node entx.Node) error {
+	for _, s := range sorts {
+		if s.alias != "" {
+			continue
+		}
+		final, field := chainField(node, s.fieldParts, resolveChain)
+		if s.Aggregate != "" && final != nil {
+			if err := s.Filters.CheckTypes(final); err != nil {
+				return err
+			}
+		}
+		if field == nil {
+			continue
+		}
+		var err error
+		if s.Aggregate != "" {
+			err = checkAggregateField(field, s.Aggregate)
+		} else {
+			err = checkSortField(field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (aggs Aggregates) CheckTypes(node entx.Node) error {
+	for _, a := range aggs {
+		if err := a.BaseAggregate.checkTypes(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *BaseAggregate) checkTypes(node entx.Node) error {
+	final, field := chainField(node, a.fieldParts, resolveChain)
+	if field != nil {
+		if err := checkAggregateField(field, a.Type); err != nil {
+			return err
+		}
+	}
+	if final == nil {
+		return nil
+	}
+	return a.Filters.CheckTypes(final)
+}
+
+func (incs Includes) CheckTypes(node entx.Node) error {
+	for _, inc := range incs {
+		final, _, bridges, err := resolveChain(node, inc.relationParts)
+		if err != nil || len(bridges) != len(inc.relationParts) {
+			continue
+		}
+		scope := withPivot(final, bridges[len(bridges)-1], true)
+		if err := inc.Filters.CheckTypes(scope); err != nil {
+			return err
+		}
+		if err := inc.Sort.CheckTypes(scope); err != nil {
+			return err
+		}
+		if err := inc.Aggregates.CheckTypes(final); err != nil {
+			return err
+		}
+		if err := inc.Includes.CheckTypes(final); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (oas OverallAggregates) CheckTypes(graph entx.Graph) error {
+	for _, a := range oas {
+		if err := a.checkTypes(graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *OverallAggregate) checkTypes(graph entx.Graph) error {
+	node := graph[a.fieldParts[0]]
+	if node == nil {
+		return nil
+	}
+	if len(a.fieldParts) == 2 {
+		if f := node.FieldByName(a.fieldParts[1]); f != nil {
+			if err := checkAggregateField(f, a.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return a.Filters.CheckTypes(node)
+}
+
+func (gas GroupedAggregates) CheckTypes(graph entx.Graph) error {
+	for _, g := range gas {
+		if err := g.checkTypes(graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTypes checks the grouped fields, the aggregates and the having conditions, the buckets
+// being sorted by grouped fields or aggregate aliases whose types are checked here.
+func (g *GroupedAggregate) checkTypes(graph entx.Graph) error {
+	node := graph[g.From]
+	if node == nil {
+		return nil
+	}
+	for i, parts := range g.groupParts {
+		if _, f := chainField(node, parts, resolveChain); f != nil {
+			if err := checkGroupField(f, g.GroupBy[i]); err != nil {
+				return err
+			}
+		}
+	}
+	var (
+		aggs   = make(map[string]*BaseAggregate, len(g.Aggregates))
+		fields = make(map[string]*entx.Field, len(g.Aggregates))
+	)
+	for _, a := range g.Aggregates {
+		aggs[a.alias()] = a
+		if len(a.fieldParts) == 0 {
+			continue
+		}
+		_, f := chainField(node, a.fieldParts, resolveChain)
+		if f == nil {
+			continue
+		}
+		if err := checkAggregateField(f, a.Type); err != nil {
+			return err
+		}
+		fields[a.alias()] = f
+	}
+	for _, h := range g.Having {
+		a, f := aggs[h.Aggregate], fields[h.Aggregate]
+		if len(a.fieldParts) > 0 && f == nil {
+			continue
+		}
+		if err := checkHavingAggregate(h, a.Type, f); err != nil {
+			return err
+		}
+	}
+	return g.Filters.CheckTypes(node)
+}
+
+func (hs DateHistograms) CheckTypes(graph entx.Graph) error {
+	for _, h := range hs {
+		if err := h.OverallAggregate.checkTypes(graph); err != nil {
+			return err
+		}
+		node := graph[h.fieldParts[0]]
+		if node == nil {
+			continue
+		}
+		if on := node.FieldByName(h.On); on != nil && on.Type != entx.TypeTime {
+			return &common.ValidationError{
+				Rule: "HistogramFieldType",
+				Err:  fmt.Errorf(ErrHistogramFieldType, h.On, on.Type),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package dsl
+
+import (
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// The costs are computed on preprocessed inputs. A relation or a field that cannot be
+// resolved adds no cost, the build rejecting it afterwards.
+
+// chainCost returns the cost of the relations traversed by a path and the node and field it ends on.
+func chainCost(node entx.Node, parts []string, w *common.CostWeights) (cost int, final entx.Node, field *entx.Field) {
+	return costChain(node, parts, w, resolveChain)
+}
+
+// filterChainCost is like chainCost for the paths of filters, which may read pivot rows.
+func filterChainCost(node entx.Node, parts []string, w *common.CostWeights) (cost int, final entx.Node, field *entx.Field) {
+	return costChain(node, parts, w, resolvePivotChain)
+}
+
+func costChain(
+	node entx.Node,
+	parts []string,
+	w *common.CostWeights,
+	resolve func(entx.Node, []string) (entx.Node, string, []entx.Bridge, error),
+) (cost int, final entx.Node, field *entx.Field) {
+	final, name, bridges, err := resolve(node, parts)
+	cost = hopsCost(bridges, w)
+	if err != nil || name == "" {
+		return cost, final, nil
+	}
+	return cost, final, final.FieldByName(name)
+}
+
+func hopsCost(bridges []entx.Bridge, w *common.CostWeights) (cost int) {
+	for _, b := range bridges {
+		// the pivot row is read from the table already joined by its relation
+		if _, ok := b.(*pivotBridge); ok {
+			continue
+		}
+		cost += w.RelationHop
+		if b.RelInfos().RelType == sqlgraph.M2M {
+			cost += w.M2MJoin
+		}
+	}
+	return
+}
+
+// recursionCost returns the cost of the last bridge followed again up to the depth of a recursion.
+func (r *Recursion) recursionCost(bridges []entx.Bridge, w *common.CostWeights) int {
+	if r == nil || len(bridges) == 0 {
+		return 0
+	}
+	return (r.MaxDepth - 1) * hopsCost(bridges[len(bridges)-1:], w)
+}
+
+func fieldCost(f *entx.Field, w *common.CostWeights) int {
+	if f != nil && !f.Indexed {
+		return w.UnindexedField
+	}
+	return 0
+}
+
+func (fs Filters) Cost(node entx.Node, w *common.CostWeights) (cost int) {
+	for _, f := range fs {
+		cost += f.Cost(node, w)
+	}
+	return
+}
+
+func (f *Filter) Cost(node entx.Node, w *common.CostWeights) (cost int) {
+	if f == nil {
+		return 0
+	}
+	if len(f.relationParts) > 0 {
+		final, _, bridges, err := resolvePivotChain(node, f.relationParts)
+		cost += hopsCost(bridges, w) + f.Recursive.recursionCost(bridges, w)
+		if err != nil || len(bridges) != len(f.relationParts) {
+			return cost
+		}
+		if f.Count != nil {
+			cost += w.Aggregate
+		}
+		node = final
+	}
+	cost += f.Not.Cost(node, w) + f.And.Cost(node, w) + f.Or.Cost(node, w)
+	if len(f.fieldParts) > 0 {
+		hops, _, field := filterChainCost(node, f.fieldParts, w)
+		cost += hops + fieldCost(field, w)
+	}
+	return
+}
+
+func (sorts Sorts) Cost(node entx.Node, w *common.CostWeights) (cost int) {
+	for _, s := range sorts {
+		if s.alias != "" {
+			cost += w.AggregateSort
+			continue
+		}
+		hops, final, field := chainCost(node, s.fieldParts, w)
+		cost += hops
+		switch {
+		case s.Aggregate != "":
+			cost += w.AggregateSort
+			if final != nil {
+				cost += s.Filters.Cost(final, w)
+			}
+		case len(s.Values) > 0:
+			// the positions of the values are computed for each row, no index applies
+			cost += w.UnindexedField
+		default:
+			cost += fieldCost(field, w)
+		}
+	}
+	return
+}
+
+// Cost of aggregates computed per entity, each one being a sub-select.
+func (aggs Aggregates) Cost(node entx.Node, w *common.CostWeights) (cost int) {
+	for _, a := range aggs {
+		cost += a.BaseAggregate.cost(node, w)
+		if a.Recursive != nil {
+			_, _, bridges, _ := resolveChain(node, a.fieldParts)
+			cost += a.Recursive.recursionCost(bridges, w)
+		}
+	}
+	return
+}
+
+func (a *BaseAggregate) cost(node entx.Node, w *common.CostWeights) int {
+	hops, final, _ := chainCost(node, a.fieldParts, w)
+	return w.Aggregate + hops + a.Filters.Cost(final, w)
+}
+
+// Cost of includes at the given depth, the top-level includes being at depth 1.
+// The rows an include may load weigh its depth times its limit.
+// Each level of a recursive include is costed as an include nested in the previous one.
+func (incs Includes) Cost(node entx.Node, depth int, w *common.CostWeights) (cost int) {
+	for _, inc := range incs {
+		levels := 1
+		if inc.Recursive != nil {
+			levels = inc.Recursive.MaxDepth
+		}
+		final, _, bridges, err := resolveChain(node, inc.relationParts)
+		cost += hopsCost(bridges, w) + inc.Recursive.recursionCost(bridges, w)
+		for level := range levels {
+			cost += w.IncludeRow * (depth + level) * inc.Limit.Limit
+		}
+		if err != nil || len(bridges) != len(inc.relationParts) {
+			continue
+		}
+		scope := withPivot(final, bridges[len(bridges)-1], true)
+		for level := range levels {
+			cost += inc.Filters.Cost(scope, w) +
+				inc.Sort.Cost(scope, w) +
+				inc.Aggregates.Cost(final, w) +
+				inc.Includes.Cost(final, depth+level+1, w)
+		}
+	}
+	return
+}
+
+// LowerLimits halves the limits of the includes and of their nested includes, down to one row.
+// It reports whether a limit was lowered.
+func (incs Includes) LowerLimits() (lowered bool) {
+	for _, inc := range incs {
+		if inc.Limit.Limit > 1 {
+			inc.Limit.Limit /= 2
+			lowered = true
+		}
+		if inc.Includes.LowerLimits() {
+			lowered = true
+		}
+	}
+	return
+}
+
+func (oas OverallAggregates) Cost(graph entx.Graph, w *common.CostWeights) (cost int) {
+	for _, a := range oas {
+		cost += a.cost(graph, w)
+	}
+	return
+}
+
+func (a *OverallAggregate) cost(graph entx.Graph, w *common.CostWeights) int {
+	cost := w.Aggregate
+	if node := graph[a.fieldParts[0]]; node != nil {
+		cost += a.Filters.Cost(node, w)
+	}
+	return cost
+}
+
+func (gas GroupedAggregates) Cost(graph entx.Graph, w *common.CostWeights) (cost int) {
+	for _, g := range gas {
+		node := graph[g.From]
+		if node == nil {
+			continue
+		}
+		for _, parts := range g.groupParts {
+			hops, _, _ := chainCost(node, parts, w)
+			cost += hops
+		}
+		for _, a := range g.Aggregates {
+			hops, _, _ := chainCost(node, a.fieldParts, w)
+			cost += w.Aggregate + hops
+		}
+		cost += g.Filters.Cost(node, w)
+	}
+	return
+}
+
+func (hs DateHistograms) Cost(graph entx.Graph, w *common.CostWeights) (cost int) {
+	for _, h := range hs {
+		cost += h.OverallAggregate.cost(graph, w)
+	}
+	return
+}
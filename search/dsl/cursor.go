@@ -0,0 +1,281 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// Cursor enables keyset pagination. The position is built from the active sorts
+// followed by the node primary keys, which are appended as tie-breakers.
+type Cursor struct {
+	WithCursor bool   `json:"with_cursor,omitempty"`
+	After      string `json:"after,omitempty"`
+	Before     string `json:"before,omitempty"`
+	// pre-processed values
+	values []any
+}
+
+var (
+	ErrCursorSortUnsupported = "cursor pagination only supports plain sorts on fields of the queried node, got %q"
+	ErrCursorMismatch        = "cursor holds %d values but the sorts require %d"
+)
+
+const cursorKeyPrefix = "__cursor_"
+
+func (c *Cursor) IsCursor() bool {
+	return c.WithCursor || c.After != "" || c.Before != ""
+}
+
+func (c *Cursor) IsBackward() bool {
+	return c.Before != ""
+}
+
+// HasPosition reports whether a position to seek from has been given.
+func (c *Cursor) HasPosition() bool {
+	return c.After != "" || c.Before != ""
+}
+
+type cursorKey struct {
+	column string
+	desc   bool
+	// sentinel replaces the NULL values of a column which may hold them,
+	// the key being flagged as NULL apart.
+	sentinel string
+}
+
+func (k cursorKey) nullable() bool {
+	return k.sentinel != ""
+}
+
+// cursorNullSentinels are literals of each field type, selected instead of NULL values.
+var cursorNullSentinels = map[entx.FieldType]string{
+	entx.TypeInt:    "0",
+	entx.TypeFloat:  "0",
+	entx.TypeString: "''",
+	entx.TypeEnum:   "''",
+	entx.TypeUUID:   "'00000000-0000-0000-0000-000000000000'",
+	entx.TypeBool:   "FALSE",
+	entx.TypeTime:   "CURRENT_TIMESTAMP",
+}
+
+func (c *Cursor) resolveKeys(node entx.Node, sorts Sorts) ([]cursorKey, error) {
+	var (
+		pks  = node.PKs()
+		keys = make([]cursorKey, 0, len(sorts)+len(pks))
+		seen = make(map[string]struct{}, len(sorts)+len(pks))
+		desc bool
+	)
+	for _, s := range sorts {
+		f := node.FieldByName(s.Field)
+		if f == nil {
+			return nil, &common.QueryBuildError{
+				Op:  "Cursor.Predicate",
+				Err: fmt.Errorf(ErrNodeNotHaveField, node.Name(), s.Field),
+			}
+		}
+		desc = s.Direction == DirDESC
+		key := cursorKey{column: f.StorageName, desc: desc}
+		if f.IsNullable() {
+			sentinel, ok := cursorNullSentinels[f.Type]
+			if !ok {
+				return nil, &common.ValidationError{
+					Rule: "CursorSortUnsupported",
+					Err:  fmt.Errorf("cursor pagination does not support the nullable %s field %q", f.Type, s.Field),
+				}
+			}
+			key.sentinel = sentinel
+		}
+		keys = append(keys, key)
+		seen[f.StorageName] = struct{}{}
+	}
+	for _, pk := range pks {
+		if _, ok := seen[pk.StorageName]; !ok {
+			keys = append(keys, cursorKey{column: pk.StorageName, desc: desc})
+		}
+	}
+	return keys, nil
+}
+
+// Predicate orders the query by the cursor keys, seeks past the given position
+// and selects the keys under the returned aliases so that cursors can be computed from results.
+// Sorts must not be applied separately when the cursor mode is used.
+//
+// NULL values are ordered as the smallest ones, first in ascending order and last in descending
+// order, as MySQL and SQLite do by default.
+func (c *Cursor) Predicate(node entx.Node, sorts Sorts) (func(*sql.Selector), []common.CursorKey, error) {
+	keys, err := c.resolveKeys(node, sorts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, nil, &common.QueryBuildError{
+			Op:  "Cursor.Predicate",
+			Err: fmt.Errorf("node %q has no key to build a cursor from", node.Name()),
+		}
+	}
+
+	if c.HasPosition() && len(c.values) != len(keys) {
+		return nil, nil, &common.QueryBuildError{
+			Op:  "Cursor.Predicate",
+			Err: fmt.Errorf(ErrCursorMismatch, len(c.values), len(keys)),
+		}
+	}
+
+	aliases := make([]common.CursorKey, len(keys))
+	for i, k := range keys {
+		aliases[i].Alias = cursorKeyPrefix + strconv.Itoa(i)
+		if k.nullable() {
+			aliases[i].NullAlias = aliases[i].Alias + "_null"
+		}
+	}
+
+	backward := c.IsBackward()
+	return func(s *sql.Selector) {
+		if c.HasPosition() {
+			s.Where(seekPredicate(s, keys, c.values, backward))
+		}
+		for i, k := range keys {
+			col, desc := s.C(k.column), k.desc != backward
+			if !k.nullable() {
+				s.OrderBy(cursorOrder(col, desc))
+				s.AppendSelectAs(col, aliases[i].Alias)
+				continue
+			}
+			order := cursorOrder(col, desc)
+			// the NULL values are placed explicitly where MySQL places them by default
+			switch {
+			case s.Dialect() == dialect.MySQL:
+			case desc:
+				order += " NULLS LAST"
+			default:
+				order += " NULLS FIRST"
+			}
+			s.OrderBy(order)
+			s.AppendSelectExprAs(sql.Expr("COALESCE("+col+", "+k.sentinel+")"), aliases[i].Alias)
+			s.AppendSelectExprAs(sql.Expr("CASE WHEN "+col+" IS NULL THEN 1 ELSE 0 END"), aliases[i].NullAlias)
+		}
+	}, aliases, nil
+}
+
+func cursorOrder(col string, desc bool) string {
+	if desc {
+		return sql.Desc(col)
+	}
+	return sql.Asc(col)
+}
+
+// seekPredicate emits a row-value comparison when all keys share the same direction
+// and none may hold NULL, and falls back on its expanded form otherwise:
+//
+//	(a > ?) OR (a = ? AND b < ?) OR ...
+func seekPredicate(s *sql.Selector, keys []cursorKey, values []any, backward bool) *sql.Predicate {
+	greater := func(k cursorKey) bool { return k.desc == backward }
+
+	uniform := true
+	for _, k := range keys {
+		if greater(k) != greater(keys[0]) || k.nullable() {
+			uniform = false
+			break
+		}
+	}
+
+	if uniform {
+		cols := make([]string, len(keys))
+		for i, k := range keys {
+			cols[i] = s.C(k.column)
+		}
+		if greater(keys[0]) {
+			return sql.CompositeGT(cols, values...)
+		}
+		return sql.CompositeLT(cols, values...)
+	}
+
+	ors := make([]*sql.Predicate, 0, len(keys))
+	for i, k := range keys {
+		after := seekAfter(s.C(k.column), k, values[i], greater(k))
+		if after == nil {
+			continue
+		}
+		ands := make([]*sql.Predicate, 0, i+1)
+		for j := range i {
+			ands = append(ands, seekEqual(s.C(keys[j].column), values[j]))
+		}
+		ors = append(ors, sql.And(append(ands, after)...))
+	}
+	if len(ors) == 0 {
+		return sql.False()
+	}
+	return sql.Or(ors...)
+}
+
+func seekEqual(col string, v any) *sql.Predicate {
+	if v == nil {
+		return sql.IsNull(col)
+	}
+	return sql.EQ(col, v)
+}
+
+// seekAfter matches the values of a key ordered after the given one, NULL being the smallest value.
+// It returns nil when none is.
+func seekAfter(col string, k cursorKey, v any, greater bool) *sql.Predicate {
+	switch {
+	case greater && v == nil:
+		return sql.NotNull(col)
+	case greater:
+		return sql.GT(col, v)
+	case v == nil:
+		return nil
+	case k.nullable():
+		return sql.Or(sql.LT(col, v), sql.IsNull(col))
+	default:
+		return sql.LT(col, v)
+	}
+}
+
+func (c *Cursor) ValidateAndPreprocess(sorts Sorts, allowBackward bool) error {
+	if !c.IsCursor() {
+		return nil
+	}
+
+	if c.After != "" && c.Before != "" {
+		return &common.ValidationError{
+			Rule: "CursorDirectionConflict",
+			Err:  errors.New("after and before cannot be used together"),
+		}
+	}
+
+	if c.Before != "" && !allowBackward {
+		return &common.ValidationError{
+			Rule: "CursorBackwardNotAllowed",
+			Err:  errors.New("before cursor is not supported here"),
+		}
+	}
+
+	for _, s := range sorts {
+		if s.Aggregate != "" || len(s.fieldParts) != 1 || s.alias != "" || s.Nulls != "" || len(s.Values) > 0 {
+			return &common.ValidationError{
+				Rule: "CursorSortUnsupported",
+				Err:  fmt.Errorf(ErrCursorSortUnsupported, s.Field),
+			}
+		}
+	}
+
+	if pos := c.After + c.Before; pos != "" {
+		values, err := common.DecodeCursor(pos)
+		if err != nil {
+			return &common.ValidationError{
+				Rule: "InvalidCursor",
+				Err:  fmt.Errorf("malformed cursor: %w", err),
+			}
+		}
+		c.values = values
+	}
+	return nil
+}
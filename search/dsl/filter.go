@@ -2,7 +2,9 @@ package dsl
 
 import (
 	"fmt"
+	"time"
 
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/brice-74/entx"
 	"github.com/brice-74/entx/search/common"
@@ -22,6 +24,15 @@ const (
 	OpNotLike      Operator = "not like"
 	OpIn           Operator = "in"
 	OpNotIn        Operator = "not in"
+	OpBetween      Operator = "between"
+	OpNotBetween   Operator = "not between"
+	OpIsNull       Operator = "is null"
+	OpIsNotNull    Operator = "is not null"
+	OpStartsWith   Operator = "starts_with"
+	OpEndsWith     Operator = "ends_with"
+	OpILike        Operator = "ilike"
+	OpEqualFold    Operator = "equal_fold"
+	OpRegex        Operator = "regex"
 )
 
 type Filters []*Filter
@@ -49,9 +60,10 @@ func (fs Filters) ValidateAndPreprocess(cfg *common.FilterConfig) error {
 	var (
 		totalFilters   int
 		totalRelations int
+		now            = cfg.Now()
 	)
 	for i := range fs {
-		if err := fs[i].walkValidate(cfg.MaxRelationChainDepth, 0, &totalFilters, &totalRelations); err != nil {
+		if err := fs[i].walkValidate(cfg, 0, &totalFilters, &totalRelations, now); err != nil {
 			return err
 		}
 	}
@@ -78,10 +90,17 @@ type Filter struct {
 	Field    string   `json:"field,omitempty"`
 	Operator Operator `json:"operator,omitempty"`
 	Value    any      `json:"value,omitempty"`
+	// Quantifier and Count apply to the last segment of Relation.
+	Quantifier Quantifier     `json:"quantifier,omitempty"`
+	Count      *RelationCount `json:"count,omitempty"`
+	// Recursive follows the last segment of Relation transitively, it must relate a node to itself.
+	Recursive *Recursion `json:"recursive,omitempty"`
 	// pre-processed segments
 	relationParts []string
 	fieldParts    []string
-	preprocessed  bool
+	// reference time of relative time expressions
+	now          time.Time
+	preprocessed bool
 }
 
 func (f *Filter) Predicate(node entx.Node) (func(*sql.Selector), error) {
@@ -89,18 +108,20 @@ func (f *Filter) Predicate(node entx.Node) (func(*sql.Selector), error) {
 		panic("Filter.Predicate: called before preprocess")
 	}
 	if len(f.relationParts) > 0 {
-		_, finalNode, compose, err := resolveFilterChain(node, f.relationParts)
+		finalNode, _, bridges, err := resolvePivotChain(node, f.relationParts)
 		if err != nil {
 			return nil, &common.QueryBuildError{
 				Op:  "Filter.Predicate",
 				Err: err,
 			}
 		}
-		local, err := f.localPredicate(finalNode)
-		if err != nil {
-			return nil, err
+		if len(bridges) != len(f.relationParts) {
+			return nil, &common.QueryBuildError{
+				Op:  "Filter.Predicate",
+				Err: fmt.Errorf(ErrNotARelation, f.Relation, node.Name()),
+			}
 		}
-		return compose(local), nil
+		return f.relationPredicate(finalNode, bridges)
 	}
 	return f.localPredicate(node)
 }
@@ -108,11 +129,16 @@ func (f *Filter) Predicate(node entx.Node) (func(*sql.Selector), error) {
 // resolveFilterChain navigates a sequence of relations, returning the final Node
 // and a function to wrap a predicate across the chain.
 func resolveFilterChain(node entx.Node, rels []string) (string, entx.Node, func(func(*sql.Selector)) func(*sql.Selector), error) {
-	final, field, bridges, err := resolveChain(node, rels)
+	final, field, bridges, err := resolvePivotChain(node, rels)
 	if err != nil {
 		return "", nil, nil, err
 	}
+	return field, final, composeBridges(bridges), nil
+}
 
+// composeBridges returns a function wrapping a predicate so that it applies
+// through the given bridges, each one matching when any related row matches.
+func composeBridges(bridges []entx.Bridge) func(func(*sql.Selector)) func(*sql.Selector) {
 	compose := func(p func(*sql.Selector)) func(*sql.Selector) { return p }
 	for i := len(bridges) - 1; i >= 0; i-- {
 		b := bridges[i]
@@ -121,7 +147,7 @@ func resolveFilterChain(node entx.Node, rels []string) (string, entx.Node, func(
 			return b.FilterWith(prev(p))
 		}
 	}
-	return field, final, compose, nil
+	return compose
 }
 
 // localPredicate builds predicates for Not, Or, And and the leaf condition.
@@ -165,31 +191,38 @@ func (f *Filter) localPredicate(node entx.Node) (func(*sql.Selector), error) {
 }
 
 func (f *Filter) buildCondition(node entx.Node) (func(*sql.Selector), error) {
-	lenFieldParts := len(f.fieldParts)
-
-	if lenFieldParts > 1 {
-		field, _, compose, err := resolveFilterChain(node, f.fieldParts)
-		if err != nil {
-			return nil, &common.QueryBuildError{
-				Op:  "Filter.buildCondition",
-				Err: err,
-			}
+	name, final, compose, err := resolveFilterChain(node, f.fieldParts)
+	if err != nil {
+		return nil, &common.QueryBuildError{
+			Op:  "Filter.buildCondition",
+			Err: err,
 		}
+	}
 
-		base, err := buildBasePredicate(field, f.Operator, f.Value)
-		if err != nil {
-			return nil, err
+	field := final.FieldByName(name)
+	if field == nil {
+		return nil, &common.QueryBuildError{
+			Op:  "Filter.buildCondition",
+			Err: fmt.Errorf(ErrFieldMissing, f.Field, final.Name()),
 		}
+	}
 
-		return compose(base), nil
+	value := f.Value
+	if field.Type == entx.TypeTime {
+		if value, err = resolveTimeValue(value, f.now); err != nil {
+			return nil, &common.ValidationError{
+				Rule: "FilterTimeValue",
+				Err:  err,
+			}
+		}
 	}
 
-	field := f.fieldParts[lenFieldParts-1]
-	base, err := buildBasePredicate(field, f.Operator, f.Value)
+	base, err := buildBasePredicate(field.StorageName, f.Operator, value)
 	if err != nil {
 		return nil, err
 	}
-	return base, nil
+
+	return compose(base), nil
 }
 
 var (
@@ -222,6 +255,24 @@ func buildBasePredicate(
 		return func(s *sql.Selector) { s.Where(sql.In(s.C(field), value.([]any)...)) }, nil
 	case OpNotIn:
 		return func(s *sql.Selector) { s.Where(sql.Not(sql.In(s.C(field), value.([]any)...))) }, nil
+	case OpBetween:
+		return func(s *sql.Selector) { s.Where(between(s.C(field), value.([]any))) }, nil
+	case OpNotBetween:
+		return func(s *sql.Selector) { s.Where(sql.Not(between(s.C(field), value.([]any)))) }, nil
+	case OpIsNull:
+		return func(s *sql.Selector) { s.Where(sql.IsNull(s.C(field))) }, nil
+	case OpIsNotNull:
+		return func(s *sql.Selector) { s.Where(sql.NotNull(s.C(field))) }, nil
+	case OpStartsWith:
+		return func(s *sql.Selector) { s.Where(sql.HasPrefix(s.C(field), value.(string))) }, nil
+	case OpEndsWith:
+		return func(s *sql.Selector) { s.Where(sql.HasSuffix(s.C(field), value.(string))) }, nil
+	case OpILike:
+		return func(s *sql.Selector) { s.Where(sql.ContainsFold(s.C(field), value.(string))) }, nil
+	case OpEqualFold:
+		return func(s *sql.Selector) { s.Where(sql.EqualFold(s.C(field), value.(string))) }, nil
+	case OpRegex:
+		return func(s *sql.Selector) { s.Where(regex(s.C(field), value.(string))) }, nil
 	default:
 		return nil, &common.QueryBuildError{
 			Op:  "buildBasePredicate",
@@ -230,12 +281,54 @@ func buildBasePredicate(
 	}
 }
 
+func between(col string, bounds []any) *sql.Predicate {
+	return sql.P(func(b *sql.Builder) {
+		b.Ident(col).WriteString(" BETWEEN ").Arg(bounds[0]).WriteString(" AND ").Arg(bounds[1])
+	})
+}
+
+// regex matches col against a regular expression using the operator of the dialect.
+// SQLite only supports it when a REGEXP function is registered on the connection.
+func regex(col, pattern string) *sql.Predicate {
+	return sql.P(func(b *sql.Builder) {
+		b.Ident(col)
+		switch b.Dialect() {
+		case dialect.Postgres:
+			b.WriteString(" ~ ")
+		default:
+			b.WriteString(" REGEXP ")
+		}
+		b.Arg(pattern)
+	})
+}
+
 func (f *Filter) ValidateAndPreprocess(cfg *common.FilterConfig) error {
 	return Filters{f}.ValidateAndPreprocess(cfg)
 }
 
-func (f *Filter) walkValidate(maxDepth, currentDepth int, totalFilters, totalRelations *int) error {
+// relationDepth returns the length of the longest chain of relations followed by the filters,
+// including the relations of their fields.
+func (fs Filters) relationDepth() (depth int) {
+	for _, f := range fs {
+		depth = max(depth, f.relationDepth())
+	}
+	return
+}
+
+func (f *Filter) relationDepth() int {
+	if f == nil {
+		return 0
+	}
+	depth := len(f.relationParts)
+	if n := len(f.fieldParts); n > 1 {
+		depth += n - 1
+	}
+	return depth + max(f.And.relationDepth(), f.Or.relationDepth(), f.Not.relationDepth())
+}
+
+func (f *Filter) walkValidate(cfg *common.FilterConfig, currentDepth int, totalFilters, totalRelations *int, now time.Time) error {
 	*totalFilters++
+	f.now = now
 
 	if f.Relation != "" {
 		parts, pos, ok := splitChain(f.Relation)
@@ -275,7 +368,7 @@ func (f *Filter) walkValidate(maxDepth, currentDepth int, totalFilters, totalRel
 			}
 		}
 	case OpEqual, OpNotEqual:
-		if !IsPrimitive(f.Value) {
+		if !IsPrimitive(f.Value) && !IsTime(f.Value) {
 			return &common.ValidationError{
 				Rule: "OperatorPrimitiveValue",
 				Err:  fmt.Errorf("'%s' operator need primitive type value, got %T", op, f.Value),
@@ -283,13 +376,29 @@ func (f *Filter) walkValidate(maxDepth, currentDepth int, totalFilters, totalRel
 		}
 	case OpGreaterThan, OpGreaterEqual,
 		OpLessThan, OpLessEqual:
-		if !IsNumber(f.Value) {
+		if !IsNumber(f.Value) && !IsTime(f.Value) && !IsTimeString(f.Value, now) {
 			return &common.ValidationError{
 				Rule: "OperatorNumberValue",
-				Err:  fmt.Errorf("'%s' operator need number type value, got %T", op, f.Value),
+				Err:  fmt.Errorf("'%s' operator need number or time value, got %T", op, f.Value),
+			}
+		}
+	case OpBetween, OpNotBetween:
+		if !IsRange(f.Value, now) {
+			return &common.ValidationError{
+				Rule: "OperatorRangeValue",
+				Err:  fmt.Errorf("'%s' operator need a slice of two number or time values, got %v", op, f.Value),
+			}
+		}
+	case OpIsNull, OpIsNotNull:
+		if f.Value != nil {
+			return &common.ValidationError{
+				Rule: "OperatorNullValue",
+				Err:  fmt.Errorf("'%s' operator does not accept a value, got %T", op, f.Value),
 			}
 		}
-	case OpLike, OpNotLike:
+	case OpLike, OpNotLike,
+		OpStartsWith, OpEndsWith,
+		OpILike, OpEqualFold, OpRegex:
 		if !IsString(f.Value) {
 			return &common.ValidationError{
 				Rule: "OperatorStringValue",
@@ -303,7 +412,15 @@ func (f *Filter) walkValidate(maxDepth, currentDepth int, totalFilters, totalRel
 		}
 	}
 
-	if maxDepth > 0 && currentDepth > maxDepth {
+	if err := f.validateQuantifier(); err != nil {
+		return err
+	}
+
+	if err := f.validateRecursion(cfg); err != nil {
+		return err
+	}
+
+	if maxDepth := cfg.MaxRelationChainDepth; maxDepth > 0 && currentDepth > maxDepth {
 		return &common.ValidationError{
 			Rule: "MaxRelationChainDepth",
 			Err:  fmt.Errorf("filters nesting depth exceeds max %d", maxDepth),
@@ -311,17 +428,17 @@ func (f *Filter) walkValidate(maxDepth, currentDepth int, totalFilters, totalRel
 	}
 
 	if f.Not != nil {
-		if err := f.Not.walkValidate(maxDepth, currentDepth, totalFilters, totalRelations); err != nil {
+		if err := f.Not.walkValidate(cfg, currentDepth, totalFilters, totalRelations, now); err != nil {
 			return err
 		}
 	}
 	for i := range f.And {
-		if err := f.And[i].walkValidate(maxDepth, currentDepth, totalFilters, totalRelations); err != nil {
+		if err := f.And[i].walkValidate(cfg, currentDepth, totalFilters, totalRelations, now); err != nil {
 			return err
 		}
 	}
 	for i := range f.Or {
-		if err := f.Or[i].walkValidate(maxDepth, currentDepth, totalFilters, totalRelations); err != nil {
+		if err := f.Or[i].walkValidate(cfg, currentDepth, totalFilters, totalRelations, now); err != nil {
 			return err
 		}
 	}
@@ -363,6 +480,25 @@ func IsNumber(val any) bool {
 	}
 }
 
+// IsRange reports whether val is a slice of two number or time bounds.
+func IsRange(val any, now time.Time) bool {
+	bounds, ok := val.([]any)
+	if !ok || len(bounds) != 2 {
+		return false
+	}
+	for _, b := range bounds {
+		if !IsNumber(b) && !IsTime(b) && !IsTimeString(b, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func IsTime(val any) bool {
+	_, ok := val.(time.Time)
+	return ok
+}
+
 func IsStringOrNumber(val any) bool {
 	switch val.(type) {
 	case string, int, int8, int16, int32, int64,
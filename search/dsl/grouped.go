@@ -0,0 +1,467 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+	"golang.org/x/sync/errgroup"
+)
+
+// GroupedAggregate groups the rows of a node by one or more fields and computes
+// aggregates per group, each group being returned as a bucket.
+type GroupedAggregate struct {
+	// From is the name of the grouped node.
+	From string `json:"from"`
+	// Alias is the response key of the buckets.
+	Alias string `json:"alias,omitempty"`
+	// GroupBy fields belong to the node or to nodes reached through M2O/O2O chains.
+	GroupBy    []string         `json:"group_by"`
+	Aggregates []*BaseAggregate `json:"aggregates"`
+	Filters    Filters          `json:"filters,omitempty"`
+	Having     []*Having        `json:"having,omitempty"`
+	Sorts      []*BucketSort    `json:"sorts,omitempty"`
+	Limit      int              `json:"limit,omitempty"`
+	// pre-processed segments
+	groupParts   [][]string
+	preprocessed bool
+}
+
+// Having filters buckets on the value of one of their aggregates.
+type Having struct {
+	Aggregate string   `json:"aggregate"`
+	Operator  Operator `json:"operator"`
+	Value     any      `json:"value"`
+}
+
+// BucketSort orders buckets by a grouped field or an aggregate alias.
+type BucketSort struct {
+	Field     string    `json:"field"`
+	Direction Direction `json:"direction,omitempty"`
+}
+
+var (
+	ErrGroupByRelation = "cannot group by %q: relation %q of node %q may return several rows, only M2O and O2O relations are allowed"
+	ErrGroupByType     = "cannot group by field %q of type %s"
+)
+
+const bucketKeyPrefix = "key_"
+
+// groupJoiner left joins the single-row relations traversed by grouped fields,
+// each relation chain being joined only once.
+type groupJoiner struct {
+	builder *sql.DialectBuilder
+	sel     *sql.Selector
+	root    *sql.SelectTable
+	tables  map[string]*sql.SelectTable
+}
+
+func (j *groupJoiner) resolve(node entx.Node, parts []string) (*sql.SelectTable, *entx.Field, error) {
+	table := j.root
+	for i, seg := range parts[:len(parts)-1] {
+		b := node.Bridge(seg)
+		if b == nil {
+			return nil, nil, fmt.Errorf(ErrUnknownLink, seg, node.Name())
+		}
+		rel := b.RelInfos()
+		if rel.RelType != sqlgraph.M2O && rel.RelType != sqlgraph.O2O {
+			return nil, nil, fmt.Errorf(ErrGroupByRelation, strings.Join(parts, "."), seg, node.Name())
+		}
+
+		chain := strings.Join(parts[:i+1], ".")
+		next, ok := j.tables[chain]
+		if !ok {
+			next = j.builder.Table(b.Child().Table()).As("t" + strconv.Itoa(len(j.tables)+1))
+			j.sel.LeftJoin(next).On(next.C(rel.FinalRightField), table.C(rel.FinalLeftField))
+			j.tables[chain] = next
+		}
+		table, node = next, b.Child()
+	}
+
+	f := node.FieldByName(parts[len(parts)-1])
+	if f == nil {
+		return nil, nil, fmt.Errorf(ErrNodeNotHaveField, node.Name(), parts[len(parts)-1])
+	}
+	return table, f, nil
+}
+
+// Build constructs the bucket query of the grouped aggregate.
+func (g *GroupedAggregate) Build(ctx context.Context, graph entx.Graph, dialect string) (*common.BucketQuery, error) {
+	if !g.preprocessed {
+		panic("GroupedAggregate.Build: called before preprocess")
+	}
+
+	node := graph[g.From]
+	if node == nil {
+		return nil, &common.QueryBuildError{
+			Op:  "GroupedAggregate.Build",
+			Err: fmt.Errorf(ErrNodeNotExist, g.From),
+		}
+	}
+
+	policyPred, err := common.EnforcePolicy(ctx, node, common.OpAggregateGrouped)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := sql.Dialect(dialect)
+	root := builder.Table(node.Table()).As("t0")
+	sel := builder.Select().From(root)
+	joiner := &groupJoiner{builder: builder, sel: sel, root: root, tables: make(map[string]*sql.SelectTable)}
+
+	var (
+		dests   = make([]func() any, 0, len(g.GroupBy)+len(g.Aggregates))
+		aliases = make(map[string]string, len(g.GroupBy)+len(g.Aggregates))
+		exprs   = make(map[string]string, len(g.Aggregates))
+		values  = make([]string, len(g.Aggregates))
+	)
+	for i, parts := range g.groupParts {
+		table, f, err := joiner.resolve(node, parts)
+		if err != nil {
+			return nil, &common.QueryBuildError{
+				Op:  "GroupedAggregate.Build",
+				Err: err,
+			}
+		}
+		alias := bucketKeyPrefix + strconv.Itoa(i)
+		sel.AppendSelectAs(table.C(f.StorageName), alias).GroupBy(table.C(f.StorageName))
+		aliases[g.GroupBy[i]] = alias
+		dests = append(dests, fieldDest(f))
+	}
+
+	for i, a := range g.Aggregates {
+		var (
+			table = root
+			field string
+			dest  = aggDest(a.Type)
+		)
+		if len(a.fieldParts) > 0 {
+			t, f, err := joiner.resolve(node, a.fieldParts)
+			if err != nil {
+				return nil, &common.QueryBuildError{
+					Op:  "GroupedAggregate.Build",
+					Err: err,
+				}
+			}
+			if a.Type == AggMin || a.Type == AggMax {
+				dest = fieldDest(f)
+			}
+			table, field = t, f.StorageName
+		}
+
+		fn, expr, alias, err := a.buildExpr(dialect, table, field)
+		if err != nil {
+			return nil, err
+		}
+		sel.AppendSelectExprAs(sql.Raw(fn(expr)), alias)
+		exprs[alias] = fn(expr)
+		aliases[alias] = alias
+		values[i] = alias
+		dests = append(dests, dest)
+	}
+
+	if policyPred != nil {
+		policyPred(sel)
+	}
+	preds, err := g.Filters.Predicate(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range preds {
+		p(sel)
+	}
+
+	if len(g.Having) > 0 {
+		havings := make([]*sql.Predicate, len(g.Having))
+		for i, h := range g.Having {
+			havings[i] = h.predicate(exprs[h.Aggregate])
+		}
+		sel.Having(sql.And(havings...))
+	}
+
+	for _, s := range g.Sorts {
+		alias := aliases[s.Field]
+		desc := s.Direction == DirDESC
+		sel.OrderExprFunc(func(b *sql.Builder) {
+			b.Ident(alias)
+			if desc {
+				b.WriteString(" DESC")
+			}
+		})
+	}
+
+	if g.Limit > 0 {
+		sel.Limit(g.Limit)
+	}
+
+	return &common.BucketQuery{
+		Selector:   sel,
+		Key:        g.Alias,
+		KeyNames:   g.GroupBy,
+		ValueNames: values,
+		NewDests: func() []any {
+			row := make([]any, len(dests))
+			for i, d := range dests {
+				row[i] = d()
+			}
+			return row
+		},
+	}, nil
+}
+
+// fieldDest returns a scan destination constructor matching the field type.
+func fieldDest(f *entx.Field) func() any {
+	switch f.Type {
+	case entx.TypeInt:
+		return func() any { return new(sql.NullInt64) }
+	case entx.TypeFloat:
+		return func() any { return new(sql.NullFloat64) }
+	case entx.TypeString, entx.TypeEnum, entx.TypeUUID:
+		return func() any { return new(sql.NullString) }
+	case entx.TypeBool:
+		return func() any { return new(sql.NullBool) }
+	case entx.TypeTime:
+		return func() any { return new(sql.NullTime) }
+	default:
+		return func() any { return new(any) }
+	}
+}
+
+func (h *Having) predicate(expr string) *sql.Predicate {
+	p := sql.P(func(b *sql.Builder) {
+		b.WriteString(expr)
+		switch h.Operator {
+		case OpBetween, OpNotBetween:
+			bounds := h.Value.([]any)
+			b.WriteString(" BETWEEN ").Arg(bounds[0]).WriteString(" AND ").Arg(bounds[1])
+		default:
+			b.WriteOp(countOps[h.Operator]).Arg(h.Value)
+		}
+	})
+	if h.Operator == OpNotBetween {
+		return sql.Not(p)
+	}
+	return p
+}
+
+func (h *Having) validate(aliases []string) error {
+	if !slices.Contains(aliases, h.Aggregate) {
+		return &common.ValidationError{
+			Rule: "HavingUnknownAggregate",
+			Err:  fmt.Errorf("having refers to %q which is not an aggregate alias, expected one of %q", h.Aggregate, aliases),
+		}
+	}
+	switch h.Operator {
+	case OpBetween, OpNotBetween:
+		bounds, ok := h.Value.([]any)
+		if !ok || len(bounds) != 2 || !IsNumber(bounds[0]) || !IsNumber(bounds[1]) {
+			return &common.ValidationError{
+				Rule: "HavingValue",
+				Err:  fmt.Errorf("'%s' having operator need a slice of two numbers, got %v", h.Operator, h.Value),
+			}
+		}
+	default:
+		if _, ok := countOps[h.Operator]; !ok {
+			return &common.ValidationError{
+				Rule: "HavingOperator",
+				Err:  fmt.Errorf("unsupported having operator %q", h.Operator),
+			}
+		}
+		if !IsNumber(h.Value) {
+			return &common.ValidationError{
+				Rule: "HavingValue",
+				Err:  fmt.Errorf("'%s' having operator need a number value, got %T", h.Operator, h.Value),
+			}
+		}
+	}
+	return nil
+}
+
+func (g *GroupedAggregate) ValidateAndPreprocess(cfg *common.Config) error {
+	if g.From == "" {
+		return &common.ValidationError{
+			Rule: "GroupedAggregateFromEmpty",
+			Err:  errors.New("grouped aggregate must target a node"),
+		}
+	}
+
+	if len(g.GroupBy) == 0 {
+		return &common.ValidationError{
+			Rule: "GroupByEmpty",
+			Err:  errors.New("grouped aggregate must group by at least one field"),
+		}
+	}
+	g.groupParts = make([][]string, len(g.GroupBy))
+	for i, field := range g.GroupBy {
+		parts, pos, ok := splitChain(field)
+		if !ok {
+			return &common.ValidationError{
+				Rule: "GroupByFieldSyntax",
+				Err:  fmt.Errorf("invalid empty segment at char %d in %q", pos, field),
+			}
+		}
+		if slices.Contains(g.GroupBy[:i], field) {
+			return &common.ValidationError{
+				Rule: "GroupByDuplicate",
+				Err:  fmt.Errorf("field %q is grouped more than once", field),
+			}
+		}
+		g.groupParts[i] = parts
+	}
+
+	if len(g.Aggregates) == 0 {
+		return &common.ValidationError{
+			Rule: "GroupedAggregatesEmpty",
+			Err:  errors.New("grouped aggregate must compute at least one aggregate"),
+		}
+	}
+	aliases := make([]string, len(g.Aggregates))
+	for i, a := range g.Aggregates {
+		if len(a.Filters) > 0 {
+			return &common.ValidationError{
+				Rule: "GroupedAggregateFilters",
+				Err:  errors.New("aggregates of a grouped aggregate cannot be filtered individually, use the grouped aggregate filters"),
+			}
+		}
+		if err := a.preprocess(&cfg.FilterConfig, true); err != nil {
+			return err
+		}
+		alias := a.alias()
+		if slices.Contains(aliases[:i], alias) || slices.Contains(g.GroupBy, alias) {
+			return &common.ValidationError{
+				Rule: "GroupedAggregateAliasDuplicate",
+				Err:  fmt.Errorf("alias %q is used more than once", alias),
+			}
+		}
+		aliases[i] = alias
+	}
+
+	if g.Alias == "" {
+		g.Alias = fmt.Sprintf("%s_by_%s", strings.ToLower(g.From), strings.ReplaceAll(strings.Join(g.GroupBy, "_"), ".", "_"))
+	}
+
+	for _, h := range g.Having {
+		if err := h.validate(aliases); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range g.Sorts {
+		switch s.Direction {
+		case DirASC, DirDESC, "":
+		default:
+			return &common.ValidationError{
+				Rule: "SortDirection",
+				Err:  fmt.Errorf("unsupported direction %q", s.Direction),
+			}
+		}
+		if !slices.Contains(g.GroupBy, s.Field) && !slices.Contains(aliases, s.Field) {
+			return &common.ValidationError{
+				Rule: "BucketSortUnknownField",
+				Err:  fmt.Errorf("cannot sort buckets by %q, expected a grouped field or an aggregate alias", s.Field),
+			}
+		}
+	}
+
+	switch {
+	case g.Limit < 0:
+		return &common.ValidationError{
+			Rule: "BucketLimit",
+			Err:  fmt.Errorf("bucket limit must be positive, got %d", g.Limit),
+		}
+	case cfg.MaxBuckets > 0 && g.Limit > cfg.MaxBuckets:
+		return &common.ValidationError{
+			Rule: "MaxBuckets",
+			Err:  fmt.Errorf("bucket limit %d exceeds max %d", g.Limit, cfg.MaxBuckets),
+		}
+	case g.Limit == 0:
+		g.Limit = cfg.MaxBuckets
+	}
+
+	if err := g.Filters.ValidateAndPreprocess(&cfg.FilterConfig); err != nil {
+		return err
+	}
+
+	g.preprocessed = true
+	return nil
+}
+
+type GroupedAggregates []*GroupedAggregate
+
+func (gas GroupedAggregates) Execute(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *common.Config,
+) (common.AggregatesResponse, error) {
+	ctx, cancel := common.ContextTimeout(ctx, cfg.RequestTimeout)
+	defer cancel()
+
+	err, count := gas.ValidateAndPreprocessFinal(cfg), len(gas)
+	if err == nil {
+		err = gas.CheckTypes(graph)
+	}
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	queries, err := gas.BuildBuckets(ctx, graph, cfg.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	wg, wgctx := errgroup.WithContext(ctx)
+	wg.SetLimit(min(count, cfg.MaxParallelWorkersPerRequest))
+
+	res := common.NewMapSync(make(map[string]any, count))
+	common.ExecuteBucketQueriesAsync(wgctx, wg, client, res, queries...)
+
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return res.UnsafeRaw(), nil
+}
+
+func (gas GroupedAggregates) BuildBuckets(ctx context.Context, graph entx.Graph, dialect string) ([]*common.BucketQuery, error) {
+	if length := len(gas); length > 0 {
+		var queries = make([]*common.BucketQuery, length)
+
+		for i, ga := range gas {
+			q, err := ga.Build(ctx, graph, dialect)
+			if err != nil {
+				return nil, err
+			}
+			queries[i] = q
+		}
+
+		return queries, nil
+	}
+	return nil, nil
+}
+
+func (gas GroupedAggregates) ValidateAndPreprocessFinal(cfg *common.Config) error {
+	count, err := gas.ValidateAndPreprocess(cfg)
+	if err != nil {
+		return err
+	}
+
+	return common.CheckMaxAggregates(cfg, count)
+}
+
+func (gas GroupedAggregates) ValidateAndPreprocess(cfg *common.Config) (count int, err error) {
+	for _, ga := range gas {
+		if err = ga.ValidateAndPreprocess(cfg); err != nil {
+			return
+		}
+		count++
+	}
+	return
+}
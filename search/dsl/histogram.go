@@ -0,0 +1,414 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+	"golang.org/x/sync/errgroup"
+)
+
+type Interval string
+
+const (
+	IntervalMinute Interval = "minute"
+	IntervalHour   Interval = "hour"
+	IntervalDay    Interval = "day"
+	IntervalWeek   Interval = "week"
+	IntervalMonth  Interval = "month"
+	IntervalYear   Interval = "year"
+)
+
+// next returns the start of the bucket following the one starting at t.
+// Wall clock arithmetic keeps buckets aligned across DST changes.
+func (i Interval) next(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, mi, _ := t.Clock()
+	switch i {
+	case IntervalMinute:
+		mi++
+	case IntervalHour:
+		h++
+	case IntervalDay:
+		d++
+	case IntervalWeek:
+		d += 7
+	case IntervalMonth:
+		mo++
+	case IntervalYear:
+		y++
+	}
+	return time.Date(y, mo, d, h, mi, 0, 0, t.Location())
+}
+
+// DateHistogram computes an overall aggregate per time interval of a time field,
+// each interval being returned as a bucket keyed by its start.
+// Weeks start on monday.
+type DateHistogram struct {
+	OverallAggregate
+	// On is the time field of the aggregated node used to bucket rows.
+	On       string   `json:"on"`
+	Interval Interval `json:"interval"`
+	// Timezone is an IANA name (e.g. "Europe/Paris") or a fixed offset (e.g. "+02:00")
+	// in which bucket boundaries are computed. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+	// FillEmpty adds the buckets without rows between the first and the last bucket.
+	FillEmpty bool `json:"fill_empty,omitempty"`
+	// pre-processed values
+	loc        *time.Location
+	fixedTZ    bool
+	valueAlias string
+	maxBuckets int
+}
+
+var (
+	ErrHistogramFieldType       = "cannot bucket on field %q of type %s, a time field is required"
+	ErrHistogramTimezoneDialect = "dialect %q only supports fixed offset timezones, got %q"
+	ErrHistogramDialect         = "date histogram is not supported by dialect %q"
+	ErrHistogramTimezoneNull    = "timezone %q is not resolved by the database, the buckets of field %q have a null key"
+)
+
+const histogramKeyLayout = time.DateTime
+
+var fixedOffsetRegex = regexp.MustCompile(`^[+-]\d{2}:\d{2}$`)
+
+var (
+	mysqlTruncFormats = map[Interval]string{
+		IntervalMinute: "%Y-%m-%d %H:%i:00",
+		IntervalHour:   "%Y-%m-%d %H:00:00",
+		IntervalDay:    "%Y-%m-%d 00:00:00",
+		IntervalWeek:   "%Y-%m-%d 00:00:00",
+		IntervalMonth:  "%Y-%m-01 00:00:00",
+		IntervalYear:   "%Y-01-01 00:00:00",
+	}
+	sqliteTruncFormats = map[Interval]string{
+		IntervalMinute: "%Y-%m-%d %H:%M:00",
+		IntervalHour:   "%Y-%m-%d %H:00:00",
+		IntervalDay:    "%Y-%m-%d 00:00:00",
+		IntervalWeek:   "%Y-%m-%d 00:00:00",
+		IntervalMonth:  "%Y-%m-01 00:00:00",
+		IntervalYear:   "%Y-01-01 00:00:00",
+	}
+)
+
+// truncate returns the expression formatting the column as the start of its bucket,
+// a histogramKeyLayout string in the histogram timezone.
+func (h *DateHistogram) truncate(d, col string) (sql.Querier, error) {
+	switch d {
+	case dialect.MySQL:
+		local := func(b *sql.Builder) {
+			if h.loc == time.UTC {
+				b.WriteString(col)
+				return
+			}
+			b.WriteString("CONVERT_TZ(").WriteString(col).WriteString(", '+00:00', ").Arg(h.Timezone).WriteByte(')')
+		}
+		return sql.ExprFunc(func(b *sql.Builder) {
+			b.WriteString("DATE_FORMAT(")
+			if h.Interval == IntervalWeek {
+				b.WriteString("DATE_SUB(")
+				local(b)
+				b.WriteString(", INTERVAL WEEKDAY(")
+				local(b)
+				b.WriteString(") DAY)")
+			} else {
+				local(b)
+			}
+			b.WriteString(", '" + mysqlTruncFormats[h.Interval] + "')")
+		}), nil
+	case dialect.Postgres:
+		return sql.ExprFunc(func(b *sql.Builder) {
+			b.WriteString("to_char(date_trunc('" + string(h.Interval) + "', ").WriteString(col).WriteString(" AT TIME ZONE ")
+			if h.fixedTZ {
+				// a plain offset string follows the POSIX sign convention, an interval does not.
+				b.WriteString("CAST(").Arg(h.Timezone).WriteString(" AS INTERVAL)")
+			} else {
+				b.Arg(h.loc.String())
+			}
+			b.WriteString("), 'YYYY-MM-DD HH24:MI:SS')")
+		}), nil
+	case dialect.SQLite:
+		if h.loc != time.UTC && !h.fixedTZ {
+			return nil, &common.QueryBuildError{
+				Op:  "DateHistogram.truncate",
+				Err: fmt.Errorf(ErrHistogramTimezoneDialect, d, h.Timezone),
+			}
+		}
+		return sql.ExprFunc(func(b *sql.Builder) {
+			b.WriteString("strftime('" + sqliteTruncFormats[h.Interval] + "', ").WriteString(col)
+			if h.fixedTZ {
+				_, offset := time.Now().In(h.loc).Zone()
+				b.WriteString(", ").Arg(strconv.Itoa(offset/60) + " minutes")
+			}
+			if h.Interval == IntervalWeek {
+				b.WriteString(", 'weekday 0', '-6 days'")
+			}
+			b.WriteByte(')')
+		}), nil
+	default:
+		return nil, &common.QueryBuildError{
+			Op:  "DateHistogram.truncate",
+			Err: fmt.Errorf(ErrHistogramDialect, d),
+		}
+	}
+}
+
+// Build constructs the bucket query of the date histogram.
+func (h *DateHistogram) Build(ctx context.Context, graph entx.Graph, dialect string) (*common.BucketQuery, error) {
+	if !h.preprocessed {
+		panic("DateHistogram.Build: called before preprocess")
+	}
+
+	node, field, err := h.resolveField(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	on := node.FieldByName(h.On)
+	if on == nil {
+		return nil, &common.QueryBuildError{
+			Op:  "DateHistogram.Build",
+			Err: fmt.Errorf(ErrNodeNotHaveField, node.Name(), h.On),
+		}
+	}
+
+	policyPred, err := common.EnforcePolicy(ctx, node, common.OpAggregateHistogram)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := sql.Dialect(dialect)
+	tbl := builder.Table(node.Table()).As("t0")
+	key, err := h.truncate(dialect, tbl.C(on.StorageName))
+	if err != nil {
+		return nil, err
+	}
+	fn, expr, _, err := h.buildExpr(dialect, tbl, field)
+	if err != nil {
+		return nil, err
+	}
+
+	keyAlias := bucketKeyPrefix + "0"
+	sel := builder.Select().From(tbl).
+		AppendSelectExprAs(key, keyAlias).
+		AppendSelectExprAs(sql.Raw(fn(expr)), h.valueAlias).
+		GroupBy(keyAlias).
+		OrderBy(keyAlias)
+	if on.IsNullable() {
+		sel.Where(sql.NotNull(tbl.C(on.StorageName)))
+	}
+	if policyPred != nil {
+		policyPred(sel)
+	}
+	preds, err := h.Filters.Predicate(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range preds {
+		p(sel)
+	}
+	if h.maxBuckets > 0 {
+		sel.Limit(h.maxBuckets)
+	}
+
+	valueDest := aggDest(h.Type)
+	if h.Type == AggMin || h.Type == AggMax {
+		valueDest = fieldDest(node.FieldByName(h.fieldParts[1]))
+	}
+
+	return &common.BucketQuery{
+		Selector:   sel,
+		Key:        h.Alias,
+		KeyNames:   []string{h.On},
+		ValueNames: []string{h.valueAlias},
+		NewDests: func() []any {
+			return []any{new(sql.NullString), valueDest()}
+		},
+		Finalize: h.finalize,
+	}, nil
+}
+
+// finalize parses the bucket keys and fills empty buckets if requested.
+func (h *DateHistogram) finalize(buckets []*common.Bucket) ([]*common.Bucket, error) {
+	for _, b := range buckets {
+		// rows with a null time are filtered out, a null key comes from a timezone
+		// the database cannot convert to, such as MySQL without its time zone tables.
+		s, ok := b.Keys[h.On].(string)
+		if !ok {
+			return nil, &common.ExecError{
+				Op:  "DateHistogram.finalize",
+				Err: fmt.Errorf(ErrHistogramTimezoneNull, h.Timezone, h.On),
+			}
+		}
+		t, err := time.ParseInLocation(histogramKeyLayout, s, h.loc)
+		if err != nil {
+			return nil, err
+		}
+		b.Keys[h.On] = t
+	}
+	if !h.FillEmpty || len(buckets) < 2 {
+		return buckets, nil
+	}
+
+	filled := make([]*common.Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		if n := len(filled); n > 0 {
+			end := b.Keys[h.On].(time.Time)
+			for t := h.Interval.next(filled[n-1].Keys[h.On].(time.Time)); t.Before(end); t = h.Interval.next(t) {
+				if h.maxBuckets > 0 && len(filled) >= h.maxBuckets {
+					return filled, nil
+				}
+				filled = append(filled, h.emptyBucket(t))
+			}
+		}
+		if h.maxBuckets > 0 && len(filled) >= h.maxBuckets {
+			return filled, nil
+		}
+		filled = append(filled, b)
+	}
+	return filled, nil
+}
+
+func (h *DateHistogram) emptyBucket(start time.Time) *common.Bucket {
+	var value any
+	if h.Type == AggCount || h.Type == AggCountDistinct {
+		value = int64(0)
+	}
+	return &common.Bucket{
+		Keys:   map[string]any{h.On: start},
+		Values: map[string]any{h.valueAlias: value},
+	}
+}
+
+func (h *DateHistogram) ValidateAndPreprocess(cfg *common.Config) error {
+	if err := h.OverallAggregate.ValidateAndPreprocess(cfg); err != nil {
+		return err
+	}
+
+	if h.On == "" {
+		return &common.ValidationError{
+			Rule: "HistogramFieldEmpty",
+			Err:  errors.New("date histogram must bucket on a time field"),
+		}
+	}
+
+	switch h.Interval {
+	case IntervalMinute, IntervalHour, IntervalDay, IntervalWeek, IntervalMonth, IntervalYear:
+	default:
+		return &common.ValidationError{
+			Rule: "HistogramInterval",
+			Err:  fmt.Errorf("unsupported histogram interval %q", h.Interval),
+		}
+	}
+
+	switch {
+	case h.Timezone == "":
+		h.loc = time.UTC
+	case fixedOffsetRegex.MatchString(h.Timezone):
+		t, err := time.Parse("-07:00", h.Timezone)
+		if err != nil {
+			return &common.ValidationError{
+				Rule: "HistogramTimezone",
+				Err:  fmt.Errorf("invalid timezone offset %q: %w", h.Timezone, err),
+			}
+		}
+		_, offset := t.Zone()
+		h.loc, h.fixedTZ = time.FixedZone(h.Timezone, offset), true
+	default:
+		loc, err := time.LoadLocation(h.Timezone)
+		if err != nil {
+			return &common.ValidationError{
+				Rule: "HistogramTimezone",
+				Err:  fmt.Errorf("unknown timezone %q: %w", h.Timezone, err),
+			}
+		}
+		h.loc = loc
+	}
+
+	h.valueAlias = h.BaseAggregate.alias()
+	if h.Alias == "" {
+		h.Alias = fmt.Sprintf("%s_per_%s", h.valueAlias, h.Interval)
+	}
+	h.maxBuckets = cfg.MaxBuckets
+	return nil
+}
+
+type DateHistograms []*DateHistogram
+
+func (hs DateHistograms) Execute(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *common.Config,
+) (common.AggregatesResponse, error) {
+	ctx, cancel := common.ContextTimeout(ctx, cfg.RequestTimeout)
+	defer cancel()
+
+	err, count := hs.ValidateAndPreprocessFinal(cfg), len(hs)
+	if err == nil {
+		err = hs.CheckTypes(graph)
+	}
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	queries, err := hs.BuildBuckets(ctx, graph, cfg.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	wg, wgctx := errgroup.WithContext(ctx)
+	wg.SetLimit(min(count, cfg.MaxParallelWorkersPerRequest))
+
+	res := common.NewMapSync(make(map[string]any, count))
+	common.ExecuteBucketQueriesAsync(wgctx, wg, client, res, queries...)
+
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return res.UnsafeRaw(), nil
+}
+
+func (hs DateHistograms) BuildBuckets(ctx context.Context, graph entx.Graph, dialect string) ([]*common.BucketQuery, error) {
+	if length := len(hs); length > 0 {
+		var queries = make([]*common.BucketQuery, length)
+
+		for i, h := range hs {
+			q, err := h.Build(ctx, graph, dialect)
+			if err != nil {
+				return nil, err
+			}
+			queries[i] = q
+		}
+
+		return queries, nil
+	}
+	return nil, nil
+}
+
+func (hs DateHistograms) ValidateAndPreprocessFinal(cfg *common.Config) error {
+	count, err := hs.ValidateAndPreprocess(cfg)
+	if err != nil {
+		return err
+	}
+
+	return common.CheckMaxAggregates(cfg, count)
+}
+
+func (hs DateHistograms) ValidateAndPreprocess(cfg *common.Config) (count int, err error) {
+	for _, h := range hs {
+		if err = h.ValidateAndPreprocess(cfg); err != nil {
+			return
+		}
+		count++
+	}
+	return
+}
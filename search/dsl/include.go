@@ -2,9 +2,13 @@ package dsl
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
 	"github.com/brice-74/entx"
 	"github.com/brice-74/entx/search/common"
 )
@@ -12,7 +16,7 @@ import (
 type Includes []*Include
 
 func (incs Includes) PredicateQs(ctx context.Context, node entx.Node, dialect string) ([]func(entx.Query), error) {
-	var applies = make([]func(entx.Query), 0, len(incs))
+	var applies = make([]func(entx.Query), len(incs))
 	for i, inc := range incs {
 		applicator, err := inc.PredicateQ(ctx, node, dialect)
 		if err != nil {
@@ -50,6 +54,17 @@ type Include struct {
 	Sort       Sorts      `json:"sort,omitempty"`
 	Aggregates Aggregates `json:"aggregates,omitempty"`
 	Limit
+	// Offset skips the first related entities of each parent.
+	Offset int `json:"offset,omitempty"`
+	// Recursive includes the last relation again in each related entity, up to the maximum depth,
+	// with the same options. It must relate a node to itself, the reports of an employee loading
+	// its descendants and its manager its ancestors.
+	Recursive *Recursion `json:"recursive,omitempty"`
+	// Pivot selects fields of the pivot row relating each related entity to its parent, returned
+	// in its metadata. The last relation must be an M2M relation backed by an edge schema, whose
+	// pivot row is also filtered and sorted as the "pivot" relation.
+	Pivot []string `json:"pivot,omitempty"`
+	Cursor
 	// pre-processed segments
 	relationParts []string
 	preprocessed  bool
@@ -65,20 +80,22 @@ func (inc *Include) PredicateQ(ctx context.Context, node entx.Node, dialect stri
 	}
 
 	var (
-		aggFields []string
-		preds     []func(*sql.Selector)
+		handlers []entx.EntityHandler
+		preds    []func(*sql.Selector)
 	)
 
 	policyPred, err := common.EnforcePolicy(ctx, node, common.OpLastIncludeQuery)
 	if err != nil {
 		return nil, err
 	}
-	preds = append(preds, policyPred)
+	if policyPred != nil {
+		preds = append(preds, policyPred)
+	}
 
 	current := node
 	var (
-		bridges              = make([]entx.Bridge, 0, len(inc.relationParts))
-		bridgesPoliciesPreds = make([]func(*sql.Selector), 0, len(inc.relationParts))
+		bridges              = make([]entx.Bridge, len(inc.relationParts))
+		bridgesPoliciesPreds = make([]func(*sql.Selector), len(inc.relationParts))
 	)
 	for i, rel := range inc.relationParts {
 		bridge := current.Bridge(rel)
@@ -98,20 +115,53 @@ func (inc *Include) PredicateQ(ctx context.Context, node entx.Node, dialect stri
 		bridges[i] = bridge
 	}
 
+	var (
+		lastIndex = len(bridges) - 1
+		last      = bridges[lastIndex]
+	)
+	if inc.Recursive != nil {
+		if _, err := selfReference(last, inc.relationParts[lastIndex]); err != nil {
+			return nil, err
+		}
+	}
+
 	if ps, fields, err := inc.Aggregates.Predicate(ctx, current, dialect); err != nil {
 		return nil, err
 	} else if len(ps) > 0 {
-		aggFields = fields
+		handlers = append(handlers, entx.AddAggregatesFromValuesFunc(inc.Aggregates.NormalizeValue, fields...))
 		preds = append(preds, ps...)
 	}
 
-	if ps, err := inc.Filters.Predicate(current); err != nil {
+	var (
+		pivotIncluder entx.PivotIncluder
+		pivotPred     func(*sql.Selector)
+		pivotHandler  entx.PivotHandler
+	)
+	if len(inc.Pivot) > 0 {
+		if pivotIncluder, pivotPred, pivotHandler, err = inc.pivotPredicate(last); err != nil {
+			return nil, err
+		}
+	}
+
+	// the filters and the sorts of the related rows may read their pivot row
+	scope := withPivot(current, last, true)
+
+	if ps, err := inc.Filters.Predicate(scope); err != nil {
 		return nil, err
 	} else if len(ps) > 0 {
 		preds = append(preds, ps...)
 	}
 
-	if ps, err := inc.Sort.Predicate(current); err != nil {
+	if inc.IsCursor() {
+		p, keys, err := inc.Cursor.Predicate(current, inc.Sort)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+		handlers = append(handlers, func(entities []entx.Entity) error {
+			return common.SetEntitiesCursor(entities, keys)
+		})
+	} else if ps, err := inc.Sort.Predicate(ctx, scope, dialect); err != nil {
 		return nil, err
 	} else if len(ps) > 0 {
 		preds = append(preds, ps...)
@@ -127,41 +177,126 @@ func (inc *Include) PredicateQ(ctx context.Context, node entx.Node, dialect stri
 		return nil, err
 	}
 
+	// an explain records the include queries, which are never loaded without parents
+	recorder := common.ExplainRecorderFrom(ctx)
+
+	// includeLast includes the last relation at the given path, with the pivot rows of the related rows when selected
+	includeLast := func(q entx.Query, path string, cq func(entx.Query), handlers []entx.EntityHandler) {
+		if pivotIncluder == nil {
+			last.Include(q, cq, handlers...)
+			return
+		}
+		pivotIncluder.IncludePivot(q, cq, func(qPivot entx.Query) {
+			qPivot.Predicate(pivotPred)
+			if recorder != nil {
+				recorder.AddInclude(path+"."+PivotRelation, qPivot)
+			}
+		}, pivotHandler, handlers...)
+	}
+
+	// complete applies the options on the query of the last relation, at the given depth
+	// of a recursive include, and includes the relation again until the maximum depth
+	var complete func(q entx.Query, path string, depth int)
+	complete = func(q entx.Query, path string, depth int) {
+		if len(preds) > 0 {
+			q.Predicate(preds...)
+		}
+
+		for _, apply := range incApplies {
+			apply(q)
+		}
+
+		selectApply(q)
+
+		if inc.Recursive != nil && depth < inc.Recursive.MaxDepth {
+			path += "." + inc.relationParts[lastIndex]
+			includeLast(q, path, func(qChild entx.Query) {
+				if pred := bridgesPoliciesPreds[lastIndex]; pred != nil {
+					qChild.Predicate(pred)
+				}
+				if recorder != nil {
+					recorder.AddInclude(path, qChild)
+				}
+				complete(qChild, path, depth+1)
+			}, inc.levelHandlers(handlers, depth+1))
+		}
+
+		// applied last, as it wraps the complete query
+		q.Predicate(inc.partitionLimit(last, inc.Offset))
+	}
+
+	lastHandlers := inc.levelHandlers(handlers, 1)
+
 	return func(q entx.Query) {
-		var (
-			childQ        entx.Query
-			hasAggregates = len(aggFields) > 0
-		)
+		var childQ entx.Query
 		for i, bridge := range bridges {
-			isLastIndex := len(bridges)-1 == i
+			isLastIndex := lastIndex == i
 			childQ = nil
 
-			if isLastIndex && hasAggregates {
-				bridge.Include(q, func(qChild entx.Query) {
-					childQ = qChild
-				}, entx.AddAggregatesFromValues(aggFields...))
+			path := node.Name() + "." + strings.Join(inc.relationParts[:i+1], ".")
+			cq := func(qChild entx.Query) {
+				childQ = qChild
+				if recorder != nil {
+					recorder.AddInclude(path, qChild)
+				}
+			}
+			if isLastIndex {
+				includeLast(q, path, cq, lastHandlers)
 			} else {
-				bridge.Include(q, func(qChild entx.Query) { childQ = qChild })
+				bridge.Include(q, cq)
 			}
 
 			if pred := bridgesPoliciesPreds[i]; pred != nil {
 				childQ.Predicate(pred)
 			}
 
-			childQ.Predicate(inc.Limit.Predicate())
+			if !isLastIndex {
+				childQ.Predicate(inc.partitionLimit(bridge, 0))
+			}
 			q = childQ
 		}
 
-		if len(preds) > 0 {
-			q.Predicate(preds...)
+		complete(q, node.Name()+"."+strings.Join(inc.relationParts, "."), 1)
+	}, nil
+}
+
+// levelHandlers returns the handlers of the entities of the last relation, those of a recursive
+// include also setting their depth.
+func (inc *Include) levelHandlers(handlers []entx.EntityHandler, depth int) []entx.EntityHandler {
+	if inc.Recursive == nil {
+		return handlers
+	}
+	return append(slices.Clip(handlers), func(entities []entx.Entity) error {
+		for _, e := range entities {
+			e.Metadatas().Depth = depth
 		}
+		return nil
+	})
+}
 
-		for _, apply := range incApplies {
-			apply(q)
+// partitionLimit limits the children of each parent, the include query loading those of all parents.
+// The children are partitioned by the column holding the key of their parent, the pivot one for M2M,
+// and ordered by their primary key when the include has no sort.
+func (inc *Include) partitionLimit(bridge entx.Bridge, offset int) func(*sql.Selector) {
+	rel := bridge.RelInfos()
+	partition := func(s *sql.Selector) string {
+		if rel.RelType == sqlgraph.M2M {
+			// the pivot table is joined by ent to load the children,
+			// it is missing when the query is rendered without parents
+			if pivot, ok := s.JoinedTable(rel.PivotTable); ok {
+				return pivot.C(rel.PivotLeftField)
+			}
+			return ""
 		}
+		return s.C(rel.FinalRightField)
+	}
 
-		selectApply(q)
-	}, nil
+	pks := bridge.Child().PKs()
+	defaults := make([]string, len(pks))
+	for i, pk := range pks {
+		defaults[i] = pk.StorageName
+	}
+	return inc.Limit.PartitionPredicate(partition, offset, defaults...)
 }
 
 func (inc *Include) ValidateAndPreprocess(cfg *common.IncludeConfig) error {
@@ -205,7 +340,40 @@ func (inc *Include) walkValidate(cfg *common.IncludeConfig, depth int, total *in
 		}
 	}
 
+	sortCfg := cfg.SortConfig
+	if sortCfg == nil {
+		sortCfg = &common.SortConfig{}
+	}
+	if err := inc.Sort.ValidateAndPreprocess(sortCfg); err != nil {
+		return err
+	}
+
+	// children of every parent are loaded by a single query,
+	// so a backward position cannot be restored per parent
+	if err := inc.Cursor.ValidateAndPreprocess(inc.Sort, false); err != nil {
+		return err
+	}
+
+	if inc.Recursive != nil {
+		// the positions of the nested levels could not be returned
+		if inc.IsCursor() {
+			return &common.ValidationError{
+				Rule: "RecursiveIncludeCursor",
+				Err:  errors.New("recursive include does not support cursor pagination"),
+			}
+		}
+		if err := inc.Recursive.validate(cfg.FilterConfig); err != nil {
+			return err
+		}
+	}
+
 	inc.Limit.Sanitize(cfg.PageableConfig)
+	if inc.Offset < 0 {
+		return &common.ValidationError{
+			Rule: "IncludeOffset",
+			Err:  fmt.Errorf("include offset must be positive, got %d", inc.Offset),
+		}
+	}
 
 	inc.preprocessed = true
 	return nil
@@ -15,6 +15,40 @@ func (l *Limit) Predicate() func(s *sql.Selector) {
 	}
 }
 
+// rowNumberAlias is the column numbering the rows of each partition of a limited query.
+const rowNumberAlias = "__entx_row"
+
+// PartitionPredicate limits the rows of each partition of the query to the limit, after skipping
+// the offset first ones. The rows are numbered with ROW_NUMBER() in the order of the query, or of
+// the defaults when it has none, and the query is wrapped to keep the selected numbers.
+// An empty partition column numbers the rows of the whole query.
+// It must be applied last, once the query is complete.
+func (l *Limit) PartitionPredicate(partition func(*sql.Selector) string, offset int, defaults ...string) func(s *sql.Selector) {
+	return func(s *sql.Selector) {
+		inner := s.Clone()
+		orders := inner.OrderColumns()
+		if len(orders) == 0 {
+			for _, col := range defaults {
+				orders = append(orders, inner.C(col))
+			}
+		}
+		window := sql.RowNumber().OrderBy(orders...)
+		if col := partition(s); col != "" {
+			window.PartitionBy(col)
+		}
+		inner.ClearOrder().AppendSelectExprAs(window, rowNumberAlias)
+
+		t := inner.As(s.TableName())
+		row := t.C(rowNumberAlias)
+		*s = *sql.Dialect(s.Dialect()).
+			Select().
+			From(t).
+			Where(sql.And(sql.GT(row, offset), sql.LTE(row, offset+l.Limit))).
+			OrderBy(row).
+			WithContext(s.Context())
+	}
+}
+
 func (l *Limit) Sanitize(c *common.PageableConfig) {
 	if l.Limit <= 0 {
 		l.Limit = c.DefaultLimit
@@ -8,6 +8,7 @@ import (
 type Pageable struct {
 	Page int `json:"page,omitempty"`
 	Limit
+	Cursor
 }
 
 func (p *Pageable) Predicate(useOffset bool) func(s *sql.Selector) {
@@ -0,0 +1,798 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brice-74/entx/search/common"
+)
+
+// The compact syntax describes filters, sorts, includes and selects in a single line,
+// e.g. for the parameters of a GET request:
+//
+//	filter:  age>18 and (articles.title like "go" or not is_active=false)
+//	sort:    -created_at,name,-count(articles)
+//	include: articles(limit:5,sort:-id,filter:published=true).tags,comments
+//	fields:  name,email
+//
+// Conditions compare a field, or a chain of relations ending with a field, to a value:
+// =, !=, >, >=, <, <=, like, not like, ilike, starts_with, ends_with, equal_fold, regex,
+// in, not in (with a list [a, b]), between, not between ([a, b] or a and b),
+// is null and is not null. Strings are quoted with " or ', bare words are strings
+// and numbers, true and false are typed.
+// Relation quantifiers are written any(rel: cond), all(rel: cond), none(rel: cond),
+// exists(rel) and count(rel[: cond]) op n.
+
+var (
+	ErrSyntaxUnexpected = "unexpected %s at character %d: %s"
+	ErrSyntaxExpected   = "expected %s at character %d: %s"
+	ErrSyntaxChain      = "invalid empty segment at character %d: %s"
+	ErrSyntaxString     = "unterminated string at character %d: %s"
+	ErrSyntaxIncluded   = "relation %q is included twice with options at character %d: %s"
+)
+
+// ParseFilters parses a filter expression, the top-level conditions joined with "and"
+// being returned as separate filters.
+func ParseFilters(s string) (Filters, error) {
+	p, err := newParser(s, "FilterSyntax")
+	if err != nil || p.peek().kind == tokEOF {
+		return nil, err
+	}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+	if f.isPlainAnd() {
+		return f.And, nil
+	}
+	return Filters{f}, nil
+}
+
+// ParseSorts parses a comma separated list of sorts, a field being prefixed with "-"
+// to sort in descending order. An aggregate of related rows is written type(chain).
+func ParseSorts(s string) (Sorts, error) {
+	p, err := newParser(s, "SortSyntax")
+	if err != nil || p.peek().kind == tokEOF {
+		return nil, err
+	}
+	sorts, err := p.parseSortList()
+	if err != nil {
+		return nil, err
+	}
+	return sorts, p.expectEOF()
+}
+
+// ParseIncludes parses a comma separated list of includes. Each relation of a chain
+// is included in the previous one and may set options between parentheses:
+// limit, sort, fields and filter.
+func ParseIncludes(s string) (Includes, error) {
+	p, err := newParser(s, "IncludeSyntax")
+	if err != nil || p.peek().kind == tokEOF {
+		return nil, err
+	}
+	var incs Includes
+	for {
+		if incs, err = p.parseInclude(incs); err != nil {
+			return nil, err
+		}
+		if !p.accept(tokComma) {
+			break
+		}
+	}
+	return incs, p.expectEOF()
+}
+
+// ParseSelect parses a comma separated list of fields.
+func ParseSelect(s string) (Select, error) {
+	p, err := newParser(s, "SelectSyntax")
+	if err != nil || p.peek().kind == tokEOF {
+		return nil, err
+	}
+	var sel Select
+	for {
+		t, err := p.expect(tokWord, "field")
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, t.text)
+		if !p.accept(tokComma) {
+			break
+		}
+	}
+	return sel, p.expectEOF()
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokColon
+	tokDot
+	tokMinus
+	tokPlus
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// is reports whether the token is the keyword kw, case insensitively.
+func (t token) is(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+type parser struct {
+	input  string
+	rule   string
+	tokens []token
+	at     int
+}
+
+func newParser(input, rule string) (*parser, error) {
+	p := &parser{input: input, rule: rule}
+	return p, p.lex()
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &common.ValidationError{
+		Rule: p.rule,
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+func (p *parser) unexpected(t token) error {
+	return p.errorf(ErrSyntaxUnexpected, t, t.pos, p.input)
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '.'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func (p *parser) lex() error {
+	s := p.input
+	for i := 0; i < len(s); {
+		c := s[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case isWordStart(c):
+			for i < len(s) && isWordPart(s[i]) {
+				i++
+			}
+			word := s[start:i]
+			if _, pos, ok := splitChain(word); !ok {
+				return p.errorf(ErrSyntaxChain, start+pos, p.input)
+			}
+			p.tokens = append(p.tokens, token{tokWord, word, start})
+			continue
+		case isDigit(c):
+			for i < len(s) && (isDigit(s[i]) || s[i] == '.' || s[i] == 'e' || s[i] == 'E' ||
+				((s[i] == '-' || s[i] == '+') && (s[i-1] == 'e' || s[i-1] == 'E'))) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{tokNumber, s[start:i], start})
+			continue
+		case c == '"' || c == '\'':
+			text, end, ok := unquote(s, i)
+			if !ok {
+				return p.errorf(ErrSyntaxString, start, p.input)
+			}
+			p.tokens = append(p.tokens, token{tokString, text, start})
+			i = end
+			continue
+		}
+
+		kind := tokOp
+		switch c {
+		case '(':
+			kind = tokLParen
+		case ')':
+			kind = tokRParen
+		case '[':
+			kind = tokLBracket
+		case ']':
+			kind = tokRBracket
+		case ',':
+			kind = tokComma
+		case ':':
+			kind = tokColon
+		case '.':
+			kind = tokDot
+		case '-':
+			kind = tokMinus
+		case '+':
+			kind = tokPlus
+		case '=':
+		case '!', '>', '<':
+			if i+1 < len(s) && s[i+1] == '=' {
+				i++
+			} else if c == '!' {
+				return p.errorf(ErrSyntaxUnexpected, strconv.Quote("!"), start, p.input)
+			}
+		default:
+			return p.errorf(ErrSyntaxUnexpected, strconv.QuoteRune(rune(c)), start, p.input)
+		}
+		i++
+		p.tokens = append(p.tokens, token{kind, s[start:i], start})
+	}
+	p.tokens = append(p.tokens, token{tokEOF, "", len(s)})
+	return nil
+}
+
+// unquote reads the string quoted at s[start], backslashes escaping the next character.
+func unquote(s string, start int) (string, int, bool) {
+	var (
+		b     strings.Builder
+		quote = s[start]
+	)
+	for i := start + 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return b.String(), i + 1, true
+		case '\\':
+			if i+1 == len(s) {
+				return "", 0, false
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+func (p *parser) peek() token { return p.tokens[p.at] }
+
+func (p *parser) peekAt(n int) token {
+	if p.at+n < len(p.tokens) {
+		return p.tokens[p.at+n]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.at]
+	if t.kind != tokEOF {
+		p.at++
+	}
+	return t
+}
+
+func (p *parser) accept(kind tokenKind) bool {
+	if p.peek().kind == kind {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptWord(kw string) bool {
+	if p.peek().is(kw) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.peek()
+	if t.kind != kind {
+		return t, p.errorf(ErrSyntaxExpected, what, t.pos, p.input)
+	}
+	return p.next(), nil
+}
+
+func (p *parser) expectEOF() error {
+	if t := p.peek(); t.kind != tokEOF {
+		return p.unexpected(t)
+	}
+	return nil
+}
+
+// isPlainAnd reports whether the filter only joins conditions with AND.
+func (f *Filter) isPlainAnd() bool {
+	return len(f.And) > 0 && f.Not == nil && len(f.Or) == 0 &&
+		f.Field == "" && f.Relation == "" && f.Operator == OpEmpty
+}
+
+func (p *parser) parseOr() (*Filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peek().is("or") {
+		return f, nil
+	}
+	or := Filters{f}
+	for p.acceptWord("or") {
+		if f, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		or = append(or, f)
+	}
+	return &Filter{Or: or}, nil
+}
+
+func (p *parser) parseAnd() (*Filter, error) {
+	f, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peek().is("and") {
+		return f, nil
+	}
+	and := Filters{f}
+	for p.acceptWord("and") {
+		if f, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		and = append(and, f)
+	}
+	return &Filter{And: and}, nil
+}
+
+func (p *parser) parseUnary() (*Filter, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokLParen:
+		p.next()
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case t.is("not") && p.peekAt(1).kind != tokOp && !p.peekAt(1).is("like") &&
+		!p.peekAt(1).is("in") && !p.peekAt(1).is("between"):
+		p.next()
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Not: f}, nil
+	case t.kind == tokWord && p.peekAt(1).kind == tokLParen:
+		return p.parseQuantified()
+	}
+	return p.parseCondition()
+}
+
+// parseQuantified parses any, all, none, exists and count conditions on a relation.
+func (p *parser) parseQuantified() (*Filter, error) {
+	name := p.next()
+	p.next() // (
+	rel, err := p.expect(tokWord, "relation")
+	if err != nil {
+		return nil, err
+	}
+
+	var f *Filter
+	switch q := Quantifier(strings.ToLower(name.text)); q {
+	case QuantAny, QuantAll, QuantNone, "count":
+		f = &Filter{}
+		if p.accept(tokColon) {
+			if f, err = p.parseOr(); err != nil {
+				return nil, err
+			}
+			if f.Relation != "" {
+				// a relation condition is nested to keep its own relation
+				f = &Filter{And: Filters{f}}
+			}
+		}
+		if q != "count" {
+			f.Quantifier = q
+		}
+	case QuantExists:
+		f = &Filter{Quantifier: QuantExists}
+	default:
+		return nil, p.unexpected(name)
+	}
+	f.Relation = rel.text
+	if _, err := p.expect(tokRParen, `")"`); err != nil {
+		return nil, err
+	}
+
+	if name.is("count") {
+		t := p.peek()
+		if t.kind != tokOp {
+			return nil, p.errorf(ErrSyntaxExpected, "comparison operator", t.pos, p.input)
+		}
+		p.next()
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		f.Count = &RelationCount{Operator: Operator(t.text), Value: v}
+	}
+	return f, nil
+}
+
+// wordOps are the operators written as a single word.
+var wordOps = map[string]Operator{
+	"like":        OpLike,
+	"ilike":       OpILike,
+	"in":          OpIn,
+	"between":     OpBetween,
+	"starts_with": OpStartsWith,
+	"ends_with":   OpEndsWith,
+	"equal_fold":  OpEqualFold,
+	"regex":       OpRegex,
+}
+
+func (p *parser) parseCondition() (*Filter, error) {
+	field, err := p.expect(tokWord, "field")
+	if err != nil {
+		return nil, err
+	}
+	f := &Filter{Field: field.text}
+
+	t := p.next()
+	switch {
+	case t.kind == tokOp:
+		f.Operator = Operator(t.text)
+	case t.is("is"):
+		f.Operator = OpIsNull
+		if p.acceptWord("not") {
+			f.Operator = OpIsNotNull
+		}
+		if n := p.next(); !n.is("null") {
+			return nil, p.errorf(ErrSyntaxExpected, `"null"`, n.pos, p.input)
+		}
+		return f, nil
+	case t.is("not"):
+		n := p.next()
+		switch {
+		case n.is("like"):
+			f.Operator = OpNotLike
+		case n.is("in"):
+			f.Operator = OpNotIn
+		case n.is("between"):
+			f.Operator = OpNotBetween
+		default:
+			return nil, p.errorf(ErrSyntaxExpected, `"like", "in" or "between"`, n.pos, p.input)
+		}
+	case t.kind == tokWord && wordOps[strings.ToLower(t.text)] != "":
+		f.Operator = wordOps[strings.ToLower(t.text)]
+	default:
+		return nil, p.errorf(ErrSyntaxExpected, "operator", t.pos, p.input)
+	}
+
+	switch f.Operator {
+	case OpIn, OpNotIn:
+		f.Value, err = p.parseList()
+	case OpBetween, OpNotBetween:
+		if k := p.peek().kind; k == tokLBracket || k == tokLParen {
+			f.Value, err = p.parseList()
+			break
+		}
+		var low, high any
+		if low, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		if n := p.next(); !n.is("and") {
+			return nil, p.errorf(ErrSyntaxExpected, `"and"`, n.pos, p.input)
+		}
+		if high, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		f.Value = []any{low, high}
+	default:
+		f.Value, err = p.parseValue()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseList parses values between brackets or parentheses.
+func (p *parser) parseList() ([]any, error) {
+	open := p.next()
+	closing := tokRBracket
+	switch open.kind {
+	case tokLBracket:
+	case tokLParen:
+		closing = tokRParen
+	default:
+		return nil, p.errorf(ErrSyntaxExpected, "list", open.pos, p.input)
+	}
+	values := []any{}
+	if p.accept(closing) {
+		return values, nil
+	}
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if p.accept(closing) {
+			return values, nil
+		}
+		if _, err := p.expect(tokComma, `","`); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseValue() (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return t.text, nil
+	case tokMinus, tokPlus:
+		n := p.next()
+		if n.kind != tokNumber {
+			return nil, p.errorf(ErrSyntaxExpected, "number", n.pos, p.input)
+		}
+		v, err := p.number(n)
+		if err != nil || t.kind == tokPlus {
+			return v, err
+		}
+		switch v := v.(type) {
+		case int64:
+			return -v, nil
+		default:
+			return -v.(float64), nil
+		}
+	case tokNumber:
+		if text, ok := p.bareWord(t); ok {
+			return text, nil
+		}
+		return p.number(t)
+	case tokWord:
+		if text, ok := p.bareWord(t); ok {
+			return text, nil
+		}
+		switch {
+		case t.is("true"):
+			return true, nil
+		case t.is("false"):
+			return false, nil
+		}
+		return t.text, nil
+	}
+	return nil, p.errorf(ErrSyntaxExpected, "value", t.pos, p.input)
+}
+
+// bareWord joins a word or a number value with the tokens written right after it
+// through "-", "+" and ":", so that times such as 2025-01-01 or now-7d need no quotes.
+// It reports false when no token is joined.
+func (p *parser) bareWord(t token) (string, bool) {
+	text, end := t.text, t.pos+len(t.text)
+	for n := p.peek(); n.pos == end; n = p.peek() {
+		switch n.kind {
+		case tokMinus, tokPlus, tokColon, tokWord, tokNumber:
+		default:
+			return text, text != t.text
+		}
+		text += n.text
+		end += len(n.text)
+		p.next()
+	}
+	return text, text != t.text
+}
+
+func (p *parser) number(t token) (any, error) {
+	if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, p.errorf(ErrSyntaxUnexpected, t, t.pos, p.input)
+	}
+	return f, nil
+}
+
+func (p *parser) parseSortList() (Sorts, error) {
+	var sorts Sorts
+	for {
+		s, err := p.parseSort()
+		if err != nil {
+			return nil, err
+		}
+		sorts = append(sorts, s)
+		if p.peek().kind != tokComma || p.peekAt(1).kind == tokWord && p.peekAt(2).kind == tokColon {
+			// a comma followed by an include option ends the list
+			return sorts, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseSort() (*Sort, error) {
+	s := &Sort{}
+	if p.accept(tokMinus) {
+		s.Direction = DirDESC
+	} else {
+		p.accept(tokPlus)
+	}
+	t, err := p.expect(tokWord, "field")
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(tokLParen) {
+		s.Field = t.text
+		return s, nil
+	}
+	s.Aggregate = Agg(strings.ToLower(t.text))
+	if p.peek().kind != tokRParen {
+		field, err := p.expect(tokWord, "field")
+		if err != nil {
+			return nil, err
+		}
+		s.Field = field.text
+	}
+	if _, err := p.expect(tokRParen, `")"`); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseInclude parses a chain of includes and merges it into incs.
+func (p *parser) parseInclude(incs Includes) (Includes, error) {
+	t, err := p.expect(tokWord, "relation")
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(t.text, ".")
+	inc := &Include{Relation: parts[len(parts)-1]}
+	if p.accept(tokLParen) {
+		if err := p.parseIncludeOptions(inc); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(tokDot) {
+		if inc.Includes, err = p.parseInclude(nil); err != nil {
+			return nil, err
+		}
+	}
+	for i := len(parts) - 2; i >= 0; i-- {
+		inc = &Include{Relation: parts[i], Includes: Includes{inc}}
+	}
+	return p.mergeInclude(incs, inc, t.pos)
+}
+
+// mergeInclude adds inc to incs, merging the includes of a relation listed several times.
+func (p *parser) mergeInclude(incs Includes, inc *Include, pos int) (Includes, error) {
+	for _, prev := range incs {
+		if prev.Relation != inc.Relation {
+			continue
+		}
+		children := inc.Includes
+		if inc.hasOptions() {
+			if prev.hasOptions() {
+				return nil, p.errorf(ErrSyntaxIncluded, inc.Relation, pos, p.input)
+			}
+			kept := prev.Includes
+			*prev = *inc
+			prev.Includes = kept
+		}
+		var err error
+		for _, child := range children {
+			if prev.Includes, err = p.mergeInclude(prev.Includes, child, pos); err != nil {
+				return nil, err
+			}
+		}
+		return incs, nil
+	}
+	return append(incs, inc), nil
+}
+
+func (inc *Include) hasOptions() bool {
+	return inc.Limit.Limit != 0 || inc.Offset != 0 || len(inc.Sort) > 0 || len(inc.Select) > 0 || len(inc.Filters) > 0
+}
+
+func (p *parser) parseIncludeOptions(inc *Include) error {
+	for {
+		key, err := p.expect(tokWord, "include option")
+		if err != nil {
+			return err
+		}
+		if _, err := p.expect(tokColon, `":"`); err != nil {
+			return err
+		}
+		switch strings.ToLower(key.text) {
+		case "limit":
+			n, err := p.expect(tokNumber, "limit")
+			if err != nil {
+				return err
+			}
+			if inc.Limit.Limit, err = strconv.Atoi(n.text); err != nil {
+				return p.unexpected(n)
+			}
+		case "offset":
+			n, err := p.expect(tokNumber, "offset")
+			if err != nil {
+				return err
+			}
+			if inc.Offset, err = strconv.Atoi(n.text); err != nil {
+				return p.unexpected(n)
+			}
+		case "sort":
+			if inc.Sort, err = p.parseSortList(); err != nil {
+				return err
+			}
+		case "fields":
+			grouped := p.accept(tokLParen)
+			for {
+				f, err := p.expect(tokWord, "field")
+				if err != nil {
+					return err
+				}
+				inc.Select = append(inc.Select, f.text)
+				if !grouped || !p.accept(tokComma) {
+					break
+				}
+			}
+			if grouped {
+				if _, err := p.expect(tokRParen, `")"`); err != nil {
+					return err
+				}
+			}
+		case "filter":
+			f, err := p.parseOr()
+			if err != nil {
+				return err
+			}
+			if f.isPlainAnd() {
+				inc.Filters = append(inc.Filters, f.And...)
+			} else {
+				inc.Filters = append(inc.Filters, f)
+			}
+		default:
+			return p.unexpected(key)
+		}
+		if p.accept(tokRParen) {
+			return nil
+		}
+		if _, err := p.expect(tokComma, `"," or ")"`); err != nil {
+			return err
+		}
+	}
+}
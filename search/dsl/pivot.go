@@ -0,0 +1,186 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// PivotRelation names the pivot row of the rows reached through an M2M relation backed by an
+// edge schema, such as the article_tags row of each tag of an article. Its fields are filtered
+// as those of a relation, e.g. "pivot.created_at", and sort or are returned by an include.
+const PivotRelation = "pivot"
+
+var (
+	ErrNoPivot       = "relation %q of node %q has no edge schema to read pivot fields from"
+	ErrPivotRelation = "relation %q of node %q reads the pivot row of each related row, it takes no count and no recursion"
+	ErrPivotSort     = "sort on the pivot row only supports a field of node %q without aggregate, got %q"
+)
+
+const pivotKeyPrefix = "__pivot_"
+
+// pivotScope is the node of the rows reached through an M2M bridge backed by an edge schema,
+// the pivot row joining each one to its parent being exposed as the "pivot" relation.
+type pivotScope struct {
+	entx.Node
+	pivot *pivotBridge
+}
+
+func (s *pivotScope) Bridge(name string) entx.Bridge {
+	if name == PivotRelation {
+		return s.pivot
+	}
+	return s.Node.Bridge(name)
+}
+
+// withPivot returns the node reached through the bridge, scoped with its pivot row if it has one.
+// The scope of an include query joins the pivot table itself when ent did not, the query
+// being rendered without parents.
+func withPivot(node entx.Node, b entx.Bridge, include bool) entx.Node {
+	if b == nil || b.Pivot() == nil {
+		return node
+	}
+	return &pivotScope{
+		Node:  node,
+		pivot: &pivotBridge{Bridge: b, parent: node, include: include},
+	}
+}
+
+// pivotBridge relates each row reached through an M2M bridge to its pivot row. The pivot table
+// is already joined by the query of the relation, so its predicates apply to the same rows.
+// Its other methods are those of the M2M bridge, it is only resolved by filters and include sorts.
+type pivotBridge struct {
+	entx.Bridge
+	parent  entx.Node
+	include bool
+}
+
+func (b *pivotBridge) Child() entx.Node {
+	return b.Bridge.Pivot()
+}
+
+func (b *pivotBridge) Parent() entx.Node {
+	return b.parent
+}
+
+func (b *pivotBridge) Pivot() entx.Node {
+	return nil
+}
+
+func (b *pivotBridge) Inverse() entx.Bridge {
+	return nil
+}
+
+// table returns the pivot table of the query of the relation: the one ent joins to load
+// the children of an include, or the one of the sub-select of a relation filter.
+func (b *pivotBridge) table(s *sql.Selector) *sql.SelectTable {
+	rel := b.RelInfos()
+	if t, ok := s.JoinedTable(rel.PivotTable); ok {
+		return t
+	}
+	t := sql.Dialect(s.Dialect()).Table(rel.PivotTable)
+	if b.include {
+		s.Join(t).On(t.C(rel.PivotRightField), s.C(rel.FinalRightField))
+	}
+	return t
+}
+
+// Filter always matches, each related row having its pivot row.
+func (b *pivotBridge) Filter() func(*sql.Selector) {
+	return func(*sql.Selector) {}
+}
+
+func (b *pivotBridge) FilterWith(predicates ...func(*sql.Selector)) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		scoped := sql.Dialect(s.Dialect()).Select().From(b.table(s))
+		entx.CombinePredicates(predicates...)(scoped)
+		if p := scoped.P(); p != nil {
+			s.Where(p)
+		}
+	}
+}
+
+// orderBy orders the related rows by a field of their pivot row.
+func (b *pivotBridge) orderBy(column string, terms func(dialect, col string) []string) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		s.OrderBy(terms(s.Dialect(), b.table(s).C(column))...)
+	}
+}
+
+// pivotPredicate returns the bridge loading the pivot rows of the related rows of an include,
+// the predicate selecting their pivot fields and the handler setting them in the metadata of each entity.
+// A related row shared by several parents is copied for each of them, with its own pivot row.
+func (inc *Include) pivotPredicate(last entx.Bridge) (entx.PivotIncluder, func(*sql.Selector), entx.PivotHandler, error) {
+	includer, ok := last.(entx.PivotIncluder)
+	if !ok || last.Pivot() == nil {
+		return nil, nil, nil, &common.QueryBuildError{
+			Op:  "Include.pivotPredicate",
+			Err: fmt.Errorf(ErrNoPivot, inc.relationParts[len(inc.relationParts)-1], last.Parent().Name()),
+		}
+	}
+	node := last.Pivot()
+	fields := make([]*entx.Field, len(inc.Pivot))
+	for i, name := range inc.Pivot {
+		if fields[i] = node.FieldByName(name); fields[i] == nil {
+			return nil, nil, nil, &common.QueryBuildError{
+				Op:  "Include.pivotPredicate",
+				Err: fmt.Errorf(ErrNodeNotHaveField, node.Name(), name),
+			}
+		}
+	}
+
+	pred := func(s *sql.Selector) {
+		for _, f := range fields {
+			s.AppendSelectAs(s.C(f.StorageName), pivotKeyPrefix+f.Name)
+		}
+	}
+	handler := func(child, pivot entx.Entity) error {
+		values := make(map[string]any, len(fields))
+		for _, f := range fields {
+			v, err := pivot.Value(pivotKeyPrefix + f.Name)
+			if err != nil {
+				return &common.ExecError{
+					Op:  "Include.pivotPredicate",
+					Err: err,
+				}
+			}
+			values[f.Name] = normalizePivotValue(f, v)
+		}
+		child.Metadatas().Pivot = values
+		return nil
+	}
+	return includer, pred, handler, nil
+}
+
+// normalizePivotValue converts a raw pivot value, whose type depends on the driver
+// and the dialect, to the type of its field.
+func normalizePivotValue(f *entx.Field, v any) any {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	switch f.Type {
+	case entx.TypeInt:
+		if s, ok := v.(string); ok {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i
+			}
+		}
+	case entx.TypeBool:
+		switch b := v.(type) {
+		case int64:
+			return b != 0
+		case string:
+			if parsed, err := strconv.ParseBool(b); err == nil {
+				return parsed
+			}
+		}
+	case entx.TypeString, entx.TypeEnum, entx.TypeUUID:
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return entx.NormalizeAggregateValue(v)
+}
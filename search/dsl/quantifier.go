@@ -0,0 +1,214 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// Quantifier tells how the related rows of a relation filter must match its conditions.
+type Quantifier string
+
+const (
+	// QuantAny matches when at least one related row satisfies the conditions (default).
+	QuantAny Quantifier = "any"
+	// QuantNone matches when no related row satisfies the conditions.
+	QuantNone Quantifier = "none"
+	// QuantAll matches when every related row satisfies the conditions,
+	// including when there is no related row at all.
+	QuantAll Quantifier = "all"
+	// QuantExists matches when at least one related row exists.
+	QuantExists Quantifier = "exists"
+	// QuantNotExists matches when no related row exists.
+	QuantNotExists Quantifier = "not exists"
+)
+
+// RelationCount compares the number of related rows, restricted by the filter conditions if any.
+type RelationCount struct {
+	Operator Operator `json:"operator"`
+	Value    any      `json:"value"`
+}
+
+var countOps = map[Operator]sql.Op{
+	OpEqual:        sql.OpEQ,
+	OpNotEqual:     sql.OpNEQ,
+	OpGreaterThan:  sql.OpGT,
+	OpGreaterEqual: sql.OpGTE,
+	OpLessThan:     sql.OpLT,
+	OpLessEqual:    sql.OpLTE,
+}
+
+// hasCondition reports whether the filter holds conditions to apply on the related rows.
+func (f *Filter) hasCondition() bool {
+	return f.Field != "" || f.Not != nil || len(f.And) > 0 || len(f.Or) > 0
+}
+
+// relationPredicate applies the quantifier or the count on the last bridge of the relation chain,
+// previous bridges being traversed with the default "any" semantic.
+func (f *Filter) relationPredicate(final entx.Node, bridges []entx.Bridge) (func(*sql.Selector), error) {
+	var local func(*sql.Selector)
+	if f.hasCondition() {
+		var err error
+		if local, err = f.localPredicate(final); err != nil {
+			return nil, err
+		}
+	}
+
+	last := bridges[len(bridges)-1]
+	compose := composeBridges(bridges[:len(bridges)-1])
+
+	if _, ok := last.(*pivotBridge); ok && (f.Count != nil || f.Recursive != nil) {
+		return nil, &common.QueryBuildError{
+			Op:  "Filter.relationPredicate",
+			Err: fmt.Errorf(ErrPivotRelation, PivotRelation, last.Parent().Name()),
+		}
+	}
+
+	if f.Recursive != nil {
+		pred, err := f.recursivePredicate(last, local)
+		if err != nil {
+			return nil, err
+		}
+		return compose(pred), nil
+	}
+
+	if f.Count != nil {
+		return compose(f.Count.predicate(last, local)), nil
+	}
+
+	var pred func(*sql.Selector)
+	switch f.Quantifier {
+	case QuantExists:
+		pred = last.Filter()
+	case QuantNotExists:
+		pred = sql.NotPredicates(last.Filter())
+	case QuantNone:
+		if local == nil {
+			pred = sql.NotPredicates(last.Filter())
+		} else {
+			pred = sql.NotPredicates(last.FilterWith(local))
+		}
+	case QuantAll:
+		pred = sql.NotPredicates(last.FilterWith(sql.NotPredicates(local)))
+	default:
+		if local == nil {
+			pred = last.Filter()
+		} else {
+			pred = last.FilterWith(local)
+		}
+	}
+	return compose(pred), nil
+}
+
+// predicate compares a correlated COUNT(*) of the rows related through b.
+func (c *RelationCount) predicate(b entx.Bridge, local func(*sql.Selector)) func(*sql.Selector) {
+	rel := b.RelInfos()
+	return func(s *sql.Selector) {
+		builder := sql.Dialect(s.Dialect())
+		t := builder.Table(b.Child().Table()).As("count_" + b.Child().Table())
+		sub := builder.Select(sql.Count("*")).From(t)
+		if rel.RelType == sqlgraph.M2M {
+			pivot := builder.Table(rel.PivotTable)
+			sub.Join(pivot).On(pivot.C(rel.PivotRightField), t.C(rel.FinalRightField))
+			sub.Where(sql.ColumnsEQ(pivot.C(rel.PivotLeftField), s.C(rel.FinalLeftField)))
+		} else {
+			sub.Where(sql.ColumnsEQ(t.C(rel.FinalRightField), s.C(rel.FinalLeftField)))
+		}
+		if local != nil {
+			local(sub)
+		}
+
+		p := sql.P(func(b *sql.Builder) {
+			b.Wrap(func(b *sql.Builder) { b.Join(sub) })
+			switch c.Operator {
+			case OpBetween, OpNotBetween:
+				bounds := c.Value.([]any)
+				b.WriteString(" BETWEEN ").Arg(bounds[0]).WriteString(" AND ").Arg(bounds[1])
+			default:
+				b.WriteOp(countOps[c.Operator]).Arg(c.Value)
+			}
+		})
+		if c.Operator == OpNotBetween {
+			p = sql.Not(p)
+		}
+		s.Where(p)
+	}
+}
+
+func (c *RelationCount) validate() error {
+	switch c.Operator {
+	case OpBetween, OpNotBetween:
+		bounds, ok := c.Value.([]any)
+		if !ok || len(bounds) != 2 || !IsInteger(bounds[0]) || !IsInteger(bounds[1]) {
+			return &common.ValidationError{
+				Rule: "RelationCountValue",
+				Err:  fmt.Errorf("'%s' count operator need a slice of two integers, got %v", c.Operator, c.Value),
+			}
+		}
+	default:
+		if _, ok := countOps[c.Operator]; !ok {
+			return &common.ValidationError{
+				Rule: "RelationCountOperator",
+				Err:  fmt.Errorf("unsupported count operator %q", c.Operator),
+			}
+		}
+		if !IsInteger(c.Value) {
+			return &common.ValidationError{
+				Rule: "RelationCountValue",
+				Err:  fmt.Errorf("'%s' count operator need an integer value, got %T", c.Operator, c.Value),
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Filter) validateQuantifier() error {
+	if f.Quantifier == "" && f.Count == nil {
+		return nil
+	}
+
+	if f.Relation == "" {
+		return &common.ValidationError{
+			Rule: "QuantifierWithoutRelation",
+			Err:  errors.New("quantifier and count require a relation"),
+		}
+	}
+
+	if f.Count != nil {
+		if f.Quantifier != "" {
+			return &common.ValidationError{
+				Rule: "QuantifierCountConflict",
+				Err:  errors.New("quantifier and count cannot be used together"),
+			}
+		}
+		return f.Count.validate()
+	}
+
+	switch f.Quantifier {
+	case QuantAny, QuantNone:
+	case QuantAll:
+		if !f.hasCondition() {
+			return &common.ValidationError{
+				Rule: "QuantifierConditionRequired",
+				Err:  fmt.Errorf("quantifier %q requires conditions on the related rows", f.Quantifier),
+			}
+		}
+	case QuantExists, QuantNotExists:
+		if f.hasCondition() {
+			return &common.ValidationError{
+				Rule: "QuantifierConditionNotAllowed",
+				Err:  fmt.Errorf("quantifier %q does not accept conditions, use %q or %q instead", f.Quantifier, QuantAny, QuantNone),
+			}
+		}
+	default:
+		return &common.ValidationError{
+			Rule: "InvalidQuantifier",
+			Err:  fmt.Errorf("invalid quantifier, got %s", f.Quantifier),
+		}
+	}
+	return nil
+}
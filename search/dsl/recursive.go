@@ -0,0 +1,234 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+// Recursion follows a self-referential relation transitively, such as the reports of an employee,
+// the relation being followed again from each related row up to a maximum depth.
+type Recursion struct {
+	// MaxDepth is the number of times the relation is followed, the configured maximum by default.
+	MaxDepth int `json:"max_depth,omitempty"`
+}
+
+var (
+	ErrNotSelfReferential    = "recursive relation %q must relate node %q to itself"
+	ErrRecursionCompositeKey = "recursive relation %q of node %q requires a single primary key"
+)
+
+// The transitive closure of a recursive relation, built with WITH RECURSIVE, holds a row
+// for each root and each row related to it within the depth, both identified by their primary key.
+const (
+	closureTable = "entx_closure"
+	closureRoot  = "root_id"
+	closureNode  = "node_id"
+	closureDepth = "depth"
+)
+
+func (r *Recursion) validate(cfg *common.FilterConfig) error {
+	if r == nil {
+		return nil
+	}
+	var max int
+	if cfg != nil {
+		max = cfg.MaxRecursionDepth
+	}
+	if r.MaxDepth == 0 {
+		r.MaxDepth = max
+	}
+	if r.MaxDepth <= 0 {
+		return &common.ValidationError{
+			Rule: "RecursionDepth",
+			Err:  fmt.Errorf("recursion depth must be positive, got %d", r.MaxDepth),
+		}
+	}
+	if max > 0 && r.MaxDepth > max {
+		return &common.ValidationError{
+			Rule: "MaxRecursionDepth",
+			Err:  fmt.Errorf("recursion depth of %d exceeds max %d", r.MaxDepth, max),
+		}
+	}
+	return nil
+}
+
+// selfReference checks that the bridge relates its node to itself
+// and returns the column of the primary key identifying the rows of the closure.
+func selfReference(b entx.Bridge, relation string) (string, error) {
+	node := b.Child()
+	if b.Parent().Name() != node.Name() {
+		return "", &common.QueryBuildError{
+			Op:  "selfReference",
+			Err: fmt.Errorf(ErrNotSelfReferential, relation, b.Parent().Name()),
+		}
+	}
+	pks := node.PKs()
+	if len(pks) != 1 {
+		return "", &common.QueryBuildError{
+			Op:  "selfReference",
+			Err: fmt.Errorf(ErrRecursionCompositeKey, relation, node.Name()),
+		}
+	}
+	return pks[0].StorageName, nil
+}
+
+// closure returns the WITH RECURSIVE clause of the transitive closure of the self-referential bridge.
+// Each pair of related keys is read from the pivot table of an M2M relation, or from the rows holding
+// the foreign key otherwise: the related rows for the reports of an employee, the root for its manager.
+func (r *Recursion) closure(dialect string, b entx.Bridge, pk string) *sql.WithBuilder {
+	rel := b.RelInfos()
+	table, from, to := b.Child().Table(), pk, rel.FinalLeftField
+	switch {
+	case rel.RelType == sqlgraph.M2M:
+		table, from, to = rel.PivotTable, rel.PivotLeftField, rel.PivotRightField
+	case rel.FinalLeftField == pk:
+		from, to = rel.FinalRightField, pk
+	}
+
+	builder := sql.Dialect(dialect)
+	seedT := builder.Table(table).As("e")
+	seed := builder.Select(seedT.C(from), seedT.C(to)).
+		AppendSelectExpr(sql.Expr("1")).
+		From(seedT).
+		Where(sql.And(sql.NotNull(seedT.C(from)), sql.NotNull(seedT.C(to))))
+
+	stepT, c := builder.Table(table).As("e"), builder.Table(closureTable).As("c")
+	step := builder.Select(c.C(closureRoot), stepT.C(to)).
+		AppendSelectExpr(sql.Expr(c.C(closureDepth)+" + 1")).
+		From(stepT).
+		Join(c).On(stepT.C(from), c.C(closureNode)).
+		Where(sql.And(sql.NotNull(stepT.C(to)), sql.LT(c.C(closureDepth), r.MaxDepth)))
+
+	with := sql.WithRecursive(closureTable, closureRoot, closureNode, closureDepth).As(seed.UnionAll(step))
+	with.SetDialect(dialect)
+	return with
+}
+
+// predicate matches the rows related to a row satisfying the conditions, directly or transitively.
+// For the manager relation of an employee, it matches the employees managed by a matching one
+// at any level within the depth.
+func (r *Recursion) predicate(b entx.Bridge, pk string, local func(*sql.Selector), negate bool) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		builder := sql.Dialect(s.Dialect())
+		c := builder.Table(closureTable).As("c")
+		roots := builder.Select(c.C(closureRoot)).From(c).Prefix(r.closure(s.Dialect(), b, pk))
+		if local != nil {
+			tbl := builder.Table(b.Child().Table()).As("t0")
+			matches := builder.Select(tbl.C(pk)).From(tbl)
+			local(matches)
+			roots.Where(sql.In(c.C(closureNode), matches))
+		}
+		if negate {
+			s.Where(sql.NotIn(s.C(pk), roots))
+		} else {
+			s.Where(sql.In(s.C(pk), roots))
+		}
+	}
+}
+
+// validateRecursion checks a recursive relation filter, which takes no count
+// and no "all" quantifier.
+func (f *Filter) validateRecursion(cfg *common.FilterConfig) error {
+	if f.Recursive == nil {
+		return nil
+	}
+	if f.Relation == "" {
+		return &common.ValidationError{
+			Rule: "RecursionWithoutRelation",
+			Err:  errors.New("recursive filter requires a relation"),
+		}
+	}
+	if f.Count != nil || f.Quantifier == QuantAll {
+		return &common.ValidationError{
+			Rule: "RecursiveQuantifier",
+			Err:  fmt.Errorf("recursive filter does not support count and quantifier %q", QuantAll),
+		}
+	}
+	return f.Recursive.validate(cfg)
+}
+
+// recursivePredicate applies the quantifier of the filter on the rows related through the
+// closure of the last bridge of its relation chain.
+func (f *Filter) recursivePredicate(last entx.Bridge, local func(*sql.Selector)) (func(*sql.Selector), error) {
+	pk, err := selfReference(last, f.relationParts[len(f.relationParts)-1])
+	if err != nil {
+		return nil, err
+	}
+	switch f.Quantifier {
+	case QuantExists:
+		return f.Recursive.predicate(last, pk, nil, false), nil
+	case QuantNotExists:
+		return f.Recursive.predicate(last, pk, nil, true), nil
+	case QuantNone:
+		return f.Recursive.predicate(last, pk, local, true), nil
+	default:
+		return f.Recursive.predicate(last, pk, local, false), nil
+	}
+}
+
+var ErrRecursiveAggregateField = "recursive aggregate field %q must be a relation of node %q, optionally followed by a field"
+
+// recursivePredicate selects the aggregate of the rows related to each entity through the closure
+// of its relation, such as the headcount of the whole team of a manager. A row related through
+// several paths is aggregated once.
+func (a *Aggregate) recursivePredicate(ctx context.Context, root entx.Node, dialect string) (func(*sql.Selector), string, error) {
+	node, finalField, bridges, err := resolveChain(root, a.fieldParts)
+	if err != nil {
+		return nil, "", &common.QueryBuildError{
+			Op:  "Aggregate.recursivePredicate",
+			Err: err,
+		}
+	}
+	if len(bridges) != 1 {
+		return nil, "", &common.QueryBuildError{
+			Op:  "Aggregate.recursivePredicate",
+			Err: fmt.Errorf(ErrRecursiveAggregateField, a.Field, root.Name()),
+		}
+	}
+	pk, err := selfReference(bridges[0], a.fieldParts[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	policyPred, err := common.EnforcePolicy(ctx, node, common.OpAggregate)
+	if err != nil {
+		return nil, "", err
+	}
+
+	builder := sql.Dialect(dialect)
+	tbl, c := builder.Table(node.Table()).As("t0"), builder.Table(closureTable).As("c")
+	fn, expr, alias, err := a.BaseAggregate.buildExpr(dialect, tbl, finalField)
+	if err != nil {
+		return nil, "", err
+	}
+	a.Alias = alias
+
+	filtersPreds, err := a.BaseAggregate.Filters.Predicate(node)
+	if err != nil {
+		return nil, "", err
+	}
+
+	related := builder.Select(c.C(closureNode)).From(c)
+	sub := builder.Select(fn(expr)).
+		From(tbl).
+		Where(sql.In(tbl.C(pk), related)).
+		Prefix(a.Recursive.closure(dialect, bridges[0], pk))
+	if policyPred != nil {
+		policyPred(sub)
+	}
+	for _, p := range filtersPreds {
+		p(sub)
+	}
+
+	modifier := func(s *sql.Selector) {
+		related.Where(sql.ColumnsEQ(c.C(closureRoot), s.C(pk)))
+		s.AppendSelectExprAs(sub, alias)
+	}
+	return modifier, alias, nil
+}
@@ -1,10 +1,14 @@
 package dsl
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"github.com/brice-74/entx"
@@ -18,23 +22,73 @@ const (
 	DirDESC Direction = "DESC"
 )
 
+// NullsOrder places the NULL values of a sort before or after the other ones.
+type NullsOrder string
+
+const (
+	NullsFirst NullsOrder = "first"
+	NullsLast  NullsOrder = "last"
+)
+
+// sortPositionAlias is the column holding the position of the value of each row in a value list sort.
+const sortPositionAlias = "__entx_position"
+
 type Sorts []*Sort
 
-func (ss Sorts) Predicate(node entx.Node) ([]func(*sql.Selector), error) {
+// Predicate orders the query by the sorts, followed by the primary keys of the node
+// which are not sorted already, so that rows of equal sort values keep the same order
+// from a page to another.
+func (ss Sorts) Predicate(ctx context.Context, node entx.Node, dialect string) ([]func(*sql.Selector), error) {
 	lenSorts := len(ss)
 	if lenSorts == 0 {
 		return nil, nil
 	}
 
-	preds := make([]func(*sql.Selector), 0, lenSorts)
+	preds := make([]func(*sql.Selector), 0, lenSorts+1)
 	for _, f := range ss {
-		pred, err := f.Predicate(node)
+		pred, err := f.Predicate(ctx, node, dialect)
 		if err != nil {
 			return nil, err
 		}
 		preds = append(preds, pred)
 	}
-	return preds, nil
+	return append(preds, ss.tieBreaker(node)), nil
+}
+
+// tieBreaker orders the query by the primary keys missing from the sorts.
+func (ss Sorts) tieBreaker(node entx.Node) func(*sql.Selector) {
+	var columns []string
+	for _, pk := range node.PKs() {
+		if !slices.ContainsFunc(ss, func(s *Sort) bool { return s.isField(pk.Name) }) {
+			columns = append(columns, pk.StorageName)
+		}
+	}
+	return func(s *sql.Selector) {
+		for _, col := range columns {
+			s.OrderBy(sql.Asc(s.C(col)))
+		}
+	}
+}
+
+var ErrSortAliasConflict = "sort on the aggregate alias %q takes no aggregate, no values and no filters"
+
+// BindAggregates resolves the sorts on the alias of one of the aggregates selected by the
+// same query options, which order the rows by the value of the aggregate.
+func (ss Sorts) BindAggregates(aggs Aggregates) error {
+	for _, s := range ss {
+		i := slices.IndexFunc(aggs, func(a *Aggregate) bool { return a.alias() == s.Field })
+		if i < 0 {
+			continue
+		}
+		if s.Aggregate != "" || len(s.Values) > 0 || len(s.Filters) > 0 {
+			return &common.ValidationError{
+				Rule: "SortAliasConflict",
+				Err:  fmt.Errorf(ErrSortAliasConflict, s.Field),
+			}
+		}
+		s.alias = aggs[i].alias()
+	}
+	return nil
 }
 
 func (ss Sorts) ValidateAndPreprocess(cfg *common.SortConfig) error {
@@ -47,14 +101,50 @@ func (ss Sorts) ValidateAndPreprocess(cfg *common.SortConfig) error {
 }
 
 type Sort struct {
+	// Field is a field of the node, a chain of relations ending with a field,
+	// or the alias of an aggregate of the same query options.
 	Field     string    `json:"field"`
 	Direction Direction `json:"direction,omitempty"`
 	Aggregate Agg       `json:"aggregate,omitempty"`
+	// Nulls places the NULL values first or last, instead of the default of the database.
+	Nulls NullsOrder `json:"nulls,omitempty"`
+	// Values orders the rows by the position of their value of the field in the list,
+	// the rows holding another value coming after them.
+	Values []any `json:"values,omitempty"`
+	// Filters restrict the related rows of an aggregate sort, such as the published articles
+	// of a user for a sort on the count of its articles.
+	Filters Filters `json:"filters,omitempty"`
+	AggParams
 	// pre-processed segments
-	fieldParts   []string
+	fieldParts []string
+	// alias of the aggregate the sort orders by, set by Sorts.BindAggregates
+	alias        string
 	preprocessed bool
 }
 
+// isField reports whether the sort orders by the plain value of a field of the node.
+func (s *Sort) isField(name string) bool {
+	return s.alias == "" && s.Aggregate == "" && len(s.Values) == 0 &&
+		len(s.fieldParts) == 1 && s.fieldParts[0] == name
+}
+
+// orderTerms returns the ORDER BY terms of a column, its NULL values being placed as requested.
+// MySQL has no NULLS FIRST and NULLS LAST, the NULL values are ordered by a leading IS NULL term.
+func (s *Sort) orderTerms(d string, direction func(string) string, col string) []string {
+	switch {
+	case s.Nulls == "":
+		return []string{direction(col)}
+	case d == dialect.MySQL:
+		nulls := sql.Desc
+		if s.Nulls == NullsLast {
+			nulls = sql.Asc
+		}
+		return []string{nulls(col + " IS NULL"), direction(col)}
+	default:
+		return []string{direction(col) + " NULLS " + strings.ToUpper(string(s.Nulls))}
+	}
+}
+
 func (s *Sort) dirBuilder() (func(string) string, error) {
 	switch s.Direction {
 	case DirDESC:
@@ -69,40 +159,32 @@ func (s *Sort) dirBuilder() (func(string) string, error) {
 	}
 }
 
-func (s *Sort) aggBuilder() (func(string) string, error) {
+func (s *Sort) aggBuilder(dialect string) (func(string) string, error) {
 	if s.Aggregate == "" {
 		return func(col string) string { return col }, nil
 	}
-	switch s.Aggregate {
-	case AggAvg:
-		return sql.Avg, nil
-	case AggSum:
-		return sql.Sum, nil
-	case AggMin:
-		return sql.Min, nil
-	case AggMax:
-		return sql.Max, nil
-	case AggCount:
-		return sql.Count, nil
-	default:
-		return nil, &common.QueryBuildError{
-			Op:  "Sort.dirBuilder",
-			Err: fmt.Errorf("unsupported aggregate %q", s.Aggregate),
-		}
-	}
+	return aggFunc(dialect, s.Aggregate, &s.AggParams)
 }
 
-func (s *Sort) Predicate(node entx.Node) (func(*sql.Selector), error) {
+func (s *Sort) Predicate(ctx context.Context, node entx.Node, dialect string) (func(*sql.Selector), error) {
 	direction, err := s.dirBuilder()
 	if err != nil {
 		return nil, err
 	}
-	agg, err := s.aggBuilder()
+
+	// the aggregate is selected under its alias by the same query
+	if s.alias != "" {
+		return func(sel *sql.Selector) {
+			sel.OrderBy(s.orderTerms(sel.Dialect(), direction, sel.Quote(s.alias))...)
+		}, nil
+	}
+
+	agg, err := s.aggBuilder(dialect)
 	if err != nil {
 		return nil, err
 	}
 
-	_, field, bridges, err := resolveChain(node, s.fieldParts)
+	final, field, bridges, err := resolveChain(node, s.fieldParts)
 	if err != nil {
 		return nil, &common.QueryBuildError{
 			Op:  "Sort.Predicate",
@@ -110,6 +192,10 @@ func (s *Sort) Predicate(node entx.Node) (func(*sql.Selector), error) {
 		}
 	}
 
+	if field != "" {
+		field = final.FieldByName(field).StorageName
+	}
+
 	if field == "" {
 		if s.Aggregate == AggCount {
 			field = "*"
@@ -122,6 +208,26 @@ func (s *Sort) Predicate(node entx.Node) (func(*sql.Selector), error) {
 	}
 
 	hasAgg := s.Aggregate != ""
+
+	// the pivot row of the related rows of an include is joined by their query
+	if len(bridges) > 0 {
+		if pivot, ok := bridges[0].(*pivotBridge); ok {
+			if hasAgg || len(bridges) != 1 || len(s.Values) > 0 {
+				return nil, &common.QueryBuildError{
+					Op:  "Sort.Predicate",
+					Err: fmt.Errorf(ErrPivotSort, pivot.Child().Name(), s.Field),
+				}
+			}
+			return pivot.orderBy(field, func(d, col string) []string {
+				return s.orderTerms(d, direction, col)
+			}), nil
+		}
+	}
+
+	if len(s.Values) > 0 {
+		return s.valuesPredicate(node, field, direction), nil
+	}
+
 	// cannot aggregate without relation
 	if hasAgg && len(bridges) == 0 {
 		return nil, &common.QueryBuildError{
@@ -130,6 +236,23 @@ func (s *Sort) Predicate(node entx.Node) (func(*sql.Selector), error) {
 		}
 	}
 
+	var preds []func(*sql.Selector)
+	if hasAgg {
+		// the related rows are aggregated as by an aggregate, under the policy of their node
+		policyPred, err := common.EnforcePolicy(ctx, final, common.OpAggregate)
+		if err != nil {
+			return nil, err
+		}
+		if policyPred != nil {
+			preds = append(preds, policyPred)
+		}
+		filtersPreds, err := s.Filters.Predicate(final)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, filtersPreds...)
+	}
+
 	// ensure non-M2O for direct sort
 	// for non-aggregate sorts, only M2O relations allowed
 	if !hasAgg && len(bridges) > 0 {
@@ -145,14 +268,15 @@ func (s *Sort) Predicate(node entx.Node) (func(*sql.Selector), error) {
 
 	return func(sel *sql.Selector) {
 		if len(bridges) == 0 {
-			sel.OrderBy(direction(sel.C(field)))
+			sel.OrderBy(s.orderTerms(sel.Dialect(), direction, sel.C(field))...)
 			return
 		}
 
 		last := bridges[len(bridges)-1]
 		subAlias := last.Child().Table()
-		fromTbl := sql.Table(subAlias).As("t0")
-		sub := sql.Dialect(sel.Dialect()).Select().From(fromTbl)
+		builder := sql.Dialect(sel.Dialect())
+		fromTbl := builder.Table(subAlias).As("t0")
+		sub := builder.Select().From(fromTbl)
 
 		prev := fromTbl
 		for i := len(bridges) - 1; i >= 1; i-- {
@@ -177,18 +301,55 @@ func (s *Sort) Predicate(node entx.Node) (func(*sql.Selector), error) {
 			selExpr = sql.As(selExpr, alias)
 		}
 		sub.Select(keyCol, selExpr).GroupBy(keyCol)
+		for _, p := range preds {
+			p(sub)
+		}
 
 		sel.LeftJoin(sub).
 			On(sel.C(rel.FinalLeftField), sub.C(rel.FinalRightField))
 
 		if alias != "" {
-			sel.OrderBy(direction(sub.C(alias)))
+			sel.OrderBy(s.orderTerms(sel.Dialect(), direction, sub.C(alias))...)
 		} else {
-			sel.OrderBy(direction(sub.C(field)))
+			sel.OrderBy(s.orderTerms(sel.Dialect(), direction, sub.C(field))...)
 		}
 	}, nil
 }
 
+// valuesPredicate orders the rows by the position of their value in the list of the sort.
+// The positions are computed by a sub-select joined on the primary keys, so that the order
+// is held by a column, as needed by the window numbering the rows of a limited include.
+func (s *Sort) valuesPredicate(node entx.Node, column string, direction func(string) string) func(*sql.Selector) {
+	return func(sel *sql.Selector) {
+		builder := sql.Dialect(sel.Dialect())
+		t := builder.Table(node.Table()).As("t0")
+		position := sql.ExprFunc(func(b *sql.Builder) {
+			b.WriteString("CASE")
+			for i, v := range s.Values {
+				b.WriteString(" WHEN ").WriteString(t.C(column)).WriteOp(sql.OpEQ).Arg(v).
+					WriteString(" THEN ").WriteString(strconv.Itoa(i))
+			}
+			b.WriteString(" ELSE ").WriteString(strconv.Itoa(len(s.Values))).WriteString(" END")
+		})
+
+		pks := node.PKs()
+		sub := builder.Select().From(t)
+		for _, pk := range pks {
+			sub.AppendSelect(t.C(pk.StorageName))
+		}
+		sub.AppendSelectExprAs(position, sortPositionAlias)
+
+		// the sub-select is aliased once joined
+		sel.Join(sub)
+		on := make([]*sql.Predicate, len(pks))
+		for i, pk := range pks {
+			on[i] = sql.ColumnsEQ(sel.C(pk.StorageName), sub.C(pk.StorageName))
+		}
+		sel.OnP(sql.And(on...))
+		sel.OrderBy(direction(sub.C(sortPositionAlias)))
+	}
+}
+
 func (s *Sort) ValidateAndPreprocess(cfg *common.SortConfig) error {
 	switch s.Direction {
 	case DirASC, DirDESC, "":
@@ -199,14 +360,40 @@ func (s *Sort) ValidateAndPreprocess(cfg *common.SortConfig) error {
 		}
 	}
 
-	switch s.Aggregate {
-	case AggAvg, AggSum, AggMin, AggMax, AggCount, "":
-	default:
+	if s.Aggregate != "" && !s.Aggregate.IsValid() {
 		return &common.ValidationError{
 			Rule: "SortAggregate",
 			Err:  fmt.Errorf("unsupported aggregate %q", s.Aggregate),
 		}
 	}
+	if err := s.AggParams.validate(s.Aggregate, cfg.FilterConfig.Dialect()); err != nil {
+		return err
+	}
+
+	switch s.Nulls {
+	case NullsFirst, NullsLast, "":
+	default:
+		return &common.ValidationError{
+			Rule: "SortNulls",
+			Err:  fmt.Errorf("unsupported nulls order %q", s.Nulls),
+		}
+	}
+
+	if err := s.validateValues(cfg); err != nil {
+		return err
+	}
+
+	if len(s.Filters) > 0 {
+		if s.Aggregate == "" {
+			return &common.ValidationError{
+				Rule: "SortFiltersWithoutAggregate",
+				Err:  fmt.Errorf("filters of sort %q require an aggregate", s.Field),
+			}
+		}
+		if err := s.Filters.ValidateAndPreprocess(cfg.FilterConfig); err != nil {
+			return err
+		}
+	}
 
 	if s.Field != "" {
 		parts, pos, ok := splitChain(s.Field)
@@ -217,10 +404,18 @@ func (s *Sort) ValidateAndPreprocess(cfg *common.SortConfig) error {
 			}
 		}
 
-		if cfg.MaxSortRelationDepth > 0 && len(parts)-1 > cfg.MaxSortRelationDepth {
+		// the relations of the filters are followed from the related rows
+		depth := len(parts) - 1 + s.Filters.relationDepth()
+		if cfg.MaxSortRelationDepth > 0 && depth > cfg.MaxSortRelationDepth {
 			return &common.ValidationError{
 				Rule: "MaxSortRelationsDepth",
-				Err:  fmt.Errorf("aggregate relation depth of %d exceeds max %d", len(parts)-1, cfg.MaxSortRelationDepth),
+				Err:  fmt.Errorf("aggregate relation depth of %d exceeds max %d", depth, cfg.MaxSortRelationDepth),
+			}
+		}
+		if len(s.Values) > 0 && len(parts) > 1 {
+			return &common.ValidationError{
+				Rule: "SortValues",
+				Err:  fmt.Errorf(ErrSortValues, s.Field),
 			}
 		}
 		s.fieldParts = parts
@@ -229,3 +424,32 @@ func (s *Sort) ValidateAndPreprocess(cfg *common.SortConfig) error {
 	s.preprocessed = true
 	return nil
 }
+
+var ErrSortValues = "sort on a list of values requires a field of the node without aggregate, got %q"
+
+func (s *Sort) validateValues(cfg *common.SortConfig) error {
+	if s.Values == nil {
+		return nil
+	}
+	if len(s.Values) == 0 || s.Aggregate != "" || s.Field == "" {
+		return &common.ValidationError{
+			Rule: "SortValues",
+			Err:  fmt.Errorf(ErrSortValues, s.Field),
+		}
+	}
+	if cfg.MaxSortValues > 0 && len(s.Values) > cfg.MaxSortValues {
+		return &common.ValidationError{
+			Rule: "MaxSortValues",
+			Err:  fmt.Errorf("sort values count of %d exceeds max %d", len(s.Values), cfg.MaxSortValues),
+		}
+	}
+	for i, v := range s.Values {
+		if !IsPrimitive(v) {
+			return &common.ValidationError{
+				Rule: "SortValues",
+				Err:  fmt.Errorf("sort value at index %d must be a primitive, got %T", i, v),
+			}
+		}
+	}
+	return nil
+}
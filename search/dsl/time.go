@@ -0,0 +1,113 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTimeValue = "invalid time value %q, expected RFC3339, date (2006-01-02) or relative expression (now-7d)"
+)
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+}
+
+// ParseTime parses an absolute time literal or a relative expression resolved against now.
+// Relative expressions start with "now" followed by any number of signed offsets
+// whose unit is one of s, m, h, d, w, M or y (e.g. "now", "now-7d", "now-1M+2h").
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if rest, ok := strings.CutPrefix(value, "now"); ok {
+		return parseRelativeTime(value, rest, now)
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(ErrInvalidTimeValue, value)
+}
+
+func parseRelativeTime(value, rest string, now time.Time) (time.Time, error) {
+	t := now
+	for rest != "" {
+		sign := 1
+		switch rest[0] {
+		case '+':
+		case '-':
+			sign = -1
+		default:
+			return time.Time{}, fmt.Errorf(ErrInvalidTimeValue, value)
+		}
+		rest = rest[1:]
+
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return time.Time{}, fmt.Errorf(ErrInvalidTimeValue, value)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return time.Time{}, fmt.Errorf(ErrInvalidTimeValue, value)
+		}
+		n *= sign
+
+		switch rest[i] {
+		case 's':
+			t = t.Add(time.Duration(n) * time.Second)
+		case 'm':
+			t = t.Add(time.Duration(n) * time.Minute)
+		case 'h':
+			t = t.Add(time.Duration(n) * time.Hour)
+		case 'd':
+			t = t.AddDate(0, 0, n)
+		case 'w':
+			t = t.AddDate(0, 0, 7*n)
+		case 'M':
+			t = t.AddDate(0, n, 0)
+		case 'y':
+			t = t.AddDate(n, 0, 0)
+		default:
+			return time.Time{}, fmt.Errorf(ErrInvalidTimeValue, value)
+		}
+		rest = rest[i+1:]
+	}
+	return t, nil
+}
+
+// IsTimeString reports whether val is a string holding a valid time literal.
+func IsTimeString(val any, now time.Time) bool {
+	s, ok := val.(string)
+	if !ok {
+		return false
+	}
+	_, err := ParseTime(s, now)
+	return err == nil
+}
+
+// resolveTimeValue converts the string literals of a filter value into time.Time.
+func resolveTimeValue(value any, now time.Time) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return ParseTime(v, now)
+	case []any:
+		values := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := resolveTimeValue(item, now)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = resolved
+		}
+		return values, nil
+	default:
+		return value, nil
+	}
+}
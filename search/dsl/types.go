@@ -0,0 +1,185 @@
+package dsl
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+var (
+	ErrFieldValueType    = "field %q of type %s cannot be compared with a %T value"
+	ErrFieldOperatorType = "operator %q is not supported on field %q of type %s"
+	ErrFieldNotNullable  = "operator %q is not supported on field %q which is neither optional nor nillable"
+	ErrFieldEnumValue    = "value %q is not allowed for enum field %q, expected one of %q"
+	ErrFieldAggType      = "aggregate %q is not supported on field %q of type %s"
+	ErrFieldSortType     = "cannot sort on field %q of type %s"
+	ErrHavingAggType     = "having on %q compares a number, but aggregate %q returns a %s"
+)
+
+// checkFilterField verifies that the operator and the value of a filter
+// are compatible with the type of the targeted field.
+func checkFilterField(f *entx.Field, op Operator, value any) error {
+	var supported bool
+	switch op {
+	case OpEmpty:
+		return nil
+	case OpIsNull, OpIsNotNull:
+		if !f.IsNullable() {
+			return &common.ValidationError{
+				Rule: "FilterOperatorType",
+				Err:  fmt.Errorf(ErrFieldNotNullable, op, f.Name),
+			}
+		}
+		return nil
+	case OpGreaterThan, OpGreaterEqual,
+		OpLessThan, OpLessEqual,
+		OpBetween, OpNotBetween:
+		// range values are numbers or times, strings are ordered but not comparable with them.
+		supported = f.Type.IsOrdered() && f.Type != entx.TypeString
+	case OpLike, OpNotLike,
+		OpStartsWith, OpEndsWith,
+		OpILike, OpEqualFold, OpRegex:
+		supported = f.Type == entx.TypeString || f.Type == entx.TypeOther
+	default:
+		supported = f.Type != entx.TypeJSON
+	}
+	if !supported {
+		return &common.ValidationError{
+			Rule: "FilterOperatorType",
+			Err:  fmt.Errorf(ErrFieldOperatorType, op, f.Name, f.Type),
+		}
+	}
+
+	if values, ok := value.([]any); ok {
+		for _, v := range values {
+			if err := checkFieldValue(f, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return checkFieldValue(f, value)
+}
+
+func checkFieldValue(f *entx.Field, value any) error {
+	var ok bool
+	switch f.Type {
+	case entx.TypeInt:
+		ok = IsInteger(value)
+	case entx.TypeFloat:
+		ok = IsNumber(value)
+	case entx.TypeString, entx.TypeUUID:
+		ok = IsString(value)
+	case entx.TypeBool:
+		_, ok = value.(bool)
+	case entx.TypeTime:
+		switch value.(type) {
+		case string, time.Time:
+			ok = true
+		}
+	case entx.TypeEnum:
+		s, isString := value.(string)
+		if isString && !f.HasEnum(s) {
+			return &common.ValidationError{
+				Rule: "FilterEnumValue",
+				Err:  fmt.Errorf(ErrFieldEnumValue, s, f.Name, f.Enums),
+			}
+		}
+		ok = isString
+	default:
+		ok = true
+	}
+	if !ok {
+		return &common.ValidationError{
+			Rule: "FilterValueType",
+			Err:  fmt.Errorf(ErrFieldValueType, f.Name, f.Type, value),
+		}
+	}
+	return nil
+}
+
+// checkAggregateField verifies that an aggregate function can be applied on the field type.
+func checkAggregateField(f *entx.Field, agg Agg) error {
+	var supported bool
+	switch agg {
+	case AggSum, AggAvg:
+		supported = f.Type.IsNumeric() || f.Type == entx.TypeOther
+	case AggMin, AggMax:
+		supported = f.Type.IsOrdered()
+	case AggStddev, AggVariance, AggMedian, AggPercentile:
+		supported = f.Type.IsNumeric()
+	case AggBoolAnd, AggBoolOr:
+		supported = f.Type == entx.TypeBool
+	case AggCountDistinct, AggStringAgg, AggGroupConcat:
+		supported = f.Type != entx.TypeJSON
+	default:
+		supported = true
+	}
+	if !supported {
+		return &common.ValidationError{
+			Rule: "AggregateFieldType",
+			Err:  fmt.Errorf(ErrFieldAggType, agg, f.Name, f.Type),
+		}
+	}
+	return nil
+}
+
+// checkGroupField verifies that the field can be used to group rows.
+func checkGroupField(f *entx.Field, name string) error {
+	if f.Type == entx.TypeJSON {
+		return &common.ValidationError{
+			Rule: "GroupByFieldType",
+			Err:  fmt.Errorf(ErrGroupByType, name, f.Type),
+		}
+	}
+	return nil
+}
+
+// checkHavingAggregate verifies that the aggregate compared by a having condition returns a number,
+// f being the aggregated field or nil for '*'.
+func checkHavingAggregate(h *Having, agg Agg, f *entx.Field) error {
+	var result entx.FieldType
+	switch {
+	case (agg == AggMin || agg == AggMax) && f != nil && !f.Type.IsNumeric() && f.Type != entx.TypeOther:
+		result = f.Type
+	case agg == AggBoolAnd || agg == AggBoolOr:
+		result = entx.TypeBool
+	case agg == AggStringAgg || agg == AggGroupConcat:
+		result = entx.TypeString
+	default:
+		return nil
+	}
+	return &common.ValidationError{
+		Rule: "HavingAggregateType",
+		Err:  fmt.Errorf(ErrHavingAggType, h.Aggregate, agg, result),
+	}
+}
+
+// checkSortField verifies that the field can be used to order results.
+func checkSortField(f *entx.Field) error {
+	if f.Type == entx.TypeJSON {
+		return &common.ValidationError{
+			Rule: "SortFieldType",
+			Err:  fmt.Errorf(ErrFieldSortType, f.Name, f.Type),
+		}
+	}
+	return nil
+}
+
+func IsInteger(val any) bool {
+	switch v := val.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return true
+	case float32:
+		return v == float32(math.Trunc(float64(v)))
+	case float64:
+		// numbers decoded from JSON are float64
+		return v == math.Trunc(v)
+	default:
+		return false
+	}
+}
@@ -8,20 +8,35 @@ import (
 )
 
 var (
-	ErrChainBroken = "invalid chain: field %q cannot appear before the end (in node %q)"
-	ErrUnknownLink = "invalid chain: segment %q is neither a field nor a relation of node %q"
+	ErrChainBroken  = "invalid chain: field %q cannot appear before the end (in node %q)"
+	ErrUnknownLink  = "invalid chain: segment %q is neither a field nor a relation of node %q"
+	ErrFieldMissing = "invalid chain: %q does not end with a field of node %q"
+	ErrNotARelation = "invalid chain: %q is not a relation chain of node %q"
 )
 
 // resolveChain traverses a list of segments starting from a start node.
 // Returns the final node, the name of the terminal field (or empty if no field) and
 // the slice of bridges traversed.
 func resolveChain(start entx.Node, parts []string) (current entx.Node, field string, bridges []entx.Bridge, err error) {
+	return walkChain(start, parts, false)
+}
+
+// resolvePivotChain is like resolveChain, the rows reached through an M2M relation backed by
+// an edge schema exposing their pivot row as the "pivot" relation of filters.
+func resolvePivotChain(start entx.Node, parts []string) (current entx.Node, field string, bridges []entx.Bridge, err error) {
+	return walkChain(start, parts, true)
+}
+
+func walkChain(start entx.Node, parts []string, pivots bool) (current entx.Node, field string, bridges []entx.Bridge, err error) {
 	current = start
 	bridges = make([]entx.Bridge, 0, len(parts))
 	for i, seg := range parts {
 		if b := current.Bridge(seg); b != nil {
 			bridges = append(bridges, b)
 			current = b.Child()
+			if pivots {
+				current = withPivot(current, b, false)
+			}
 		} else if f := current.FieldByName(seg); f != nil {
 			if i != len(parts)-1 {
 				err = fmt.Errorf(ErrChainBroken, seg, current.Name())
@@ -0,0 +1,215 @@
+package search
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/common"
+)
+
+var errExplainContext = errors.New("search not built with an explain context")
+
+// Explain validates and builds the query, and returns the statements its execution would run
+// without executing them. With plan, EXPLAIN is run on each statement in the configured dialect.
+func (q *TargetedQuery) Explain(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *Config,
+	plan bool,
+) (*ExplainResponse, error) {
+	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
+	defer cancel()
+	ctx = common.ContextWithExplain(ctx)
+
+	if err := q.ValidateAndPreprocess(cfg); err != nil {
+		return nil, err
+	}
+	if err := q.CheckTypes(graph); err != nil {
+		return nil, err
+	}
+
+	build, err := q.Build(ctx, cfg, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := build.Explain(ctx, client, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if build.IsPaginated() {
+		paginate := &ExplainedQuery{Kind: common.ExplainKindPaginate}
+		paginate.SQL, paginate.Args = build.Paginate.CountSelector.Query()
+		queries = append(queries, paginate)
+	}
+
+	return newExplainResponse(ctx, client, cfg, plan, queries)
+}
+
+func (group *QueryGroup) Explain(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *Config,
+	plan bool,
+) (*ExplainResponse, error) {
+	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
+	defer cancel()
+	ctx = common.ContextWithExplain(ctx)
+
+	if err := group.ValidateAndPreprocessFinal(cfg); err != nil {
+		return nil, err
+	}
+	if err := group.CheckTypes(graph); err != nil {
+		return nil, err
+	}
+
+	build, err := group.BuildClassified(ctx, cfg, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := build.Explain(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return newExplainResponse(ctx, client, cfg, plan, queries)
+}
+
+func (q *QueryBundle) Explain(
+	ctx context.Context,
+	client entx.Client,
+	graph entx.Graph,
+	cfg *Config,
+	plan bool,
+) (*ExplainResponse, error) {
+	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
+	defer cancel()
+	ctx = common.ContextWithExplain(ctx)
+
+	if err := q.ValidateAndPreprocessFinal(cfg); err != nil {
+		return nil, err
+	}
+	if err := q.CheckTypes(graph); err != nil {
+		return nil, err
+	}
+
+	build, err := q.BuildClassified(ctx, cfg, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := build.Explain(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return newExplainResponse(ctx, client, cfg, plan, queries)
+}
+
+func newExplainResponse(
+	ctx context.Context,
+	client entx.Client,
+	cfg *Config,
+	plan bool,
+	queries []*ExplainedQuery,
+) (*ExplainResponse, error) {
+	res := &ExplainResponse{Queries: queries}
+	if plan {
+		if err := res.ExplainPlans(ctx, client, cfg.Dialect); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Explain renders the statements of the builds in their execution order,
+// the transactions being rendered last.
+func (builds *ClassifiedBuilds) Explain(ctx context.Context, client entx.Client, cfg *Config) ([]*ExplainedQuery, error) {
+	var queries []*ExplainedQuery
+	for _, build := range builds.Searches {
+		search, err := build.Explain(ctx, client, build.Key)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, search...)
+	}
+
+	for _, group := range builds.scalarGroups(cfg, builds.calculateSizes()) {
+		if len(group) > 0 {
+			queries = append(queries, common.ExplainScalars(group))
+		}
+	}
+
+	for _, bucket := range builds.Buckets {
+		queries = append(queries, common.ExplainBucketQuery(bucket))
+	}
+
+	for _, tx := range builds.Transactions {
+		txQueries, err := tx.Explain(ctx, client, cfg)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, txQueries...)
+	}
+	return queries, nil
+}
+
+func (build *TxQueryGroupBuild) Explain(ctx context.Context, client entx.Client, cfg *Config) ([]*ExplainedQuery, error) {
+	var queries []*ExplainedQuery
+	for _, s := range build.Searches {
+		search, err := s.Explain(ctx, client, s.Key)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, search...)
+	}
+
+	scalars, _ := build.prepareScalars()
+	for _, group := range common.SplitInChunks(scalars, cfg.ScalarQueriesChunkSize) {
+		if len(group) > 0 {
+			queries = append(queries, common.ExplainScalars(group))
+		}
+	}
+
+	for _, bucket := range build.Buckets {
+		queries = append(queries, common.ExplainBucketQuery(bucket))
+	}
+	return queries, nil
+}
+
+// Explain renders the search query and its include queries, which are rendered without
+// the condition on the keys of their parents added by ent when loading them.
+// The context must come from common.ContextWithExplain and have been used to build the search.
+func (build *QueryOptionsBuild) Explain(ctx context.Context, client entx.Client, key string) ([]*ExplainedQuery, error) {
+	recorder := common.ExplainRecorderFrom(ctx)
+	if recorder == nil {
+		return nil, &ExecError{
+			Op:  "QueryOptionsBuild.Explain",
+			Err: errExplainContext,
+		}
+	}
+
+	q := build.QueryFn(client)
+	includes := recorder.TakeIncludes()
+
+	search := &ExplainedQuery{Kind: common.ExplainKindSearch, Key: key}
+	var err error
+	if search.SQL, search.Args, err = common.DryRun(ctx, q); err != nil {
+		return nil, err
+	}
+
+	queries := []*ExplainedQuery{search}
+	for _, inc := range includes {
+		include := &ExplainedQuery{Kind: common.ExplainKindInclude, Key: key, Include: inc.Path}
+		if include.SQL, include.Args, err = common.DryRun(ctx, inc.Query); err != nil {
+			return nil, err
+		}
+		queries = append(queries, include)
+	}
+	return queries, nil
+}
@@ -16,17 +16,30 @@ type QueryOp = common.QueryOp
 
 const OpAggregate = common.OpAggregate
 const OpAggregateOverall = common.OpAggregateOverall
+const OpAggregateGrouped = common.OpAggregateGrouped
+const OpAggregateHistogram = common.OpAggregateHistogram
 const OpRootQuery = common.OpRootQuery
 const OpIncludeQuery = common.OpIncludeQuery
 const OpLastIncludeQuery = common.OpLastIncludeQuery
 
 type OverallAggregate = dsl.OverallAggregate
 type OverallAggregates = dsl.OverallAggregates
+type GroupedAggregate = dsl.GroupedAggregate
+type GroupedAggregates = dsl.GroupedAggregates
+type DateHistogram = dsl.DateHistogram
+type DateHistograms = dsl.DateHistograms
 
 type AggregatesResponse = common.AggregatesResponse
+type Bucket = common.Bucket
 type MetaResponse = common.MetaResponse
 type MetaSearchResponse = common.MetaSearchResponse
 type SearchResponse = common.SearchResponse
 type SearchesResponse = common.SearchesResponse
 type GroupResponse = common.GroupResponse
 type GroupResponseSync = common.GroupResponseSync
+type ExplainedQuery = common.ExplainedQuery
+type ExplainResponse = common.ExplainResponse
+
+type CostWeights = common.CostWeights
+type CostConfig = common.CostConfig
+type Cost = common.Cost
@@ -54,6 +54,8 @@ type Config struct {
 	IncludeAllNodes  bool
 	IncludeAllFields bool
 	Nodes            map[string]*NodeConfig
+	// GraphQL generates the GraphQL SDL of the searchable nodes and its resolver.
+	GraphQL bool
 }
 
 func NewConfig(opts ...Option) *Config {
@@ -147,6 +149,9 @@ func GlobalExcludeNodes() Option  { return func(c *Config) { c.IncludeAllNodes =
 func GlobalIncludeFields() Option { return func(c *Config) { c.IncludeAllFields = true } }
 func GlobalExcludeFields() Option { return func(c *Config) { c.IncludeAllFields = false } }
 
+// WithGraphQL generates entx/schema.graphql and the resolver executing it through the search module.
+func WithGraphQL() Option { return func(c *Config) { c.GraphQL = true } }
+
 func SetNodesInclusion(included bool, names ...string) Option {
 	return func(c *Config) {
 		for _, name := range names {
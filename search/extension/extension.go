@@ -5,13 +5,16 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
 	"entgo.io/ent/entc"
 	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -31,6 +34,7 @@ var (
 		"isNodeInclude":  nil,
 		"debug":          debug,
 		"isGenType":      isGenType,
+		"fieldType":      fieldType,
 	}
 )
 
@@ -102,6 +106,13 @@ func (e *Extension) Hooks() []gen.Hook {
 					{template: e.newTemplate("graph.tmpl"), params: entxGraph},
 					{template: e.newTemplate("adapters.tmpl"), params: g},
 				}
+				if e.conf.GraphQL {
+					schema := prepareGraphQL(entxGraph)
+					fileInfos = append(fileInfos,
+						&genFileInfo{template: e.newTemplate("schema.tmpl"), params: schema, fileName: "schema.graphql"},
+						&genFileInfo{template: e.newTemplate("graphql.tmpl"), params: schema},
+					)
+				}
 
 				return genFiles(g.Target, fileInfos...)
 			})
@@ -119,6 +130,8 @@ func (e *Extension) newTemplate(name string) *gen.Template {
 type genFileInfo struct {
 	template *gen.Template
 	params   any
+	// defaults to the template name with the .go extension
+	fileName string
 }
 
 func genFiles(rootPath string, fileInfos ...*genFileInfo) error {
@@ -144,7 +157,11 @@ func genFiles(rootPath string, fileInfos ...*genFileInfo) error {
 					return fmt.Errorf("execute %w", err)
 				}
 
-				outPath := rootPath + "entx/" + templateName + ".go"
+				fileName := fi.fileName
+				if fileName == "" {
+					fileName = templateName + ".go"
+				}
+				outPath := rootPath + "entx/" + fileName
 
 				if err := os.WriteFile(outPath, buf.Bytes(), 0o644); err != nil {
 					return fmt.Errorf("write file %s: %w", outPath, err)
@@ -166,6 +183,8 @@ type GenGraph struct {
 	EntGraph    *gen.Graph
 	Nodes       []GenNode
 	BridgePairs []GenBridgePair
+	// packages of the field types declared outside of the node packages
+	Imports []string
 }
 
 type GenNode struct {
@@ -175,7 +194,9 @@ type GenNode struct {
 	TableName     string
 	Columns       []*gen.Field
 	PKs           []*gen.Field
-	EntNode       *gen.Type
+	// Indexed are the names of the columns usable as the leading column of an index.
+	Indexed map[string]bool
+	EntNode *gen.Type
 }
 
 type GenBridgePair struct {
@@ -198,15 +219,40 @@ type GenBridge struct {
 	PivotTable         string
 	PivotLeftField     string
 	PivotRightField    string
+	// lower name of the edge schema node holding the pivot rows of an M2M relation, if any
+	LowerPivotNodeName string
+	// ent edge from the left node to its pivot rows and field of a pivot row holding the key
+	// of the right node, used to include the pivot rows with the children, if any
+	PivotEdge       *gen.Edge
+	PivotChildField string
+	// ent edge from the left node, used to mutate the relation
+	Edge *gen.Edge
 }
 
 func (ext *Extension) prepareGenGraph(g *gen.Graph) (*GenGraph, error) {
 	graph := &GenGraph{EntGraph: g}
 	graph.Nodes = ext.buildGenNodes(g.Nodes)
 	graph.BridgePairs = ext.buildBridgePairs(graph.Nodes)
+	graph.Imports = fieldImports(graph.Nodes)
 	return graph, nil
 }
 
+// fieldImports returns the import paths of the field types that are not declared in a node package.
+func fieldImports(nodes []GenNode) []string {
+	set := make(map[string]struct{})
+	for _, n := range nodes {
+		for _, f := range n.Columns {
+			if f.Type != nil && f.Type.PkgPath != "" {
+				set[f.Type.PkgPath] = struct{}{}
+			}
+		}
+	}
+	for _, n := range nodes {
+		delete(set, n.EntNode.Config.Package+"/"+n.EntNode.PackageDir())
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
 func (ext *Extension) buildGenNodes(nodes []*gen.Type) []GenNode {
 	var result []GenNode
 	for _, node := range nodes {
@@ -233,6 +279,7 @@ func (ext *Extension) mapToGenNode(node *gen.Type) GenNode {
 	pks = append(pks, node.EdgeSchema.ID...)
 
 	return GenNode{
+		Indexed:       indexedColumns(node, cols, pks),
 		HasPolicy:     node.NumPolicy() > 0,
 		EntNode:       node,
 		NodeName:      node.Name,
@@ -243,27 +290,87 @@ func (ext *Extension) mapToGenNode(node *gen.Type) GenNode {
 	}
 }
 
+// indexedColumns returns the names of the columns which are the first primary key,
+// are unique or are the first column of an index of the node.
+func indexedColumns(node *gen.Type, cols, pks []*gen.Field) map[string]bool {
+	indexed := make(map[string]bool)
+	if len(pks) > 0 {
+		indexed[pks[0].Name] = true
+	}
+	for _, f := range cols {
+		if f.Unique {
+			indexed[f.Name] = true
+		}
+		for _, idx := range node.Indexes {
+			if len(idx.Columns) > 0 && idx.Columns[0] == f.StorageKey() {
+				indexed[f.Name] = true
+			}
+		}
+	}
+	return indexed
+}
+
 func (ext *Extension) buildBridgePairs(genNodes []GenNode) []GenBridgePair {
 	var pairs []GenBridgePair
 	for _, gn := range genNodes {
 		node := gn.EntNode
 		for _, e := range node.Edges {
-			if e.Owner != node || e.Ref == nil || !ext.IsNodeInclude(e.Type) {
+			// a self-referential inverse edge is also owned by its node,
+			// it is generated with the edge it references
+			if e.Owner != node || e.Ref == nil || (e.IsInverse() && e.Type == node) || !ext.IsNodeInclude(e.Type) {
 				continue
 			}
 			forward := makeForwardGenBridge(e)
+			through := edgeSchema(e)
+			if through != nil && ext.IsNodeInclude(through) {
+				forward.LowerPivotNodeName = lowerFirst(through.Name)
+			} else {
+				through = nil
+			}
 			inverse := makeInverseGenBridge(forward, e)
+			if through != nil {
+				setPivotEdge(&forward, through)
+				setPivotEdge(inverse, through)
+			}
 			pairs = append(pairs, GenBridgePair{forward, inverse})
 		}
 	}
 	return pairs
 }
 
-func makeInverseGenBridge(forward GenBridge, e *gen.Edge) *GenBridge {
-	if e.Type.Name == e.Ref.Type.Name {
-		return nil
+// edgeSchema returns the edge schema of an M2M edge declared with Through on either side.
+func edgeSchema(e *gen.Edge) *gen.Type {
+	if e.Through != nil {
+		return e.Through
+	}
+	if e.Ref != nil {
+		return e.Ref.Through
 	}
+	return nil
+}
+
+// setPivotEdge sets the edge from the left node of an M2M bridge to the rows of its edge schema,
+// declared when the edge goes through it from this side.
+func setPivotEdge(b *GenBridge, through *gen.Type) {
+	if b.RightNode.ID == nil {
+		return
+	}
+	for _, e := range b.LeftNode.Edges {
+		if e.Type == through && e.Rel.Type == gen.O2M && e.Rel.Column() == b.PivotLeftField {
+			b.PivotEdge = e
+		}
+	}
+	for _, f := range through.Fields {
+		if f.StorageKey() == b.PivotRightField {
+			b.PivotChildField = f.StructField()
+		}
+	}
+	if b.PivotChildField == "" {
+		b.PivotEdge = nil
+	}
+}
 
+func makeInverseGenBridge(forward GenBridge, e *gen.Edge) *GenBridge {
 	var relType gen.Rel
 	switch v := e.Rel.Type; v {
 	case gen.M2M, gen.O2O:
@@ -276,17 +383,19 @@ func makeInverseGenBridge(forward GenBridge, e *gen.Edge) *GenBridge {
 
 	structField := e.Ref.StructField()
 	inverse := GenBridge{
-		StructField:     structField,
-		Name:            fmt.Sprintf("%s%sBridge", e.Type.Name, structField),
-		LeftNode:        e.Type,
-		RightNode:       e.Owner,
-		RelName:         e.Ref.Name,
-		LeftField:       forward.RightField,
-		RightField:      forward.LeftField,
-		PivotTable:      forward.PivotTable,
-		PivotLeftField:  forward.PivotRightField,
-		PivotRightField: forward.PivotLeftField,
-		RelType:         relType.String(),
+		StructField:        structField,
+		Name:               fmt.Sprintf("%s%sBridge", e.Type.Name, structField),
+		LeftNode:           e.Type,
+		RightNode:          e.Owner,
+		RelName:            e.Ref.Name,
+		LeftField:          forward.RightField,
+		RightField:         forward.LeftField,
+		PivotTable:         forward.PivotTable,
+		PivotLeftField:     forward.PivotRightField,
+		PivotRightField:    forward.PivotLeftField,
+		RelType:            relType.String(),
+		Edge:               e.Ref,
+		LowerPivotNodeName: forward.LowerPivotNodeName,
 	}
 	inverse.LowerName = lowerFirst(inverse.Name)
 	inverse.LowerLeftNodeName = lowerFirst(inverse.LeftNode.Name)
@@ -308,6 +417,7 @@ func makeForwardGenBridge(e *gen.Edge) GenBridge {
 		LowerRightNodeName: lowerFirst(e.Type.Name),
 		RelName:            e.Name,
 		RelType:            e.Rel.Type.String(),
+		Edge:               e,
 	}
 	// assign fields
 	if e.Owner.ID != nil {
@@ -324,7 +434,15 @@ func makeForwardGenBridge(e *gen.Edge) GenBridge {
 			b.RightField = e.Type.ID.StorageKey()
 		}
 	case gen.O2M, gen.M2O, gen.O2O:
-		b.RightField = e.Rel.Column()
+		// the foreign key is held by the table of the owner, as for the manager of an employee
+		if e.OwnFK() {
+			b.LeftField = e.Rel.Column()
+			if e.Type.ID != nil {
+				b.RightField = e.Type.ID.StorageKey()
+			}
+		} else {
+			b.RightField = e.Rel.Column()
+		}
 	}
 	return b
 }
@@ -336,6 +454,35 @@ func lowerFirst(s string) string {
 	return strings.ToLower(s[:1]) + s[1:]
 }
 
+// fieldType returns the name of the entx.FieldType constant matching an ent field.
+func fieldType(f *gen.Field) string {
+	if f.Type == nil {
+		return "TypeOther"
+	}
+	switch t := f.Type.Type; {
+	case t.Integer():
+		return "TypeInt"
+	case t.Float():
+		return "TypeFloat"
+	case t == field.TypeString:
+		return "TypeString"
+	case t == field.TypeBool:
+		return "TypeBool"
+	case t == field.TypeTime:
+		return "TypeTime"
+	case t == field.TypeEnum:
+		return "TypeEnum"
+	case t == field.TypeJSON:
+		return "TypeJSON"
+	case t == field.TypeUUID:
+		return "TypeUUID"
+	case t == field.TypeBytes:
+		return "TypeBytes"
+	default:
+		return "TypeOther"
+	}
+}
+
 func debug(v any) string {
 	fmt.Printf("DEBUG: %#v\n", v)
 	return ""
@@ -0,0 +1,174 @@
+package extension
+
+import (
+	"regexp"
+	"sort"
+
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
+)
+
+// aggregateFieldName is the field computing per-entity aggregates on every type of the schema.
+const aggregateFieldName = "aggregate"
+
+var graphQLName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// GraphQLSchema holds what the GraphQL templates need to render the SDL and its resolver.
+type GraphQLSchema struct {
+	Nodes []GraphQLNode
+	Enums []GraphQLEnum
+}
+
+type GraphQLNode struct {
+	Name string
+	// Root is the field of the Query type searching the node.
+	Root   string
+	Fields []GraphQLField
+	Edges  []GraphQLEdge
+	// Aggregate is false when a field or an edge already uses the aggregate field name.
+	Aggregate bool
+}
+
+type GraphQLField struct {
+	Name string
+	// Type is the GraphQL output type, non-null unless the field is nillable.
+	Type string
+	// Filter is the input type filtering the field, empty if it cannot be filtered.
+	Filter   string
+	Sortable bool
+}
+
+type GraphQLEdge struct {
+	Name   string
+	Node   string
+	Unique bool
+	// filter keys of the quantifiers, empty when the name is already taken
+	HasKey  string
+	AllKey  string
+	NoneKey string
+}
+
+type GraphQLEnum struct {
+	Name   string
+	Values []string
+}
+
+// prepareGraphQL maps the generated graph onto the GraphQL types.
+// Edges are the bridges of the graph, fields of unsupported types are left out.
+func prepareGraphQL(g *GenGraph) *GraphQLSchema {
+	plural := gen.Funcs["plural"].(func(string) string)
+	pascal := gen.Funcs["pascal"].(func(string) string)
+
+	edges := make(map[string][]GraphQLEdge, len(g.Nodes))
+	addEdge := func(b *GenBridge) {
+		edges[b.LeftNode.Name] = append(edges[b.LeftNode.Name], GraphQLEdge{
+			Name:   b.RelName,
+			Node:   b.RightNode.Name,
+			Unique: b.RelType == gen.M2O.String() || b.RelType == gen.O2O.String(),
+		})
+	}
+	for _, pair := range g.BridgePairs {
+		addEdge(&pair.Forward)
+		if pair.Inverse != nil {
+			addEdge(pair.Inverse)
+		}
+	}
+
+	schema := &GraphQLSchema{}
+	for _, n := range g.Nodes {
+		node := GraphQLNode{
+			Name:      n.NodeName,
+			Root:      lowerFirst(plural(n.NodeName)),
+			Edges:     edges[n.NodeName],
+			Aggregate: true,
+		}
+		sort.Slice(node.Edges, func(i, j int) bool { return node.Edges[i].Name < node.Edges[j].Name })
+
+		taken := make(map[string]bool, len(n.Columns)+len(node.Edges))
+		for _, e := range node.Edges {
+			taken[e.Name] = true
+		}
+		// the id column is appended last, render it first
+		cols := n.Columns
+		if id := n.EntNode.ID; id != nil && len(cols) > 0 && cols[len(cols)-1] == id {
+			cols = append([]*gen.Field{id}, cols[:len(cols)-1]...)
+		}
+		for _, f := range cols {
+			if taken[f.Name] || !graphQLName.MatchString(f.Name) {
+				continue
+			}
+			gf, enum, ok := graphQLField(n.NodeName+pascal(f.Name), f)
+			if !ok {
+				continue
+			}
+			if enum != nil {
+				schema.Enums = append(schema.Enums, *enum)
+			}
+			taken[f.Name] = true
+			node.Fields = append(node.Fields, gf)
+		}
+		node.Aggregate = !taken[aggregateFieldName]
+
+		filterKey := func(name string) string {
+			if taken[name] || name == "and" || name == "or" || name == "not" {
+				return ""
+			}
+			taken[name] = true
+			return name
+		}
+		for i := range node.Edges {
+			e := &node.Edges[i]
+			e.HasKey = filterKey("has_" + e.Name)
+			if !e.Unique {
+				e.AllKey = filterKey(e.Name + "_all")
+				e.NoneKey = filterKey(e.Name + "_none")
+			}
+		}
+		schema.Nodes = append(schema.Nodes, node)
+	}
+	return schema
+}
+
+// graphQLField returns the GraphQL field of an ent field, enumName naming its enum type if any.
+func graphQLField(enumName string, f *gen.Field) (GraphQLField, *GraphQLEnum, bool) {
+	if f.Type == nil {
+		return GraphQLField{}, nil, false
+	}
+	var (
+		gf   = GraphQLField{Name: f.Name, Sortable: true}
+		enum *GraphQLEnum
+	)
+	switch t := f.Type.Type; {
+	case t.Integer():
+		gf.Type, gf.Filter = "Int", "IntFilter"
+	case t.Float():
+		gf.Type, gf.Filter = "Float", "FloatFilter"
+	case t == field.TypeString, t == field.TypeUUID:
+		gf.Type, gf.Filter = "String", "StringFilter"
+	case t == field.TypeBool:
+		gf.Type, gf.Filter = "Boolean", "BooleanFilter"
+	case t == field.TypeTime:
+		gf.Type, gf.Filter = "Time", "TimeFilter"
+	case t == field.TypeJSON:
+		gf.Type, gf.Sortable = "JSON", false
+	case t == field.TypeEnum:
+		values := f.EnumValues()
+		for _, v := range values {
+			// values that are not GraphQL names fall back on strings
+			if !graphQLName.MatchString(v) || v == "true" || v == "false" || v == "null" {
+				gf.Type, gf.Filter = "String", "StringFilter"
+				break
+			}
+		}
+		if gf.Type == "" {
+			enum = &GraphQLEnum{Name: enumName, Values: values}
+			gf.Type, gf.Filter = enumName, enumName+"Filter"
+		}
+	default:
+		return GraphQLField{}, nil, false
+	}
+	if !f.Nillable {
+		gf.Type += "!"
+	}
+	return gf, enum, true
+}
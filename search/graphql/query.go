@@ -0,0 +1,410 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/brice-74/entx/search/dsl"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// aggregateField is the field of the generated types computing an aggregate per entity.
+const aggregateField = "aggregate"
+
+// filterOps maps the keys of the scalar filter inputs onto the filter operators.
+var filterOps = map[string]dsl.Operator{
+	"eq":          dsl.OpEqual,
+	"neq":         dsl.OpNotEqual,
+	"gt":          dsl.OpGreaterThan,
+	"gte":         dsl.OpGreaterEqual,
+	"lt":          dsl.OpLessThan,
+	"lte":         dsl.OpLessEqual,
+	"in":          dsl.OpIn,
+	"not_in":      dsl.OpNotIn,
+	"between":     dsl.OpBetween,
+	"not_between": dsl.OpNotBetween,
+	"like":        dsl.OpLike,
+	"not_like":    dsl.OpNotLike,
+	"starts_with": dsl.OpStartsWith,
+	"ends_with":   dsl.OpEndsWith,
+	"ilike":       dsl.OpILike,
+	"equal_fold":  dsl.OpEqualFold,
+	"regex":       dsl.OpRegex,
+}
+
+// TargetedQuery maps a field of the Query type onto the search it resolves:
+// arguments become filters, sorts and pagination, selections of the data
+// become the select, the includes and the aggregates.
+// It only needs a validated field, e.g. the one of a gqlgen field context.
+func (r *Resolver) TargetedQuery(field *ast.Field, vars map[string]any) (*search.TargetedQuery, error) {
+	name, ok := r.roots[field.Name]
+	if !ok {
+		return nil, &search.ValidationError{
+			Rule: "GraphQLUnknownRoot",
+			Err:  fmt.Errorf(ErrUnknownRoot, field.Name),
+		}
+	}
+	node := r.graph[name]
+	q := &search.TargetedQuery{From: name}
+	args := field.ArgumentMap(vars)
+
+	var err error
+	if q.Filters, err = filterArg(node, args["filter"]); err != nil {
+		return nil, err
+	}
+	q.Sorts = sortArg(args["sort"])
+	q.Limit.Limit, _ = toInt(args["limit"])
+	q.Page, _ = toInt(args["page"])
+	q.After, _ = args["after"].(string)
+	q.Before, _ = args["before"].(string)
+
+	for _, f := range collectFields(field.SelectionSet, vars) {
+		switch f.Name {
+		case "data":
+			if q.Select, q.Includes, q.Aggregates, err = selection(node, f.SelectionSet, vars); err != nil {
+				return nil, err
+			}
+		case "paginate":
+			q.WithPagination = true
+		case "cursor":
+			q.WithCursor = true
+		}
+	}
+	return q, nil
+}
+
+// selection maps the selected fields of a node type.
+func selection(node entx.Node, set ast.SelectionSet, vars map[string]any) (dsl.Select, dsl.Includes, dsl.Aggregates, error) {
+	var (
+		sel  dsl.Select
+		incs dsl.Includes
+		aggs dsl.Aggregates
+	)
+	for _, f := range collectFields(set, vars) {
+		switch {
+		case f.Name == "__typename":
+		case node.FieldByName(f.Name) != nil:
+			if !slices.Contains(sel, f.Name) {
+				sel = append(sel, f.Name)
+			}
+		case node.Bridge(f.Name) != nil:
+			if slices.ContainsFunc(incs, func(inc *dsl.Include) bool { return inc.Relation == f.Name }) {
+				return nil, nil, nil, &search.ValidationError{
+					Rule: "GraphQLRelationSelected",
+					Err:  fmt.Errorf(ErrRelationSelected, f.Name, node.Name()),
+				}
+			}
+			inc, err := include(node.Bridge(f.Name).Child(), f, vars)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			incs = append(incs, inc)
+		case f.Name == aggregateField:
+			aggs = append(aggs, aggregate(f, vars))
+		}
+	}
+	return sel, incs, aggs, nil
+}
+
+func include(child entx.Node, f *ast.Field, vars map[string]any) (*dsl.Include, error) {
+	inc := &dsl.Include{Relation: f.Name}
+	args := f.ArgumentMap(vars)
+
+	var err error
+	if inc.Filters, err = filterArg(child, args["filter"]); err != nil {
+		return nil, err
+	}
+	inc.Sort = sortArg(args["sort"])
+	inc.Limit.Limit, _ = toInt(args["limit"])
+	inc.Offset, _ = toInt(args["offset"])
+
+	if inc.Select, inc.Includes, inc.Aggregates, err = selection(child, f.SelectionSet, vars); err != nil {
+		return nil, err
+	}
+	return inc, nil
+}
+
+// aggregate maps an aggregate field, its response key being the alias of the aggregate.
+func aggregate(f *ast.Field, vars map[string]any) *dsl.Aggregate {
+	args := f.ArgumentMap(vars)
+	a := &dsl.Aggregate{BaseAggregate: dsl.BaseAggregate{Alias: f.Alias}}
+	a.Type = dsl.Agg(fmt.Sprint(args["type"]))
+	a.Field, _ = args["field"].(string)
+	a.Percentile, _ = toFloat(args["percentile"])
+	a.Separator, _ = args["separator"].(string)
+	if order, ok := args["order"].(string); ok {
+		a.Order = dsl.Direction(order)
+	}
+	return a
+}
+
+func sortArg(v any) dsl.Sorts {
+	list, _ := v.([]any)
+	if len(list) == 0 {
+		return nil
+	}
+	sorts := make(dsl.Sorts, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		s := &dsl.Sort{}
+		s.Field, _ = m["field"].(string)
+		if dir, ok := m["direction"].(string); ok {
+			s.Direction = dsl.Direction(dir)
+		}
+		sorts = append(sorts, s)
+	}
+	return sorts
+}
+
+func filterArg(node entx.Node, v any) (dsl.Filters, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	f, err := filter(node, m)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	return dsl.Filters{f}, nil
+}
+
+// filter maps a filter input of a node type, its keys being combined with AND.
+// It returns nil when the input holds no condition.
+func filter(node entx.Node, in map[string]any) (*dsl.Filter, error) {
+	keys := make([]string, 0, len(in))
+	for k, v := range in {
+		if v != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var conds dsl.Filters
+	for _, key := range keys {
+		v := in[key]
+		switch key {
+		case "and", "or":
+			list, _ := v.([]any)
+			subs := make(dsl.Filters, 0, len(list))
+			for _, item := range list {
+				sub, err := filter(node, asMap(item))
+				if err != nil {
+					return nil, err
+				}
+				if sub != nil {
+					subs = append(subs, sub)
+				}
+			}
+			if len(subs) == 0 {
+				continue
+			}
+			if key == "and" {
+				conds = append(conds, &dsl.Filter{And: subs})
+			} else {
+				conds = append(conds, &dsl.Filter{Or: subs})
+			}
+			continue
+		case "not":
+			sub, err := filter(node, asMap(v))
+			if err != nil {
+				return nil, err
+			}
+			if sub != nil {
+				conds = append(conds, &dsl.Filter{Not: sub})
+			}
+			continue
+		}
+
+		if field := node.FieldByName(key); field != nil {
+			conds = append(conds, fieldConditions(field, asMap(v))...)
+			continue
+		}
+
+		relation, quantifier := key, dsl.QuantAny
+		switch {
+		case node.Bridge(key) != nil:
+		case strings.HasPrefix(key, "has_") && node.Bridge(key[4:]) != nil:
+			relation, quantifier = key[4:], dsl.QuantExists
+			if b, _ := v.(bool); !b {
+				quantifier = dsl.QuantNotExists
+			}
+			conds = append(conds, &dsl.Filter{Relation: relation, Quantifier: quantifier})
+			continue
+		case strings.HasSuffix(key, "_all") && node.Bridge(key[:len(key)-4]) != nil:
+			relation, quantifier = key[:len(key)-4], dsl.QuantAll
+		case strings.HasSuffix(key, "_none") && node.Bridge(key[:len(key)-5]) != nil:
+			relation, quantifier = key[:len(key)-5], dsl.QuantNone
+		default:
+			return nil, &search.ValidationError{
+				Rule: "GraphQLFilterKey",
+				Err:  fmt.Errorf("node %q has no field or relation matching filter key %q", node.Name(), key),
+			}
+		}
+		sub, err := filter(node.Bridge(relation).Child(), asMap(v))
+		if err != nil {
+			return nil, err
+		}
+		if sub == nil {
+			if quantifier == dsl.QuantAll {
+				continue
+			}
+			sub = &dsl.Filter{}
+		}
+		sub.Relation, sub.Quantifier = relation, quantifier
+		conds = append(conds, sub)
+	}
+
+	switch len(conds) {
+	case 0:
+		return nil, nil
+	case 1:
+		return conds[0], nil
+	default:
+		return &dsl.Filter{And: conds}, nil
+	}
+}
+
+// fieldConditions maps a scalar filter input, one condition per operator.
+func fieldConditions(field *entx.Field, in map[string]any) dsl.Filters {
+	ops := make([]string, 0, len(in))
+	for op, v := range in {
+		if v != nil {
+			ops = append(ops, op)
+		}
+	}
+	sort.Strings(ops)
+
+	conds := make(dsl.Filters, 0, len(ops))
+	for _, op := range ops {
+		v := in[op]
+		if op == "is_null" {
+			operator := dsl.OpIsNotNull
+			if b, _ := v.(bool); b {
+				operator = dsl.OpIsNull
+			}
+			conds = append(conds, &dsl.Filter{Field: field.Name, Operator: operator})
+			continue
+		}
+		conds = append(conds, &dsl.Filter{Field: field.Name, Operator: filterOps[op], Value: fieldValue(field, v)})
+	}
+	return conds
+}
+
+// fieldValue converts the numbers of variables, decoded as floats, to integers for integer fields.
+func fieldValue(field *entx.Field, v any) any {
+	if field.Type != entx.TypeInt {
+		return v
+	}
+	switch val := v.(type) {
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = fieldValue(field, item)
+		}
+		return out
+	default:
+		if i, ok := toInt(val); ok {
+			return int64(i)
+		}
+		return v
+	}
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n), true
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return int(i), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// collectFields flattens the fragments of a selection set, skipping the fields excluded
+// by @skip or @include and merging the fields sharing a response key.
+func collectFields(set ast.SelectionSet, vars map[string]any) []*ast.Field {
+	var (
+		fields []*ast.Field
+		index  = map[string]int{}
+	)
+	var walk func(ast.SelectionSet)
+	walk = func(set ast.SelectionSet) {
+		for _, s := range set {
+			switch s := s.(type) {
+			case *ast.Field:
+				if !included(s.Directives, vars) {
+					continue
+				}
+				if i, ok := index[s.Alias]; ok {
+					merged := *fields[i]
+					merged.SelectionSet = append(slices.Clip(merged.SelectionSet), s.SelectionSet...)
+					fields[i] = &merged
+					continue
+				}
+				index[s.Alias] = len(fields)
+				fields = append(fields, s)
+			case *ast.InlineFragment:
+				if included(s.Directives, vars) {
+					walk(s.SelectionSet)
+				}
+			case *ast.FragmentSpread:
+				if included(s.Directives, vars) && s.Definition != nil {
+					walk(s.Definition.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(set)
+	return fields
+}
+
+func included(directives ast.DirectiveList, vars map[string]any) bool {
+	if d := directives.ForName("skip"); d != nil {
+		if b, _ := d.ArgumentMap(vars)["if"].(bool); b {
+			return false
+		}
+	}
+	if d := directives.ForName("include"); d != nil {
+		if b, _ := d.ArgumentMap(vars)["if"].(bool); !b {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"entgo.io/ent/privacy"
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// MaxBodySize is the size limit of the requests served over HTTP, in bytes.
+const MaxBodySize int64 = 1 << 20
+
+// Codes of the errors, set in their "code" extension.
+const (
+	CodeValidation = "VALIDATION_FAILED"
+	CodeBuild      = "BUILD_FAILED"
+	CodeForbidden  = "FORBIDDEN"
+	CodeTimeout    = "TIMEOUT"
+	CodeExecution  = "EXECUTION_FAILED"
+	CodeInternal   = "INTERNAL"
+)
+
+var (
+	ErrUnknownRoot      = "query field %q does not search a node"
+	ErrIntrospection    = "introspection is not supported, use the generated schema"
+	ErrOperationType    = "only query operations are supported, got %s"
+	ErrRelationSelected = "relation %q of node %q is selected more than once"
+)
+
+// Resolver executes the GraphQL queries of a schema generated by the search extension,
+// each field of the Query type being executed as a search.TargetedQuery.
+type Resolver struct {
+	schema *ast.Schema
+	roots  map[string]string
+	client entx.Client
+	graph  entx.Graph
+	cfg    *search.Config
+}
+
+// NewResolver parses the SDL, roots mapping the fields of the Query type to the node they search.
+func NewResolver(sdl string, roots map[string]string, client entx.Client, graph entx.Graph, cfg *search.Config) (*Resolver, error) {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, err
+	}
+	for field, name := range roots {
+		if graph[name] == nil {
+			return nil, fmt.Errorf("root %q targets unknown node %q", field, name)
+		}
+	}
+	return &Resolver{
+		schema: schema,
+		roots:  roots,
+		client: client,
+		graph:  graph,
+		cfg:    cfg,
+	}, nil
+}
+
+// Schema returns the parsed schema.
+func (r *Resolver) Schema() *ast.Schema {
+	return r.schema
+}
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type Response struct {
+	Data   any           `json:"data"`
+	Errors gqlerror.List `json:"errors,omitempty"`
+}
+
+// Execute validates and executes the request, the root fields being resolved in order.
+// A failing root field is null in the data and reported in the errors.
+func (r *Resolver) Execute(ctx context.Context, req *Request) *Response {
+	doc, errs := gqlparser.LoadQuery(r.schema, req.Query)
+	if errs != nil {
+		return &Response{Errors: errs}
+	}
+
+	op := doc.Operations.ForName(req.OperationName)
+	if op == nil {
+		return &Response{Errors: gqlerror.List{gqlerror.Errorf("operation %q not found", req.OperationName)}}
+	}
+	if op.Operation != ast.Query {
+		return &Response{Errors: gqlerror.List{gqlerror.Errorf(ErrOperationType, op.Operation)}}
+	}
+
+	vars, err := validator.VariableValues(r.schema, op, req.Variables)
+	if err != nil {
+		return &Response{Errors: gqlerror.List{gqlerror.WrapIfUnwrapped(err)}}
+	}
+
+	var (
+		res  Response
+		data = make(object, 0, len(op.SelectionSet))
+	)
+	for _, f := range collectFields(op.SelectionSet, vars) {
+		if f.Name == "__typename" {
+			data = data.set(f.Alias, "Query")
+			continue
+		}
+		value, err := r.resolveRoot(ctx, f, vars)
+		if err != nil {
+			res.Errors = append(res.Errors, toGQLError(err, f))
+		}
+		data = data.set(f.Alias, value)
+	}
+	res.Data = data
+	return &res
+}
+
+func (r *Resolver) resolveRoot(ctx context.Context, f *ast.Field, vars map[string]any) (any, error) {
+	if f.Name == "__schema" || f.Name == "__type" {
+		return nil, gqlerror.Errorf("%s", ErrIntrospection)
+	}
+	q, err := r.TargetedQuery(f, vars)
+	if err != nil {
+		return nil, err
+	}
+	res, err := q.Execute(ctx, r.client, r.graph, r.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return r.shapeList(res, f, vars)
+}
+
+// ServeHTTP serves the GraphQL requests sent as a JSON body with POST,
+// or as query parameters with GET.
+func (r *Resolver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var gqlReq Request
+	switch req.Method {
+	case http.MethodGet:
+		q := req.URL.Query()
+		gqlReq.Query, gqlReq.OperationName = q.Get("query"), q.Get("operationName")
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &gqlReq.Variables); err != nil {
+				writeResponse(w, http.StatusBadRequest, &Response{Errors: gqlerror.List{gqlerror.Errorf("invalid variables: %v", err)}})
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, MaxBodySize)).Decode(&gqlReq); err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{Errors: gqlerror.List{gqlerror.Errorf("invalid request body: %v", err)}})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeResponse(w, http.StatusMethodNotAllowed, &Response{Errors: gqlerror.List{gqlerror.Errorf("method %s not allowed", req.Method)}})
+		return
+	}
+	writeResponse(w, http.StatusOK, r.Execute(req.Context(), &gqlReq))
+}
+
+func writeResponse(w http.ResponseWriter, status int, res *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// toGQLError maps an error of a root field, execution and unknown errors hide their message.
+func toGQLError(err error, f *ast.Field) *gqlerror.Error {
+	var (
+		gerr *gqlerror.Error
+		verr *search.ValidationError
+		berr *search.QueryBuildError
+		xerr *search.ExecError
+	)
+	e := &gqlerror.Error{
+		Err:        err,
+		Message:    err.Error(),
+		Path:       ast.Path{ast.PathName(f.Alias)},
+		Extensions: map[string]any{},
+	}
+	if f.Position != nil {
+		e.Locations = []gqlerror.Location{{Line: f.Position.Line, Column: f.Position.Column}}
+	}
+	switch {
+	case errors.As(err, &gerr):
+		e.Err, e.Message, e.Extensions = gerr.Err, gerr.Message, gerr.Extensions
+	case errors.As(err, &verr):
+		e.Message = verr.Err.Error()
+		e.Extensions["code"], e.Extensions["rule"] = CodeValidation, verr.Rule
+	case errors.Is(err, privacy.Deny):
+		e.Message = "forbidden"
+		e.Extensions["code"] = CodeForbidden
+	case errors.As(err, &berr):
+		e.Message = berr.Err.Error()
+		e.Extensions["code"], e.Extensions["op"] = CodeBuild, berr.Op
+	case errors.Is(err, context.DeadlineExceeded):
+		e.Message = "timeout exceeded"
+		e.Extensions["code"] = CodeTimeout
+	case errors.As(err, &xerr):
+		e.Message = "execution failed"
+		e.Extensions["code"], e.Extensions["op"] = CodeExecution, xerr.Op
+	default:
+		e.Message = "internal error"
+		e.Extensions["code"] = CodeInternal
+	}
+	return e
+}
@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// object is a response object keeping the order of the selected fields.
+type object []objectField
+
+type objectField struct {
+	key   string
+	value any
+}
+
+// set adds the value of a response key, replacing the previous one if any.
+func (o object) set(key string, value any) object {
+	for i := range o {
+		if o[i].key == key {
+			o[i].value = value
+			return o
+		}
+	}
+	return append(o, objectField{key, value})
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// shapeList shapes the response of a root field to its selection.
+func (r *Resolver) shapeList(res *search.SearchResponse, f *ast.Field, vars map[string]any) (any, error) {
+	node := r.graph[r.roots[f.Name]]
+
+	var entities []map[string]any
+	if res.Data != nil {
+		raw, err := json.Marshal(res.Data)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&entities); err != nil {
+			return nil, err
+		}
+	}
+
+	var meta search.MetaSearchResponse
+	if res.Meta != nil {
+		meta = *res.Meta
+	}
+
+	sel := collectFields(f.SelectionSet, vars)
+	out := make(object, 0, len(sel))
+	for _, sf := range sel {
+		switch sf.Name {
+		case "__typename":
+			out = out.set(sf.Alias, sf.ObjectDefinition.Name)
+		case "data":
+			data := make([]any, len(entities))
+			for i, e := range entities {
+				data[i] = shapeEntity(node, e, sf.SelectionSet, vars)
+			}
+			out = out.set(sf.Alias, data)
+		case "paginate":
+			out = out.set(sf.Alias, shapeValue(meta.Paginate, sf, vars))
+		case "cursor":
+			out = out.set(sf.Alias, shapeValue(meta.Cursor, sf, vars))
+		}
+	}
+	return out, nil
+}
+
+// shapeEntity shapes an entity decoded from its JSON form to the selection of its type.
+func shapeEntity(node entx.Node, e map[string]any, set ast.SelectionSet, vars map[string]any) object {
+	var (
+		sel   = collectFields(set, vars)
+		out   = make(object, 0, len(sel))
+		edges = asMap(e["edges"])
+	)
+	for _, f := range sel {
+		switch {
+		case f.Name == "__typename":
+			out = out.set(f.Alias, node.Name())
+		case node.FieldByName(f.Name) != nil:
+			out = out.set(f.Alias, scalar(e[f.Name], f.Definition.Type))
+		case node.Bridge(f.Name) != nil:
+			child := node.Bridge(f.Name).Child()
+			if f.Definition.Type.Elem == nil {
+				var value any
+				if m, ok := edges[f.Name].(map[string]any); ok {
+					value = shapeEntity(child, m, f.SelectionSet, vars)
+				}
+				out = out.set(f.Alias, value)
+				continue
+			}
+			list, _ := edges[f.Name].([]any)
+			value := make([]any, len(list))
+			for i, item := range list {
+				value[i] = shapeEntity(child, asMap(item), f.SelectionSet, vars)
+			}
+			out = out.set(f.Alias, value)
+		case f.Name == aggregateField:
+			out = out.set(f.Alias, asMap(asMap(e["meta"])["aggregates"])[f.Alias])
+		}
+	}
+	return out
+}
+
+// shapeValue shapes a metadata of the response, nil when it is not computed.
+func shapeValue(v any, f *ast.Field, vars map[string]any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil || m == nil {
+		return nil
+	}
+	sel := collectFields(f.SelectionSet, vars)
+	out := make(object, 0, len(sel))
+	for _, sf := range sel {
+		if sf.Name == "__typename" {
+			out = out.set(sf.Alias, sf.ObjectDefinition.Name)
+			continue
+		}
+		out = out.set(sf.Alias, scalar(m[sf.Name], sf.Definition.Type))
+	}
+	return out
+}
+
+// scalar returns the value of a scalar field, entities leaving their zero values out of their JSON form.
+func scalar(v any, t *ast.Type) any {
+	if v != nil || !t.NonNull {
+		return v
+	}
+	switch t.Name() {
+	case "Int", "Float":
+		return 0
+	case "String":
+		return ""
+	case "Boolean":
+		return false
+	default:
+		return nil
+	}
+}
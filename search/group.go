@@ -11,6 +11,8 @@ import (
 type QueryGroup struct {
 	Searches   NamedQueries          `json:"searches,omitempty"`
 	Aggregates dsl.OverallAggregates `json:"aggregates,omitempty"`
+	Grouped    dsl.GroupedAggregates `json:"grouped_aggregates,omitempty"`
+	Histograms dsl.DateHistograms    `json:"histograms,omitempty"`
 }
 
 func (group *QueryGroup) Execute(
@@ -22,21 +24,31 @@ func (group *QueryGroup) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	if err := group.ValidateAndPreprocessFinal(cfg); err != nil {
+	cost, err := validateAndCost(ctx, cfg,
+		func() error { return group.ValidateAndPreprocessFinal(cfg) },
+		func() error { return group.CheckTypes(graph) },
+		func(w *CostWeights) int { return group.Cost(graph, w) },
+		group.lowerIncludeLimits,
+	)
+	if err != nil {
 		return nil, err
 	}
 
-	build, err := group.BuildClassified(ctx, cfg, graph)
+	build, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (*ClassifiedBuilds, error) {
+		return group.BuildClassified(ctx, cfg, graph)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := build.Execute(ctx, client, cfg)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*GroupResponse, error) {
+		return build.Execute(ctx, client, cfg)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	return withGroupCost(res, cost), nil
 }
 
 func (r *QueryGroup) BuildClassified(ctx context.Context, cfg *Config, graph entx.Graph) (build *ClassifiedBuilds, err error) {
@@ -46,12 +58,28 @@ func (r *QueryGroup) BuildClassified(ctx context.Context, cfg *Config, graph ent
 	if build.Aggregates, err = r.Aggregates.BuildScalars(ctx, graph, cfg.Dialect); err != nil {
 		return
 	}
+	if build.Buckets, err = r.buildBuckets(ctx, graph, cfg.Dialect); err != nil {
+		return
+	}
 	return
 }
 
+func (r *QueryGroup) buildBuckets(ctx context.Context, graph entx.Graph, dialect string) ([]*common.BucketQuery, error) {
+	grouped, err := r.Grouped.BuildBuckets(ctx, graph, dialect)
+	if err != nil {
+		return nil, err
+	}
+	histograms, err := r.Histograms.BuildBuckets(ctx, graph, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return append(grouped, histograms...), nil
+}
+
 type QueryGroupBuild struct {
 	Searches   []*NamedQueryBuild
 	Aggregates []*common.ScalarQuery
+	Buckets    []*common.BucketQuery
 }
 
 func (build *QueryGroupBuild) CountPaginations() (count int) {
@@ -74,15 +102,15 @@ func (r *QueryGroup) Build(ctx context.Context, cfg *Config, graph entx.Graph) (
 	if build.Aggregates, err = r.Aggregates.BuildScalars(ctx, graph, cfg.Dialect); err != nil {
 		return
 	}
+	if build.Buckets, err = r.buildBuckets(ctx, graph, cfg.Dialect); err != nil {
+		return
+	}
 	return
 }
 
 func (sr *QueryGroup) ValidateAndPreprocessFinal(cfg *Config) (err error) {
 	var countSearches, countAggregates int
-	if countAggregates, err = sr.Aggregates.ValidateAndPreprocess(cfg); err != nil {
-		return
-	}
-	if countSearches, err = sr.Searches.ValidateAndPreprocess(cfg); err != nil {
+	if countSearches, countAggregates, err = sr.ValidateAndPreprocess(cfg); err != nil {
 		return
 	}
 	if err = common.CheckMaxAggregates(cfg, countAggregates); err != nil {
@@ -95,9 +123,17 @@ func (sr *QueryGroup) ValidateAndPreprocessFinal(cfg *Config) (err error) {
 }
 
 func (sr *QueryGroup) ValidateAndPreprocess(cfg *Config) (countSearches, countAggregates int, err error) {
+	var countGrouped, countHistograms int
 	if countAggregates, err = sr.Aggregates.ValidateAndPreprocess(cfg); err != nil {
 		return
 	}
+	if countGrouped, err = sr.Grouped.ValidateAndPreprocess(cfg); err != nil {
+		return
+	}
+	if countHistograms, err = sr.Histograms.ValidateAndPreprocess(cfg); err != nil {
+		return
+	}
+	countAggregates += countGrouped + countHistograms
 	if countSearches, err = sr.Searches.ValidateAndPreprocess(cfg); err != nil {
 		return
 	}
@@ -0,0 +1,92 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"entgo.io/ent/privacy"
+	"github.com/brice-74/entx/search"
+)
+
+// Types of the errors returned in the response body.
+const (
+	TypeRouting    = "routing"
+	TypeDecode     = "decode"
+	TypeAuth       = "auth"
+	TypeValidation = "validation"
+	TypeBuild      = "build"
+	TypePolicy     = "policy"
+	TypeTimeout    = "timeout"
+	TypeCanceled   = "canceled"
+	TypeExecution  = "execution"
+	TypeInternal   = "internal"
+)
+
+// Error is the JSON error body of the handler, returned under the "error" key.
+// Hooks can return an *Error to control the response status and body.
+type Error struct {
+	Status  int    `json:"status"`
+	Type    string `json:"type"`
+	Rule    string `json:"rule,omitempty"`
+	Op      string `json:"op,omitempty"`
+	Message string `json:"message"`
+	// Err is the cause of the error, never sent to the client.
+	Err error `json:"-"`
+}
+
+func (e *Error) Error() string { return e.Message }
+func (e *Error) Unwrap() error { return e.Err }
+
+// StatusClientClosedRequest is the non-standard status of a request canceled by the client.
+const StatusClientClosedRequest = 499
+
+type errorBody struct {
+	Error *Error `json:"error"`
+}
+
+// errorFrom maps an execution error to its response.
+// Execution and unknown errors hide their message, it may expose the database.
+func errorFrom(err error) *Error {
+	var (
+		herr *Error
+		verr *search.ValidationError
+		berr *search.QueryBuildError
+		xerr *search.ExecError
+	)
+	switch {
+	case errors.As(err, &herr):
+		return herr
+	case errors.As(err, &verr):
+		return &Error{Status: http.StatusBadRequest, Type: TypeValidation, Rule: verr.Rule, Message: message(verr.Err, err), Err: err}
+	case errors.Is(err, privacy.Deny):
+		return &Error{Status: http.StatusForbidden, Type: TypePolicy, Message: http.StatusText(http.StatusForbidden), Err: err}
+	case errors.As(err, &berr):
+		return &Error{Status: http.StatusUnprocessableEntity, Type: TypeBuild, Op: berr.Op, Message: message(berr.Err, err), Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Status: http.StatusGatewayTimeout, Type: TypeTimeout, Message: http.StatusText(http.StatusGatewayTimeout), Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Status: StatusClientClosedRequest, Type: TypeCanceled, Message: "client closed request", Err: err}
+	case errors.As(err, &xerr):
+		return &Error{Status: http.StatusInternalServerError, Type: TypeExecution, Op: xerr.Op, Message: http.StatusText(http.StatusInternalServerError), Err: err}
+	default:
+		return &Error{Status: http.StatusInternalServerError, Type: TypeInternal, Message: http.StatusText(http.StatusInternalServerError), Err: err}
+	}
+}
+
+// message returns the message of the cause, or of err when the cause is missing.
+func message(cause, err error) string {
+	if cause == nil {
+		return err.Error()
+	}
+	return cause.Error()
+}
+
+// asError maps a hook error, using status unless the hook returned an *Error.
+func asError(err error, status int, typ string) *Error {
+	var herr *Error
+	if errors.As(err, &herr) {
+		return herr
+	}
+	return &Error{Status: status, Type: typ, Message: err.Error(), Err: err}
+}
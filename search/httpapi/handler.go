@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search"
+)
+
+// Paths of the endpoints, each one accepting a POST request whose JSON body is the input type.
+const (
+	PathSearch      = "/search"      // search.TargetedQuery
+	PathSearches    = "/searches"    // search.NamedQueries
+	PathGroup       = "/group"       // search.QueryGroup
+	PathBundle      = "/bundle"      // search.QueryBundle
+	PathTransaction = "/transaction" // search.TxQueryGroup
+	PathAggregates  = "/aggregates"  // search.OverallAggregates
+	PathGrouped     = "/grouped"     // search.GroupedAggregates
+	PathHistograms  = "/histograms"  // search.DateHistograms
+)
+
+// Handler exposes the search inputs over HTTP.
+// Mount it under a prefix with http.StripPrefix.
+type Handler struct {
+	client    entx.Client
+	graph     entx.Graph
+	cfg       *search.Config
+	opts      options
+	endpoints map[string]http.HandlerFunc
+}
+
+// New returns a Handler executing the inputs with client on graph, following cfg.
+func New(client entx.Client, graph entx.Graph, cfg *search.Config, opts ...Option) *Handler {
+	h := &Handler{
+		client: client,
+		graph:  graph,
+		cfg:    cfg,
+		opts:   defaultOptions(),
+	}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	h.endpoints = map[string]http.HandlerFunc{
+		PathSearch:      endpoint[search.TargetedQuery, *search.SearchResponse](h),
+		PathSearches:    endpoint[search.NamedQueries, search.SearchesResponse](h),
+		PathGroup:       endpoint[search.QueryGroup, *search.GroupResponse](h),
+		PathBundle:      endpoint[search.QueryBundle, *search.GroupResponse](h),
+		PathTransaction: endpoint[search.TxQueryGroup, *search.GroupResponse](h),
+		PathAggregates:  endpoint[search.OverallAggregates, search.AggregatesResponse](h),
+		PathGrouped:     endpoint[search.GroupedAggregates, search.AggregatesResponse](h),
+		PathHistograms:  endpoint[search.DateHistograms, search.AggregatesResponse](h),
+	}
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serve, ok := h.endpoints[r.URL.Path]
+	if !ok {
+		h.writeError(w, r, &Error{Status: http.StatusNotFound, Type: TypeRouting, Message: "unknown endpoint " + r.URL.Path})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.writeError(w, r, &Error{Status: http.StatusMethodNotAllowed, Type: TypeRouting, Message: "method " + r.Method + " not allowed"})
+		return
+	}
+	serve(w, r)
+}
+
+type executable[Res any] interface {
+	Execute(context.Context, entx.Client, entx.Graph, *search.Config) (Res, error)
+}
+
+// endpoint decodes the body into a new In and executes it.
+func endpoint[In any, Res any, P interface {
+	*In
+	executable[Res]
+}](h *Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.opts.authenticate != nil {
+			if err := h.opts.authenticate(r); err != nil {
+				h.writeError(w, r, asError(err, http.StatusUnauthorized, TypeAuth))
+				return
+			}
+		}
+
+		ctx := r.Context()
+		if h.opts.context != nil {
+			var err error
+			if ctx, err = h.opts.context(ctx, r); err != nil {
+				h.writeError(w, r, asError(err, http.StatusForbidden, TypeAuth))
+				return
+			}
+		}
+
+		var in In
+		if err := h.decode(w, r, &in); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		res, err := P(&in).Execute(ctx, h.client, h.graph, h.cfg)
+		if err != nil {
+			h.writeError(w, r, errorFrom(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request, dest any) *Error {
+	if h.opts.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.opts.maxBodySize)
+	}
+	dec := json.NewDecoder(r.Body)
+	if h.opts.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(dest); err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxErr):
+			return &Error{Status: http.StatusRequestEntityTooLarge, Type: TypeDecode, Message: err.Error()}
+		case errors.Is(err, io.EOF):
+			return &Error{Status: http.StatusBadRequest, Type: TypeDecode, Message: "empty request body"}
+		default:
+			return &Error{Status: http.StatusBadRequest, Type: TypeDecode, Message: err.Error()}
+		}
+	}
+	if dec.More() {
+		return &Error{Status: http.StatusBadRequest, Type: TypeDecode, Message: "request body must contain a single JSON value"}
+	}
+	return nil
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, e *Error) {
+	if h.opts.onError != nil {
+		h.opts.onError(r, e)
+	}
+	writeJSON(w, e.Status, errorBody{Error: e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/jsonschema"
+)
+
+// endpointDocs documents the endpoints, by input and response schema.
+var endpointDocs = []struct {
+	path, operation, input, response, summary string
+}{
+	{PathSearch, "search", "TargetedQuery", "SearchResponse", "Searches the entities of a node."},
+	{PathSearches, "searches", "NamedQueries", "SearchesResponse", "Executes searches keyed by name."},
+	{PathGroup, "group", "QueryGroup", "GroupResponse", "Executes searches and aggregates in parallel."},
+	{PathBundle, "bundle", "QueryBundle", "GroupResponse", "Executes transactions and aggregate groups."},
+	{PathTransaction, "transaction", "TxQueryGroup", "GroupResponse", "Executes searches and aggregates in a transaction."},
+	{PathAggregates, "aggregates", "OverallAggregates", "AggregatesResponse", "Computes aggregates over whole nodes."},
+	{PathGrouped, "grouped", "GroupedAggregates", "AggregatesResponse", "Computes aggregates by bucket."},
+	{PathHistograms, "histograms", "DateHistograms", "AggregatesResponse", "Computes aggregates by time interval."},
+}
+
+// OpenAPI returns the OpenAPI document of the endpoints of a Handler serving graph.
+// Its schemas enumerate the fields and relations of every node, so that it can be
+// served alongside the handler, paths being relative to its mount point.
+func OpenAPI(graph entx.Graph, info jsonschema.Info) *jsonschema.OpenAPI {
+	doc := jsonschema.NewOpenAPI(graph, info)
+	ref := func(name string) *jsonschema.Schema {
+		return &jsonschema.Schema{Ref: jsonschema.ComponentsPrefix + name}
+	}
+
+	schemas := doc.Components.Schemas
+	schemas["SearchResponse"] = &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"data": {Type: "array", Items: &jsonschema.Schema{Type: "object"}},
+			"meta": {Type: "object"},
+		},
+	}
+	schemas["SearchesResponse"] = &jsonschema.Schema{Type: "object", Description: "search responses keyed by the key of their search"}
+	schemas["GroupResponse"] = &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"searches": ref("SearchesResponse"),
+			"meta":     {Type: "object"},
+		},
+	}
+	schemas["AggregatesResponse"] = &jsonschema.Schema{Type: "object", Description: "aggregate results keyed by alias"}
+	schemas["Error"] = &jsonschema.Schema{
+		Type:     "object",
+		Required: []string{"error"},
+		Properties: map[string]*jsonschema.Schema{
+			"error": {
+				Type:     "object",
+				Required: []string{"status", "type", "message"},
+				Properties: map[string]*jsonschema.Schema{
+					"status":  {Type: "integer"},
+					"type":    {Type: "string"},
+					"rule":    {Type: "string"},
+					"op":      {Type: "string"},
+					"message": {Type: "string"},
+				},
+			},
+		},
+	}
+
+	for _, e := range endpointDocs {
+		doc.Paths[e.path] = &jsonschema.PathItem{Post: &jsonschema.Operation{
+			OperationID: e.operation,
+			Summary:     e.summary,
+			RequestBody: &jsonschema.RequestBody{Required: true, Content: jsonschema.JSONContent(ref(e.input))},
+			Responses: map[string]*jsonschema.Response{
+				strconv.Itoa(http.StatusOK): {Description: "OK", Content: jsonschema.JSONContent(ref(e.response))},
+				"default":                   {Description: "Error", Content: jsonschema.JSONContent(ref("Error"))},
+			},
+		}}
+	}
+	return doc
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultMaxBodySize is the default size limit of a request body, in bytes.
+const DefaultMaxBodySize int64 = 1 << 20
+
+type Option func(*options)
+
+type options struct {
+	maxBodySize           int64
+	disallowUnknownFields bool
+	authenticate          func(*http.Request) error
+	context               func(context.Context, *http.Request) (context.Context, error)
+	onError               func(*http.Request, *Error)
+}
+
+func defaultOptions() options {
+	return options{
+		maxBodySize:           DefaultMaxBodySize,
+		disallowUnknownFields: true,
+	}
+}
+
+// WithMaxBodySize limits the size of request bodies, 0 disables the limit.
+func WithMaxBodySize(size int64) Option {
+	return func(o *options) {
+		o.maxBodySize = size
+	}
+}
+
+// WithUnknownFields accepts bodies containing fields unknown to the input type,
+// rejected by default.
+func WithUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknownFields = false
+	}
+}
+
+// WithAuthenticator is called before the body is decoded,
+// an error rejects the request with a 401 status unless it is an *Error.
+func WithAuthenticator(fn func(*http.Request) error) Option {
+	return func(o *options) {
+		o.authenticate = fn
+	}
+}
+
+// WithContext derives the context of the execution from the request,
+// typically to inject the viewer read by the query policies.
+// An error rejects the request with a 403 status unless it is an *Error.
+func WithContext(fn func(context.Context, *http.Request) (context.Context, error)) Option {
+	return func(o *options) {
+		o.context = fn
+	}
+}
+
+// WithErrorHook is called with every error before it is written, e.g. to log its cause.
+func WithErrorHook(fn func(*http.Request, *Error)) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
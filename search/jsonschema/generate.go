@@ -0,0 +1,459 @@
+package jsonschema
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/brice-74/entx"
+	"github.com/brice-74/entx/search/dsl"
+)
+
+// DefsPrefix is the reference prefix of the definitions of a generated document.
+const DefsPrefix = "#/$defs/"
+
+// chainPattern matches dotted chains of relations, optionally ending with a field.
+const chainPattern = `^[^.]+(\.[^.]+)+$`
+
+var (
+	operators = []dsl.Operator{
+		dsl.OpEqual, dsl.OpNotEqual,
+		dsl.OpGreaterThan, dsl.OpGreaterEqual, dsl.OpLessThan, dsl.OpLessEqual,
+		dsl.OpLike, dsl.OpNotLike,
+		dsl.OpIn, dsl.OpNotIn,
+		dsl.OpBetween, dsl.OpNotBetween,
+		dsl.OpIsNull, dsl.OpIsNotNull,
+		dsl.OpStartsWith, dsl.OpEndsWith, dsl.OpILike, dsl.OpEqualFold, dsl.OpRegex,
+	}
+	countOperators = []dsl.Operator{
+		dsl.OpEqual, dsl.OpNotEqual,
+		dsl.OpGreaterThan, dsl.OpGreaterEqual, dsl.OpLessThan, dsl.OpLessEqual,
+		dsl.OpBetween, dsl.OpNotBetween,
+	}
+	quantifiers = []dsl.Quantifier{
+		dsl.QuantAny, dsl.QuantNone, dsl.QuantAll, dsl.QuantExists, dsl.QuantNotExists,
+	}
+	aggregateTypes = []dsl.Agg{
+		dsl.AggAvg, dsl.AggSum, dsl.AggMin, dsl.AggMax,
+		dsl.AggCount, dsl.AggCountDistinct,
+		dsl.AggStddev, dsl.AggVariance, dsl.AggMedian, dsl.AggPercentile,
+		dsl.AggBoolAnd, dsl.AggBoolOr,
+		dsl.AggStringAgg, dsl.AggGroupConcat,
+	}
+	intervals = []dsl.Interval{
+		dsl.IntervalMinute, dsl.IntervalHour, dsl.IntervalDay,
+		dsl.IntervalWeek, dsl.IntervalMonth, dsl.IntervalYear,
+	}
+)
+
+// Generate returns a JSON Schema document whose $defs describe the search inputs of the graph.
+// An input is validated against the definition of its type, e.g. "#/$defs/TargetedQuery"
+// or "#/$defs/QueryBundle". Nodes are described by "<Node>.<Input>" definitions,
+// e.g. "#/$defs/User.Filter".
+func Generate(graph entx.Graph) *Schema {
+	return &Schema{
+		Schema: Draft,
+		Title:  "entx search inputs",
+		Defs:   Definitions(graph, DefsPrefix),
+	}
+}
+
+// Definitions returns the definitions of the search inputs of the graph keyed by name,
+// their references being prefixed with prefix (e.g. "#/components/schemas/").
+//
+// Field and relation names are enumerated per node from the graph, which only holds
+// the fields and relations exposed by the search extension. Chains of relations
+// ("author.articles.title") are accepted as strings, the conditions of a relation
+// filter, include or aggregate being described for the related node when the chain
+// has a single relation.
+func Definitions(graph entx.Graph, prefix string) map[string]*Schema {
+	g := &generator{
+		graph:  graph,
+		names:  slices.Sorted(maps.Keys(graph)),
+		prefix: prefix,
+		defs:   map[string]*Schema{},
+	}
+	g.common()
+	for _, name := range g.names {
+		g.node(graph[name])
+	}
+	g.inputs()
+	return g.defs
+}
+
+type generator struct {
+	graph  entx.Graph
+	names  []string
+	prefix string
+	defs   map[string]*Schema
+}
+
+func (g *generator) ref(name string) *Schema {
+	return &Schema{Ref: g.prefix + name}
+}
+
+func (g *generator) arrayOf(name string) *Schema {
+	return array(g.ref(name))
+}
+
+func array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func object(props map[string]*Schema, required ...string) *Schema {
+	return &Schema{
+		Type:                 "object",
+		Properties:           props,
+		Required:             required,
+		AdditionalProperties: ptr(false),
+	}
+}
+
+func enum[T ~string](values ...T) *Schema {
+	s := &Schema{Type: "string", Enum: make([]any, len(values))}
+	for i, v := range values {
+		s.Enum[i] = string(v)
+	}
+	return s
+}
+
+func str() *Schema     { return &Schema{Type: "string"} }
+func boolean() *Schema { return &Schema{Type: "boolean"} }
+
+func integer(min float64) *Schema {
+	return &Schema{Type: "integer", Minimum: ptr(min)}
+}
+
+// chainOr accepts the enumerated names or a chain of relations.
+func chainOr(names *Schema) *Schema {
+	return &Schema{AnyOf: []*Schema{names, {Type: "string", Pattern: chainPattern}}}
+}
+
+// common describes the values shared by every node.
+func (g *generator) common() {
+	g.defs["Operator"] = enum(operators...)
+	g.defs["Quantifier"] = enum(quantifiers...)
+	g.defs["Direction"] = enum(dsl.DirASC, dsl.DirDESC)
+	g.defs["AggregateType"] = enum(aggregateTypes...)
+	g.defs["Interval"] = enum(intervals...)
+	g.defs["IsolationLevel"] = &Schema{
+		Type:        "integer",
+		Description: "database/sql isolation level, from LevelDefault (0) to LevelLinearizable (7)",
+		Minimum:     ptr(0.0),
+		Maximum:     ptr(7.0),
+	}
+	g.defs["RelationCount"] = object(map[string]*Schema{
+		"operator": enum(countOperators...),
+		// the bounds of a between operator, a single integer otherwise
+		"value": {OneOf: []*Schema{
+			integer(0),
+			{Type: "array", Items: integer(0), MinItems: ptr(2), MaxItems: ptr(2)},
+		}},
+	}, "operator", "value")
+	g.defs["Recursion"] = object(map[string]*Schema{
+		"max_depth": integer(1),
+	})
+	g.defs["Having"] = object(map[string]*Schema{
+		"aggregate": str(),
+		"operator":  g.ref("Operator"),
+		"value":     {},
+	}, "aggregate", "operator")
+	g.defs["BucketSort"] = object(map[string]*Schema{
+		"field":     str(),
+		"direction": g.ref("Direction"),
+	}, "field")
+}
+
+// aggregateProps adds the properties shared by the aggregates.
+func (g *generator) aggregateProps(props map[string]*Schema) map[string]*Schema {
+	props["alias"] = str()
+	props["type"] = g.ref("AggregateType")
+	props["distinct"] = boolean()
+	return g.aggParams(props)
+}
+
+// aggParams adds the parameters of the aggregate functions.
+func (g *generator) aggParams(props map[string]*Schema) map[string]*Schema {
+	props["percentile"] = &Schema{Type: "number", ExclusiveMinimum: ptr(0.0), ExclusiveMaximum: ptr(1.0)}
+	props["separator"] = str()
+	props["order"] = g.ref("Direction")
+	return props
+}
+
+// node describes the inputs of a node, named "<Node>.<Input>".
+func (g *generator) node(n entx.Node) {
+	var (
+		name       = n.Name()
+		fields     = n.Fields()
+		bridges    = n.Bridges()
+		relations  = slices.Sorted(maps.Keys(bridges))
+		fieldNames = make([]string, len(fields))
+		timeFields []string
+	)
+	for i, f := range fields {
+		fieldNames[i] = f.Name
+		if f.Type == entx.TypeTime {
+			timeFields = append(timeFields, f.Name)
+		}
+	}
+
+	g.defs[name+".Field"] = enum(fieldNames...)
+	g.defs[name+".FieldPath"] = chainOr(g.ref(name + ".Field"))
+	if len(relations) > 0 {
+		g.defs[name+".Relation"] = enum(relations...)
+	}
+
+	// filter, its conditions apply to the related node when a relation is set
+	filterProps := map[string]*Schema{
+		"field":    str(),
+		"operator": g.ref("Operator"),
+		"value":    {},
+		"not":      {Type: "object"},
+		"and":      {Type: "array"},
+		"or":       {Type: "array"},
+	}
+	filter := object(filterProps)
+	filter.AllOf = append(filter.AllOf, &Schema{
+		If:   &Schema{Not: &Schema{Required: []string{"relation"}}},
+		Then: g.conditions(name),
+	})
+	if len(relations) > 0 {
+		filterProps["relation"] = chainOr(g.ref(name + ".Relation"))
+		filterProps["quantifier"] = g.ref("Quantifier")
+		filterProps["count"] = g.ref("RelationCount")
+		filterProps["recursive"] = g.ref("Recursion")
+		for _, rel := range relations {
+			filter.AllOf = append(filter.AllOf, &Schema{
+				If:   whenConst("relation", rel),
+				Then: g.conditions(bridges[rel].Child().Name()),
+			})
+		}
+	}
+	g.defs[name+".Filter"] = filter
+
+	// a sort of the search also orders by the alias of one of its aggregates
+	g.defs[name+".Sort"] = object(g.aggParams(map[string]*Schema{
+		"field": {AnyOf: []*Schema{
+			g.ref(name + ".FieldPath"),
+			{Type: "string", Pattern: `^[^.]+$`, Description: "alias of an aggregate of the search"},
+		}},
+		"direction": g.ref("Direction"),
+		"aggregate": g.ref("AggregateType"),
+		"nulls":     enum(dsl.NullsFirst, dsl.NullsLast),
+		"filters":   array(&Schema{Type: "object"}),
+		"values": {Type: "array", MinItems: ptr(1), Items: &Schema{AnyOf: []*Schema{
+			str(), {Type: "number"}, boolean(),
+		}}},
+	}), "field")
+
+	// per-entity aggregate, on a field of the node or of related rows
+	aggFields := slices.Clone(fieldNames)
+	aggregate := object(g.aggregateProps(map[string]*Schema{
+		"filters":   array(&Schema{Type: "object"}),
+		"recursive": g.ref("Recursion"),
+	}), "field", "type")
+	aggregate.AllOf = append(aggregate.AllOf, &Schema{
+		If:   whenEnum("field", fieldNames),
+		Then: &Schema{Properties: map[string]*Schema{"filters": g.arrayOf(name + ".Filter")}},
+	})
+	for _, rel := range relations {
+		child := bridges[rel].Child()
+		paths := []string{rel}
+		for _, f := range child.Fields() {
+			paths = append(paths, rel+"."+f.Name)
+		}
+		aggFields = append(aggFields, paths...)
+		aggregate.AllOf = append(aggregate.AllOf, &Schema{
+			If:   whenEnum("field", paths),
+			Then: &Schema{Properties: map[string]*Schema{"filters": g.arrayOf(child.Name() + ".Filter")}},
+		})
+	}
+	aggregate.Properties["field"] = chainOr(enum(aggFields...))
+	g.defs[name+".Aggregate"] = aggregate
+
+	if len(relations) > 0 {
+		include := object(map[string]*Schema{
+			"relation":    chainOr(g.ref(name + ".Relation")),
+			"select":      array(str()),
+			"filters":     array(&Schema{Type: "object"}),
+			"includes":    array(&Schema{Type: "object"}),
+			"sort":        array(&Schema{Type: "object"}),
+			"aggregates":  array(&Schema{Type: "object"}),
+			"limit":       integer(0),
+			"offset":      integer(0),
+			"recursive":   g.ref("Recursion"),
+			"pivot":       array(str()),
+			"with_cursor": boolean(),
+			"after":       str(),
+		}, "relation")
+		for _, rel := range relations {
+			child := bridges[rel].Child()
+			props := g.options(child)
+			// the pivot fields of an M2M relation backed by an edge schema
+			props["pivot"] = &Schema{Type: "array", MaxItems: ptr(0)}
+			if pivot := bridges[rel].Pivot(); pivot != nil {
+				props["pivot"] = &Schema{Type: "array", Items: g.ref(pivot.Name() + ".Field"), UniqueItems: true}
+			}
+			include.AllOf = append(include.AllOf, &Schema{
+				If:   whenConst("relation", rel),
+				Then: &Schema{Properties: props},
+			})
+		}
+		g.defs[name+".Include"] = include
+	}
+
+	query := object(g.queryOptions(n, map[string]*Schema{"from": {Const: name}}), "from")
+	g.defs[name+".TargetedQuery"] = query
+	named := object(g.queryOptions(n, map[string]*Schema{"from": {Const: name}, "key": str()}), "from")
+	g.defs[name+".NamedQuery"] = named
+
+	overall := []string{name}
+	for _, f := range fieldNames {
+		overall = append(overall, name+"."+f)
+	}
+	g.defs[name+".GroupedAggregate"] = object(map[string]*Schema{
+		"from":     {Const: name},
+		"alias":    str(),
+		"group_by": {Type: "array", Items: g.ref(name + ".FieldPath"), MinItems: ptr(1)},
+		"aggregates": {Type: "array", MinItems: ptr(1), Items: object(g.aggregateProps(map[string]*Schema{
+			"field":   g.ref(name + ".FieldPath"),
+			"filters": g.arrayOf(name + ".Filter"),
+		}), "type")},
+		"filters": g.arrayOf(name + ".Filter"),
+		"having":  g.arrayOf("Having"),
+		"sorts":   g.arrayOf("BucketSort"),
+		"limit":   integer(0),
+	}, "from", "group_by", "aggregates")
+
+	if len(timeFields) > 0 {
+		g.defs[name+".DateHistogram"] = object(g.aggregateProps(map[string]*Schema{
+			"field":      enum(overall...),
+			"filters":    g.arrayOf(name + ".Filter"),
+			"on":         enum(timeFields...),
+			"interval":   g.ref("Interval"),
+			"timezone":   str(),
+			"fill_empty": boolean(),
+		}), "field", "type", "on", "interval")
+	}
+}
+
+// conditions types the conditions of a filter applying to the node.
+func (g *generator) conditions(name string) *Schema {
+	return &Schema{Properties: map[string]*Schema{
+		"field": g.ref(name + ".FieldPath"),
+		"not":   g.ref(name + ".Filter"),
+		"and":   g.arrayOf(name + ".Filter"),
+		"or":    g.arrayOf(name + ".Filter"),
+	}}
+}
+
+// options types the select, filters, sorts, includes and aggregates applying to the node.
+func (g *generator) options(n entx.Node) map[string]*Schema {
+	name := n.Name()
+	props := map[string]*Schema{
+		"select":     {Type: "array", Items: g.ref(name + ".Field"), UniqueItems: true},
+		"filters":    g.arrayOf(name + ".Filter"),
+		"sort":       g.arrayOf(name + ".Sort"),
+		"aggregates": g.arrayOf(name + ".Aggregate"),
+		"includes":   {Type: "array", MaxItems: ptr(0)},
+	}
+	if len(n.Bridges()) > 0 {
+		props["includes"] = g.arrayOf(name + ".Include")
+	}
+	return props
+}
+
+func (g *generator) queryOptions(n entx.Node, props map[string]*Schema) map[string]*Schema {
+	opts := g.options(n)
+	opts["sorts"] = opts["sort"]
+	delete(opts, "sort")
+	maps.Copy(opts, props)
+	maps.Copy(opts, map[string]*Schema{
+		"with_pagination":             boolean(),
+		"enable_transaction":          boolean(),
+		"transaction_isolation_level": g.ref("IsolationLevel"),
+		"page":                        integer(0),
+		"limit":                       integer(0),
+		"with_cursor":                 boolean(),
+		"after":                       str(),
+		"before":                      str(),
+	})
+	return opts
+}
+
+// inputs describes the inputs of the search module, choosing the node definitions on their target.
+func (g *generator) inputs() {
+	var (
+		queries, named, grouped, histograms []*Schema
+		overallFields                       []string
+		overall                             = object(g.aggregateProps(map[string]*Schema{
+			"filters": array(&Schema{Type: "object"}),
+		}), "field", "type")
+	)
+	for _, name := range g.names {
+		queries = append(queries, g.ref(name+".TargetedQuery"))
+		named = append(named, g.ref(name+".NamedQuery"))
+		grouped = append(grouped, g.ref(name+".GroupedAggregate"))
+		if g.defs[name+".DateHistogram"] != nil {
+			histograms = append(histograms, g.ref(name+".DateHistogram"))
+		}
+
+		paths := []string{name}
+		for _, f := range g.graph[name].Fields() {
+			paths = append(paths, name+"."+f.Name)
+		}
+		overallFields = append(overallFields, paths...)
+		overall.AllOf = append(overall.AllOf, &Schema{
+			If:   whenEnum("field", paths),
+			Then: &Schema{Properties: map[string]*Schema{"filters": g.arrayOf(name + ".Filter")}},
+		})
+	}
+	overall.Properties["field"] = enum(overallFields...)
+
+	g.defs["TargetedQuery"] = oneOf(queries)
+	g.defs["NamedQuery"] = oneOf(named)
+	g.defs["NamedQueries"] = g.arrayOf("NamedQuery")
+	g.defs["OverallAggregate"] = overall
+	g.defs["OverallAggregates"] = g.arrayOf("OverallAggregate")
+	g.defs["GroupedAggregate"] = oneOf(grouped)
+	g.defs["GroupedAggregates"] = g.arrayOf("GroupedAggregate")
+	g.defs["DateHistogram"] = oneOf(histograms)
+	g.defs["DateHistograms"] = g.arrayOf("DateHistogram")
+
+	group := map[string]*Schema{
+		"searches":           g.ref("NamedQueries"),
+		"aggregates":         g.ref("OverallAggregates"),
+		"grouped_aggregates": g.ref("GroupedAggregates"),
+		"histograms":         g.ref("DateHistograms"),
+	}
+	g.defs["QueryGroup"] = object(group)
+	tx := maps.Clone(group)
+	tx["transaction_isolation_level"] = g.ref("IsolationLevel")
+	g.defs["TxQueryGroup"] = object(tx)
+	// the query group of a bundle is embedded, its inputs are top-level properties
+	bundle := maps.Clone(group)
+	bundle["transactions"] = g.arrayOf("TxQueryGroup")
+	bundle["parallel_aggregates_groups"] = g.arrayOf("OverallAggregates")
+	g.defs["QueryBundle"] = object(bundle)
+}
+
+// oneOf matches one of the schemas, nothing when there is none.
+func oneOf(schemas []*Schema) *Schema {
+	if len(schemas) == 0 {
+		return &Schema{Not: &Schema{}}
+	}
+	return &Schema{OneOf: schemas}
+}
+
+func whenConst(prop, value string) *Schema {
+	return &Schema{
+		Required:   []string{prop},
+		Properties: map[string]*Schema{prop: {Const: value}},
+	}
+}
+
+func whenEnum(prop string, values []string) *Schema {
+	return &Schema{
+		Required:   []string{prop},
+		Properties: map[string]*Schema{prop: enum(values...)},
+	}
+}
@@ -0,0 +1,69 @@
+package jsonschema
+
+import "github.com/brice-74/entx"
+
+// OpenAPIVersion is the version of the generated OpenAPI documents,
+// the first one whose schemas are JSON Schema draft 2020-12.
+const OpenAPIVersion = "3.1.0"
+
+// ComponentsPrefix is the reference prefix of the schemas of an OpenAPI document.
+const ComponentsPrefix = "#/components/schemas/"
+
+// OpenAPI is the subset of an OpenAPI document used to describe the search endpoints.
+type OpenAPI struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem struct {
+	Post *Operation `json:"post,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// NewOpenAPI returns a document whose components describe the search inputs of the graph,
+// paths being added by the caller.
+func NewOpenAPI(graph entx.Graph, info Info) *OpenAPI {
+	return &OpenAPI{
+		OpenAPI:    OpenAPIVersion,
+		Info:       info,
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: Definitions(graph, ComponentsPrefix)},
+	}
+}
+
+// JSONContent returns the content of a JSON body validated by the schema.
+func JSONContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
@@ -18,16 +18,25 @@ func (queries NamedQueries) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	if err := queries.ValidateAndPreprocessFinal(cfg); err != nil {
+	if _, err := validateAndCost(ctx, cfg,
+		func() error { return queries.ValidateAndPreprocessFinal(cfg) },
+		func() error { return queries.CheckTypes(graph) },
+		func(w *CostWeights) int { return queries.Cost(graph, w) },
+		queries.lowerIncludeLimits,
+	); err != nil {
 		return nil, err
 	}
 
-	build, err := queries.BuildClassified(ctx, cfg, graph)
+	build, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (*ClassifiedBuilds, error) {
+		return queries.BuildClassified(ctx, cfg, graph)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := build.Execute(ctx, client, cfg)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*GroupResponse, error) {
+		return build.Execute(ctx, client, cfg)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +66,7 @@ func (queries NamedQueries) BuildClassified(
 }
 
 func (queries NamedQueries) Build(ctx context.Context, cfg *Config, graph entx.Graph) ([]*NamedQueryBuild, error) {
-	var builds = make([]*NamedQueryBuild, 0, len(queries))
+	var builds = make([]*NamedQueryBuild, len(queries))
 	for i, q := range queries {
 		build, err := q.Build(ctx, i, cfg, graph)
 		if err != nil {
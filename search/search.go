@@ -2,6 +2,7 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	stdsql "database/sql"
@@ -70,16 +71,31 @@ func (q *TargetedQuery) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	if err := q.ValidateAndPreprocess(cfg); err != nil {
+	cost, err := validateAndCost(ctx, cfg,
+		func() error { return q.ValidateAndPreprocess(cfg) },
+		func() error { return q.CheckTypes(graph) },
+		func(w *CostWeights) int { return q.Cost(graph, w) },
+		q.lowerIncludeLimits,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (*QueryOptionsBuild, error) {
+		return q.Build(ctx, cfg, graph)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	build, err := q.Build(ctx, cfg, graph)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*SearchResponse, error) {
+		return q.QueryOptions.execute(ctx, client, cfg, build)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return q.QueryOptions.execute(ctx, client, cfg, build)
+	return withSearchCost(res, cost), nil
 }
 
 func (q *TargetedQuery) Build(
@@ -121,16 +137,31 @@ func (qo *QueryOptions) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	if err := qo.ValidateAndPreprocess(cfg); err != nil {
+	cost, err := validateAndCost(ctx, cfg,
+		func() error { return qo.ValidateAndPreprocess(cfg) },
+		func() error { return qo.CheckTypes(node) },
+		func(w *CostWeights) int { return qo.Cost(node, w) },
+		qo.lowerIncludeLimits,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (*QueryOptionsBuild, error) {
+		return qo.Build(ctx, cfg, node)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	build, err := qo.Build(ctx, cfg, node)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*SearchResponse, error) {
+		return qo.execute(ctx, client, cfg, build)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return qo.execute(ctx, client, cfg, build)
+	return withSearchCost(res, cost), nil
 }
 
 func (qo *QueryOptions) execute(
@@ -152,8 +183,13 @@ func (qo *QueryOptions) execute(
 type QueryOptionsBuild struct {
 	ExecFn                    func(context.Context, entx.Client) (any, int, error)
 	Paginate                  *common.PaginateInfos
+	Cursor                    *common.CursorInfos
 	EnableTransaction         bool
 	TransactionIsolationLevel stdsql.IsolationLevel
+	// CacheEntry caches the search response, nil when the cache is disabled.
+	CacheEntry *common.CacheEntry
+	// QueryFn returns the search query executed by ExecFn, with its includes.
+	QueryFn func(entx.Client) entx.Query
 }
 
 // run asynchronously search query & count paginate inside 2 goroutines
@@ -202,7 +238,8 @@ func (build *QueryOptionsBuild) ExecutePaginatedWithTx(
 		panic("cannot call QueryOptionsBuild.ExecutePaginatedWithTx with nil pagination or without transaction")
 	}
 
-	return WithTx(ctx, client, &stdsql.TxOptions{
+	ctx, span := cfg.Telemetry.Start(ctx, common.SpanTransaction, common.AttrIsolationLevel.String(build.TransactionIsolationLevel.String()))
+	res, err := WithTx(ctx, client, &stdsql.TxOptions{
 		ReadOnly:  true,
 		Isolation: build.TransactionIsolationLevel,
 	}, func(ctx context.Context, client entx.Client) (*SearchResponse, error) {
@@ -219,6 +256,8 @@ func (build *QueryOptionsBuild) ExecutePaginatedWithTx(
 		response.Meta.Paginate = build.Paginate.Calculate(total, response.Meta.Count)
 		return response, nil
 	})
+	common.EndSpan(span, err)
+	return res, err
 }
 
 // execute search without pagination
@@ -227,12 +266,36 @@ func (build *QueryOptionsBuild) ExecuteSearchOnly(
 	client entx.Client,
 	cfg *Config,
 ) (*SearchResponse, error) {
-	data, count, err := build.ExecFn(ctx, client)
+	res, err := common.ExecuteCached(ctx, &cfg.Cache, build.CacheEntry, func() (*SearchResponse, error) {
+		data, count, err := build.ExecFn(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		return build.newSearchResponse(data, count)
+	})
+	if err != nil || build.CacheEntry == nil {
+		return res, err
+	}
+
+	// the cached response is shared, its meta is completed per request
+	meta := *res.Meta
+	return &SearchResponse{Data: res.Data, Meta: &meta}, nil
+}
+
+// newSearchResponse wraps the ExecFn result, trimming it and computing cursors when the cursor mode is used.
+func (build *QueryOptionsBuild) newSearchResponse(data any, count int) (*SearchResponse, error) {
+	res := &SearchResponse{Data: data, Meta: &MetaSearchResponse{Count: count}}
+	if build.Cursor == nil {
+		return res, nil
+	}
+
+	entities, cursor, err := build.Cursor.Calculate(data.([]entx.Entity))
 	if err != nil {
 		return nil, err
 	}
 
-	return &SearchResponse{Data: data, Meta: &MetaSearchResponse{Count: count}}, nil
+	res.Data, res.Meta.Count, res.Meta.Cursor = entities, len(entities), cursor
+	return res, nil
 }
 
 func (build *QueryOptionsBuild) ExecutePaginate(
@@ -244,7 +307,13 @@ func (build *QueryOptionsBuild) ExecutePaginate(
 		panic("cannot call QueryOptionsBuild.ExecutePaginate with nil pagination")
 	}
 
-	raw, err := common.ExecuteScalar(ctx, client, build.Paginate.ToScalarQuery(""))
+	q := build.Paginate.ToScalarQuery("")
+	raw, err := common.ExecuteCached(ctx, &cfg.Cache, cfg.Cache.ScalarEntry(ctx, q), func() (any, error) {
+		ctx, span := cfg.Telemetry.StartScalars(ctx, []*common.ScalarQuery{q})
+		raw, err := common.ExecuteScalar(ctx, client, q)
+		common.EndSpan(span, err)
+		return raw, err
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -315,13 +384,30 @@ func (qo *QueryOptions) Build(
 		preds = append(preds, ps...)
 	}
 
-	if ps, err := qo.Sorts.Predicate(node); err != nil {
-		return nil, err
-	} else if len(ps) > 0 {
-		preds = append(preds, ps...)
-	}
+	var cursor *common.CursorInfos
+	if qo.IsCursor() {
+		p, keys, err := qo.Cursor.Predicate(node, qo.Sorts)
+		if err != nil {
+			return nil, err
+		}
+		// one more row is fetched to know if a next page exists without counting
+		lookahead := dsl.Limit{Limit: qo.Limit.Limit + 1}
+		preds = append(preds, p, lookahead.Predicate())
+		cursor = &common.CursorInfos{
+			Keys:      keys,
+			Limit:     qo.Limit.Limit,
+			Backward:  qo.IsBackward(),
+			HasCursor: qo.HasPosition(),
+		}
+	} else {
+		if ps, err := qo.Sorts.Predicate(ctx, node, cfg.Dialect); err != nil {
+			return nil, err
+		} else if len(ps) > 0 {
+			preds = append(preds, ps...)
+		}
 
-	preds = append(preds, qo.Pageable.Predicate(true))
+		preds = append(preds, qo.Pageable.Predicate(true))
+	}
 
 	selectApply, err := qo.Select.PredicateQ(node)
 	if err != nil {
@@ -333,7 +419,7 @@ func (qo *QueryOptions) Build(
 		return nil, err
 	}
 
-	execute := func(ctx context.Context, client entx.Client) (any, int, error) {
+	newQuery := func(client entx.Client) entx.Query {
 		q := node.NewQuery(client).Predicate(preds...)
 
 		for _, apply := range incApplies {
@@ -341,16 +427,29 @@ func (qo *QueryOptions) Build(
 		}
 
 		selectApply(q)
+		return q
+	}
+
+	execute := func(ctx context.Context, client entx.Client) (any, int, error) {
+		ctx, span := cfg.Telemetry.StartQuery(ctx, node)
+		q := newQuery(client)
+		if cfg.Telemetry != nil {
+			q.Predicate(cfg.Telemetry.RecordStatement(span))
+		}
 
 		entities, err := q.All(ctx)
 		if err != nil {
-			return nil, 0, &ExecError{
+			err = &ExecError{
 				Op:  "QueryOptions.execute",
 				Err: err,
 			}
+			common.EndSpan(span, err)
+			return nil, 0, err
 		}
+		span.SetAttributes(common.AttrRows.Int(len(entities)))
+		span.End()
 		if len(aggFields) > 0 {
-			if err := entx.AddAggregatesFromValues(aggFields...)(entities); err != nil {
+			if err := entx.AddAggregatesFromValuesFunc(qo.Aggregates.NormalizeValue, aggFields...)(entities); err != nil {
 				panic(err)
 			}
 		}
@@ -359,6 +458,8 @@ func (qo *QueryOptions) Build(
 
 	res := QueryOptionsBuild{
 		ExecFn:                    execute,
+		QueryFn:                   newQuery,
+		Cursor:                    cursor,
 		EnableTransaction:         cfg.Transaction.EnablePaginateQuery,
 		TransactionIsolationLevel: cfg.Transaction.IsolationLevel,
 	}
@@ -379,6 +480,17 @@ func (qo *QueryOptions) Build(
 		}
 	}
 
+	if cfg.Cache.Store != nil {
+		nodes, err := common.DependentNodes(node, qo)
+		if err != nil {
+			return nil, err
+		}
+		res.CacheEntry = cfg.Cache.NewEntry(ctx, "search", qo, nodes)
+		if res.Paginate != nil {
+			res.Paginate.Nodes = nodes
+		}
+	}
+
 	return &res, err
 }
 
@@ -387,8 +499,9 @@ func (qo *QueryOptions) scalarCountSelector(node entx.Node, dialect string, pred
 		return nil, nil
 	}
 
-	sel := sql.Dialect(dialect).Select(sql.Count("*")).
-		From(sql.Table(node.Table()).As("t0"))
+	builder := sql.Dialect(dialect)
+	sel := builder.Select(sql.Count("*")).
+		From(builder.Table(node.Table()).As("t0"))
 
 	for _, p := range preds {
 		p(sel)
@@ -410,6 +523,18 @@ func (qo *QueryOptions) ValidateAndPreprocess(c *Config) (err error) {
 	if err = qo.Sorts.ValidateAndPreprocess(&c.SortConfig); err != nil {
 		return
 	}
+	if err = qo.Sorts.BindAggregates(qo.Aggregates); err != nil {
+		return
+	}
+	if qo.IsCursor() && (qo.WithPagination || qo.Page > 1) {
+		return &ValidationError{
+			Rule: "CursorPaginationConflict",
+			Err:  errors.New("cursor cannot be combined with page based pagination"),
+		}
+	}
+	if err = qo.Cursor.ValidateAndPreprocess(qo.Sorts, true); err != nil {
+		return
+	}
 	qo.Pageable.Sanitize(&c.PageableConfig)
 	return
 }
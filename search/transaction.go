@@ -29,7 +29,7 @@ func WithTx[T any](
 			panic(rollback(tx, err))
 		}
 	}()
-	res, err := fn(ctx, clientTx)
+	res, err := fn(common.ContextWithTx(ctx), clientTx)
 	if err != nil {
 		return zero, rollback(tx, err)
 	}
@@ -90,6 +90,7 @@ func (build *TxQueryGroupBuild) Execute(
 ) (*GroupResponse, error) {
 	scalars, paginations := build.prepareScalars()
 
+	ctx, span := cfg.Telemetry.Start(ctx, common.SpanTransaction, common.AttrIsolationLevel.String(build.IsolationLevel.String()))
 	res, err := WithTx(ctx,
 		client,
 		&sql.TxOptions{
@@ -103,12 +104,14 @@ func (build *TxQueryGroupBuild) Execute(
 			}
 
 			for _, s := range build.Searches {
-				data, count, err := s.ExecFn(ctx, tx)
+				data, count, err := s.ExecFn(common.ContextWithSearchKey(ctx, s.Key), tx)
 				if err != nil {
 					return nil, err
 				}
 
-				res.Searches[s.Key] = &SearchResponse{Data: data, Meta: &MetaSearchResponse{Count: count}}
+				if res.Searches[s.Key], err = s.newSearchResponse(data, count); err != nil {
+					return nil, err
+				}
 			}
 
 			if countScalars := len(scalars); countScalars > 0 {
@@ -125,14 +128,27 @@ func (build *TxQueryGroupBuild) Execute(
 				res.Meta = &MetaResponse{Aggregates: scalarsRes}
 			}
 
+			if len(build.Buckets) > 0 {
+				if res.Meta == nil {
+					res.Meta = &MetaResponse{Aggregates: make(map[string]any, len(build.Buckets))}
+				}
+				if err := common.ExecuteBucketQueries(ctx, tx, res.Meta.Aggregates, build.Buckets...); err != nil {
+					return nil, err
+				}
+			}
+
 			return &res, nil
 		})
 	if err != nil {
-		return nil, &ExecError{
+		err = &ExecError{
 			Op:  "TxQueryGroup.execute",
 			Err: err,
 		}
 	}
+	common.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(paginations) > 0 && res.Meta != nil {
 		if err := common.AttachPaginationAndClean(res.Searches, res.Meta.Aggregates, paginations); err != nil {
@@ -182,16 +198,31 @@ func (group *TxQueryGroup) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	if err := group.ValidateAndPreprocessFinal(cfg); err != nil {
+	cost, err := validateAndCost(ctx, cfg,
+		func() error { return group.ValidateAndPreprocessFinal(cfg) },
+		func() error { return group.CheckTypes(graph) },
+		func(w *CostWeights) int { return group.Cost(graph, w) },
+		group.lowerIncludeLimits,
+	)
+	if err != nil {
 		return nil, err
 	}
 
-	build, err := group.Build(ctx, cfg, graph)
+	build, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (*TxQueryGroupBuild, error) {
+		return group.Build(ctx, cfg, graph)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return build.Execute(ctx, client, cfg)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*GroupResponse, error) {
+		return build.Execute(ctx, client, cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withGroupCost(res, cost), nil
 }
 
 func (r *TxQueryGroup) Build(ctx context.Context, cfg *Config, graph entx.Graph) (*TxQueryGroupBuild, error) {
@@ -227,7 +258,7 @@ func (tr *TxQueryGroup) ValidateAndPreprocessFinal(cfg *Config) (err error) {
 }
 
 func (tr *TxQueryGroup) ValidateAndPreprocess(c *Config) (countAggregates, countSearches int, err error) {
-	if len(tr.Searches)+len(tr.Aggregates) <= 1 {
+	if len(tr.Searches)+len(tr.Aggregates)+len(tr.Grouped)+len(tr.Histograms) <= 1 {
 		return 0, 0, &ValidationError{
 			Rule: "TransactionUnnecessary",
 			Err:  errors.New("transaction with a single search or one aggregate is unnecessary"),
@@ -253,16 +284,43 @@ func (groups TxQueryGroups) Execute(
 	ctx, cancel := common.ContextTimeout(common.ContextWithPolicyToken(ctx), cfg.RequestTimeout)
 	defer cancel()
 
-	countSearches, countAggregates, err := groups.ValidateAndPreprocessFinal(cfg)
+	var countSearches, countAggregates int
+	cost, err := validateAndCost(ctx, cfg,
+		func() (err error) {
+			countSearches, countAggregates, err = groups.ValidateAndPreprocessFinal(cfg)
+			return
+		},
+		func() error { return groups.CheckTypes(graph) },
+		func(w *CostWeights) int { return groups.Cost(graph, w) },
+		groups.lowerIncludeLimits,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	builds, err := common.TraceBuild(ctx, cfg, func(ctx context.Context) (TxQueryGroupBuilds, error) {
+		return groups.Build(ctx, cfg, graph)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	builds, err := groups.Build(ctx, cfg, graph)
+	res, err := common.TraceExec(ctx, cfg, func(ctx context.Context) (*GroupResponse, error) {
+		return builds.execute(ctx, client, cfg, countSearches, countAggregates)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	return withGroupCost(res, cost), nil
+}
+
+func (builds TxQueryGroupBuilds) execute(
+	ctx context.Context,
+	client entx.Client,
+	cfg *Config,
+	countSearches, countAggregates int,
+) (*GroupResponse, error) {
 	var res GroupResponseSync
 
 	if countSearches > 0 {
@@ -285,7 +343,7 @@ func (groups TxQueryGroups) Execute(
 }
 
 func (groups TxQueryGroups) Build(ctx context.Context, cfg *Config, graph entx.Graph) (TxQueryGroupBuilds, error) {
-	var builds = make(TxQueryGroupBuilds, 0, len(groups))
+	var builds = make(TxQueryGroupBuilds, len(groups))
 	for i, group := range groups {
 		build, err := group.Build(ctx, cfg, graph)
 		if err != nil {
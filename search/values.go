@@ -0,0 +1,64 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/brice-74/entx/search/dsl"
+)
+
+var ErrQueryParameter = "invalid %s parameter %q"
+
+// ParseQueryOptions reads query options from URL parameters. The filter, sort, include
+// and fields parameters are written in the compact syntax of the dsl package
+// (e.g. filter=age>18 and is_active=true&sort=-created_at), along with the limit,
+// page, paginate, cursor, after and before parameters.
+func ParseQueryOptions(values url.Values) (*QueryOptions, error) {
+	var (
+		qo  QueryOptions
+		err error
+	)
+	if qo.Filters, err = dsl.ParseFilters(values.Get("filter")); err != nil {
+		return nil, err
+	}
+	if qo.Sorts, err = dsl.ParseSorts(values.Get("sort")); err != nil {
+		return nil, err
+	}
+	if qo.Includes, err = dsl.ParseIncludes(values.Get("include")); err != nil {
+		return nil, err
+	}
+	if qo.Select, err = dsl.ParseSelect(values.Get("fields")); err != nil {
+		return nil, err
+	}
+
+	for _, p := range []struct {
+		name string
+		dest *int
+	}{{"limit", &qo.Limit.Limit}, {"page", &qo.Page}} {
+		if v := values.Get(p.name); v != "" {
+			if *p.dest, err = strconv.Atoi(v); err != nil {
+				return nil, queryParameterError(p.name, v)
+			}
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dest *bool
+	}{{"paginate", &qo.WithPagination}, {"cursor", &qo.WithCursor}} {
+		if v := values.Get(p.name); v != "" {
+			if *p.dest, err = strconv.ParseBool(v); err != nil {
+				return nil, queryParameterError(p.name, v)
+			}
+		}
+	}
+	qo.After, qo.Before = values.Get("after"), values.Get("before")
+	return &qo, nil
+}
+
+func queryParameterError(name, value string) error {
+	return &ValidationError{
+		Rule: "QueryParameter",
+		Err:  fmt.Errorf(ErrQueryParameter, name, value),
+	}
+}
@@ -2,7 +2,9 @@ package entx
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"time"
 
@@ -10,6 +12,13 @@ import (
 	"entgo.io/ent/dialect/sql"
 )
 
+var (
+	ErrConvertValue       = "cannot convert %v (%T) to %s"
+	ErrAttachUnique       = "relation %q accepts a single id, got %d"
+	ErrRelationNotMutable = "relation %q cannot be attached or detached"
+	ErrIDNotSettable      = "the id of node %s cannot be set"
+)
+
 func CombinePredicates[T ~func(*sql.Selector)](preds ...T) T {
 	return func(s *sql.Selector) {
 		for _, f := range preds {
@@ -19,6 +28,12 @@ func CombinePredicates[T ~func(*sql.Selector)](preds ...T) T {
 }
 
 func AddAggregatesFromValues(fields ...string) EntityHandler {
+	return AddAggregatesFromValuesFunc(func(_ string, v any) any { return NormalizeAggregateValue(v) }, fields...)
+}
+
+// AddAggregatesFromValuesFunc is like AddAggregatesFromValues but normalizes
+// the raw value of each field with the given function.
+func AddAggregatesFromValuesFunc(normalize func(field string, v any) any, fields ...string) EntityHandler {
 	return func(entities []Entity) error {
 		for _, e := range entities {
 			for _, f := range fields {
@@ -30,7 +45,7 @@ func AddAggregatesFromValues(fields ...string) EntityHandler {
 				if m.Aggregates == nil {
 					m.Aggregates = make(map[string]any, len(fields))
 				}
-				m.Aggregates[f] = NormalizeAggregateValue(v)
+				m.Aggregates[f] = normalize(f, v)
 			}
 		}
 		return nil
@@ -107,3 +122,43 @@ func AsTypedEntities[T Entity](in []Entity) []T {
 	}
 	return out
 }
+
+// ConvertValue converts a value decoded from JSON to the Go type of the field,
+// as expected by the ent mutations. A nil value is returned as is.
+func ConvertValue(f *Field, v any) (any, error) {
+	if v == nil || f.GoType == nil {
+		return v, nil
+	}
+	return convertValue(v, f.GoType)
+}
+
+// ConvertValues converts values decoded from JSON, such as the ids of a relation, to T.
+func ConvertValues[T any](values []any) ([]T, error) {
+	t := reflect.TypeFor[T]()
+	out := make([]T, len(values))
+	for i, v := range values {
+		c, err := convertValue(v, t)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = c.(T)
+	}
+	return out, nil
+}
+
+// convertValue re-decodes v into t, so that JSON numbers, strings and objects
+// are converted the way encoding/json would decode them into the field.
+func convertValue(v any, t reflect.Type) (any, error) {
+	if v != nil && reflect.TypeOf(v) == t {
+		return v, nil
+	}
+	raw, err := json.Marshal(v)
+	if v == nil || err != nil {
+		return nil, fmt.Errorf(ErrConvertValue, v, v, t)
+	}
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf(ErrConvertValue, v, v, t)
+	}
+	return ptr.Elem().Interface(), nil
+}